@@ -1,125 +1,279 @@
-package config
-
-import (
-	"log"
-	"os"
-	"strconv"
-	"strings"
-
-	"github.com/joho/godotenv"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
-)
-
-type Config struct {
-	// MySQL Config
-	MySQLUser     string
-	MySQLPassword string
-	MySQLHost     string
-	MySQLPort     string
-	MySQLDB       string
-
-	// Redis Config
-	RedisHost     string
-	RedisPort     string
-	RedisPassword string
-
-	// MinIO Config
-	MinIOEndpoint   string
-	MinIOAccessKey  string
-	MinIOSecretKey  string
-	MinIOUseSSL     bool
-	MinIOBucketName string
-
-	// JWT Secret
-	JWTSecret string
-
-	ALLOW_ORIGINS []string
-
-	FRONTEND_URL string
-}
-
-type Oauth2Config struct {
-	OauthClientID     string
-	OauthClientSecret string
-	RedirectURL       string
-	Scopes            []string
-	Endpoint          oauth2.Endpoint
-}
-
-type SMTPConfig struct {
-	Host        string
-	Port        int
-	Username    string
-	Password    string
-	RedirectUrl string
-}
-
-var AppConfig Config
-var GoogleOauthConfig *oauth2.Config
-var SMTP SMTPConfig
-
-func LoadConfig() {
-	// Load .env file
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found, loading environment variables")
-	}
-
-	// Parse values from environment
-	AppConfig = Config{
-		MySQLUser:     getEnv("MYSQL_USER", "root"),
-		MySQLPassword: getEnv("MYSQL_PASSWORD", ""),
-		MySQLHost:     getEnv("MYSQL_HOST", "127.0.0.1"),
-		MySQLPort:     getEnv("MYSQL_PORT", "3306"),
-		MySQLDB:       getEnv("MYSQL_DB", "testdb"),
-
-		RedisHost:     getEnv("REDIS_HOST", "127.0.0.1"),
-		RedisPort:     getEnv("REDIS_PORT", "6379"),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),
-
-		MinIOEndpoint:   getEnv("MINIO_ENDPOINT", "localhost:9000"),
-		MinIOAccessKey:  getEnv("MINIO_ACCESS_KEY", ""),
-		MinIOSecretKey:  getEnv("MINIO_SECRET_KEY", ""),
-		MinIOUseSSL:     getEnvAsBool("MINIO_USE_SSL", false),
-		MinIOBucketName: getEnv("MINIO_BUCKET_NAME", "crazygame"),
-
-		JWTSecret:     getEnv("JWT_SECRET", ""),
-		ALLOW_ORIGINS: strings.Split(getEnv("ALLOW_ORIGINS", "http://localhost:3000"), ","),
-		FRONTEND_URL:  getEnv("FRONTEND_URL", "http://localhost:3000/home"),
-	}
-
-	GoogleOauthConfig = &oauth2.Config{
-		ClientID:     getEnv("OAUTH2_ClientID", ""),
-		ClientSecret: getEnv("OAUTH2_ClientSecret", ""),
-		RedirectURL:  getEnv("OAUTH2_RedirectURL", "http://localhost:8080/api/auth/google/callback"),
-		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",
-			"https://www.googleapis.com/auth/userinfo.profile",
-		},
-		Endpoint: google.Endpoint,
-	}
-
-	SMTP = SMTPConfig{
-		Host:        getEnv("SMTP_HOST", ""),
-		Port:        465,
-		Username:    getEnv("SMTP_USER", ""),
-		Password:    getEnv("SMTP_PASS", ""),
-		RedirectUrl: getEnv("REDIRECT_URL", ""),
-	}
-}
-
-func getEnv(key, fallback string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
-	return fallback
-}
-
-func getEnvAsBool(key string, fallback bool) bool {
-	valueStr := getEnv(key, "")
-	if value, err := strconv.ParseBool(valueStr); err == nil {
-		return value
-	}
-	return fallback
-}
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+	"golang.org/x/oauth2/google"
+)
+
+type Config struct {
+	// MySQL Config
+	MySQLUser     string
+	MySQLPassword string
+	MySQLHost     string
+	MySQLPort     string
+	MySQLDB       string
+
+	// Redis Config
+	RedisHost     string
+	RedisPort     string
+	RedisPassword string
+
+	// MinIO Config
+	MinIOEndpoint   string
+	MinIOAccessKey  string
+	MinIOSecretKey  string
+	MinIOUseSSL     bool
+	MinIOBucketName string
+
+	// JWT Secret
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	// How recently users without a password must have logged in to the
+	// session to change their email or password or delete their account
+	RecentLoginWindow time.Duration
+
+	ALLOW_ORIGINS []string
+
+	FRONTEND_URL string
+
+	CookieSecure bool
+
+	// Email verification
+	RequireEmailVerification  bool
+	EmailVerificationTTL      time.Duration
+	EmailVerificationCooldown time.Duration
+
+	// Password reset
+	PasswordResetTTL           time.Duration
+	PasswordResetPurgeInterval time.Duration
+
+	// How often users whose suspension ended are reinstated
+	SuspensionCheckInterval time.Duration
+
+	// Brute-force protection for login and forgot password
+	RateLimitWindow               time.Duration
+	LoginLimitPerIP               int
+	LoginLimitPerAccount          int
+	ForgotPasswordLimitPerIP      int
+	ForgotPasswordLimitPerAccount int
+	LockoutThreshold              int
+	LockoutCooldown               time.Duration
+	LockoutMaxCooldown            time.Duration
+
+	// Two-factor authentication
+	MFAIssuer            string
+	MFARequiredForAdmins bool
+
+	// Personal data exports and erasures
+	DataRequestPollInterval time.Duration
+	DataExportTTL           time.Duration
+	DataExportLinkTTL       time.Duration
+	DataExportPurgeInterval time.Duration
+
+	// Game plays, counted per IP within RateLimitWindow
+	PlayLimitPerIP         int
+	PlayCountFlushInterval time.Duration
+	// Play session heartbeats and ends, counted per IP within RateLimitWindow
+	PlaySessionLimitPerIP int
+	// A play session without a heartbeat for PlaySessionTimeout has ended
+	PlaySessionTimeout         time.Duration
+	PlaySessionCleanupInterval time.Duration
+	// A play counts half as much towards the trending games every
+	// TrendingHalfLife. Rebuild the rankings after changing it.
+	TrendingHalfLife time.Duration
+
+	// Game votes, counted per IP within RateLimitWindow
+	VoteLimitPerIP int
+}
+
+type Oauth2Config struct {
+	OauthClientID     string
+	OauthClientSecret string
+	RedirectURL       string
+	Scopes            []string
+	Endpoint          oauth2.Endpoint
+	UserInfoURL       string
+	// IssuerURL makes the provider a generic OpenID Connect one whose Endpoint
+	// and UserInfoURL are discovered from the issuer.
+	IssuerURL string
+}
+
+type SMTPConfig struct {
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	RedirectUrl string
+}
+
+var AppConfig Config
+var OauthProviders map[string]Oauth2Config
+var SMTP SMTPConfig
+
+func LoadConfig() {
+	// Load .env file
+	err := godotenv.Load()
+	if err != nil {
+		log.Println("No .env file found, loading environment variables")
+	}
+
+	// Parse values from environment
+	AppConfig = Config{
+		MySQLUser:     getEnv("MYSQL_USER", "root"),
+		MySQLPassword: getEnv("MYSQL_PASSWORD", ""),
+		MySQLHost:     getEnv("MYSQL_HOST", "127.0.0.1"),
+		MySQLPort:     getEnv("MYSQL_PORT", "3306"),
+		MySQLDB:       getEnv("MYSQL_DB", "testdb"),
+
+		RedisHost:     getEnv("REDIS_HOST", "127.0.0.1"),
+		RedisPort:     getEnv("REDIS_PORT", "6379"),
+		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+
+		MinIOEndpoint:   getEnv("MINIO_ENDPOINT", "localhost:9000"),
+		MinIOAccessKey:  getEnv("MINIO_ACCESS_KEY", ""),
+		MinIOSecretKey:  getEnv("MINIO_SECRET_KEY", ""),
+		MinIOUseSSL:     getEnvAsBool("MINIO_USE_SSL", false),
+		MinIOBucketName: getEnv("MINIO_BUCKET_NAME", "crazygame"),
+
+		JWTSecret:       getEnv("JWT_SECRET", ""),
+		AccessTokenTTL:  getEnvAsDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvAsDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		RecentLoginWindow: getEnvAsDuration("RECENT_LOGIN_WINDOW", 5*time.Minute),
+
+		ALLOW_ORIGINS: strings.Split(getEnv("ALLOW_ORIGINS", "http://localhost:3000"), ","),
+		FRONTEND_URL:  getEnv("FRONTEND_URL", "http://localhost:3000/home"),
+
+		CookieSecure: getEnvAsBool("COOKIE_SECURE", false),
+
+		RequireEmailVerification:  getEnvAsBool("REQUIRE_EMAIL_VERIFICATION", false),
+		EmailVerificationTTL:      getEnvAsDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		EmailVerificationCooldown: getEnvAsDuration("EMAIL_VERIFICATION_COOLDOWN", time.Minute),
+
+		PasswordResetTTL:           getEnvAsDuration("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetPurgeInterval: getEnvAsDuration("PASSWORD_RESET_PURGE_INTERVAL", time.Hour),
+
+		SuspensionCheckInterval: getEnvAsDuration("SUSPENSION_CHECK_INTERVAL", time.Minute),
+
+		RateLimitWindow:               getEnvAsDuration("RATE_LIMIT_WINDOW", 15*time.Minute),
+		LoginLimitPerIP:               getEnvAsInt("LOGIN_LIMIT_PER_IP", 50),
+		LoginLimitPerAccount:          getEnvAsInt("LOGIN_LIMIT_PER_ACCOUNT", 20),
+		ForgotPasswordLimitPerIP:      getEnvAsInt("FORGOT_PASSWORD_LIMIT_PER_IP", 10),
+		ForgotPasswordLimitPerAccount: getEnvAsInt("FORGOT_PASSWORD_LIMIT_PER_ACCOUNT", 3),
+		LockoutThreshold:              getEnvAsInt("LOCKOUT_THRESHOLD", 5),
+		LockoutCooldown:               getEnvAsDuration("LOCKOUT_COOLDOWN", time.Minute),
+		LockoutMaxCooldown:            getEnvAsDuration("LOCKOUT_MAX_COOLDOWN", time.Hour),
+
+		MFAIssuer:            getEnv("MFA_ISSUER", "CrazyGames"),
+		MFARequiredForAdmins: getEnvAsBool("MFA_REQUIRED_FOR_ADMINS", false),
+
+		DataRequestPollInterval: getEnvAsDuration("DATA_REQUEST_POLL_INTERVAL", 30*time.Second),
+		DataExportTTL:           getEnvAsDuration("DATA_EXPORT_TTL", 7*24*time.Hour),
+		DataExportLinkTTL:       getEnvAsDuration("DATA_EXPORT_LINK_TTL", 15*time.Minute),
+		DataExportPurgeInterval: getEnvAsDuration("DATA_EXPORT_PURGE_INTERVAL", time.Hour),
+
+		PlayLimitPerIP:             getEnvAsInt("PLAY_LIMIT_PER_IP", 300),
+		PlayCountFlushInterval:     getEnvAsDuration("PLAY_COUNT_FLUSH_INTERVAL", 30*time.Second),
+		PlaySessionLimitPerIP:      getEnvAsInt("PLAY_SESSION_LIMIT_PER_IP", 3000),
+		PlaySessionTimeout:         getEnvAsDuration("PLAY_SESSION_TIMEOUT", 90*time.Second),
+		PlaySessionCleanupInterval: getEnvAsDuration("PLAY_SESSION_CLEANUP_INTERVAL", time.Minute),
+		TrendingHalfLife:           getEnvAsDuration("TRENDING_HALF_LIFE", 6*time.Hour),
+
+		VoteLimitPerIP: getEnvAsInt("VOTE_LIMIT_PER_IP", 100),
+	}
+
+	// Providers without a client ID are not offered for login
+	OauthProviders = map[string]Oauth2Config{}
+	addOauthProvider("google", Oauth2Config{
+		OauthClientID:     getEnv("OAUTH2_ClientID", ""),
+		OauthClientSecret: getEnv("OAUTH2_ClientSecret", ""),
+		RedirectURL:       getEnv("OAUTH2_RedirectURL", "http://localhost:8080/api/Oauth/google/callback"),
+		Scopes: []string{
+			"https://www.googleapis.com/auth/userinfo.email",
+			"https://www.googleapis.com/auth/userinfo.profile",
+		},
+		Endpoint:    google.Endpoint,
+		UserInfoURL: getEnv("OAUTH2_UserInfoURL", "https://www.googleapis.com/oauth2/v2/userinfo"),
+	})
+	addOauthProvider("github", Oauth2Config{
+		OauthClientID:     getEnv("OAUTH_GITHUB_CLIENT_ID", ""),
+		OauthClientSecret: getEnv("OAUTH_GITHUB_CLIENT_SECRET", ""),
+		RedirectURL:       getEnv("OAUTH_GITHUB_REDIRECT_URL", "http://localhost:8080/api/Oauth/github/callback"),
+		Scopes:            []string{"read:user", "user:email"},
+		Endpoint:          github.Endpoint,
+		UserInfoURL:       "https://api.github.com/user",
+	})
+	addOauthProvider("discord", Oauth2Config{
+		OauthClientID:     getEnv("OAUTH_DISCORD_CLIENT_ID", ""),
+		OauthClientSecret: getEnv("OAUTH_DISCORD_CLIENT_SECRET", ""),
+		RedirectURL:       getEnv("OAUTH_DISCORD_REDIRECT_URL", "http://localhost:8080/api/Oauth/discord/callback"),
+		Scopes:            []string{"identify", "email"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://discord.com/oauth2/authorize",
+			TokenURL: "https://discord.com/api/oauth2/token",
+		},
+		UserInfoURL: "https://discord.com/api/users/@me",
+	})
+	addOauthProvider(getEnv("OAUTH_OIDC_NAME", "oidc"), Oauth2Config{
+		OauthClientID:     getEnv("OAUTH_OIDC_CLIENT_ID", ""),
+		OauthClientSecret: getEnv("OAUTH_OIDC_CLIENT_SECRET", ""),
+		RedirectURL:       getEnv("OAUTH_OIDC_REDIRECT_URL", "http://localhost:8080/api/Oauth/oidc/callback"),
+		Scopes:            []string{"openid", "email", "profile"},
+		IssuerURL:         getEnv("OAUTH_OIDC_ISSUER_URL", ""),
+	})
+
+	SMTP = SMTPConfig{
+		Host:        getEnv("SMTP_HOST", ""),
+		Port:        465,
+		Username:    getEnv("SMTP_USER", ""),
+		Password:    getEnv("SMTP_PASS", ""),
+		RedirectUrl: getEnv("REDIRECT_URL", ""),
+	}
+}
+
+func addOauthProvider(name string, conf Oauth2Config) {
+	if conf.OauthClientID != "" {
+		OauthProviders[name] = conf
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return fallback
+}
+
+func getEnvAsBool(key string, fallback bool) bool {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func getEnvAsInt(key string, fallback int) int {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.Atoi(valueStr); err == nil {
+		return value
+	}
+	return fallback
+}
+
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	valueStr := getEnv(key, "")
+	if value, err := time.ParseDuration(valueStr); err == nil {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,23 @@
+package entities
+
+import (
+	"time"
+)
+
+// APIKey lets an integration act as its user without the user's password.
+// Only the SHA-256 of the key is stored; Prefix is kept to tell keys apart.
+// A key can only use the permissions listed in Scopes that its user's role
+// still grants.
+type APIKey struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	UserID     uint       `json:"user_id" gorm:"not null;index"`
+	User       *User      `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Name       string     `json:"name" gorm:"size:100;not null"`
+	Prefix     string     `json:"prefix" gorm:"size:16;not null"`
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Scopes     []string   `json:"scopes" gorm:"type:text;serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
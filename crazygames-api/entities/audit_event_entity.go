@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+const (
+	AuditEntityGame     = "game"
+	AuditEntityCategory = "category"
+	AuditEntityAds      = "ads"
+	AuditEntityUser     = "user"
+	AuditEntityTag      = "tag"
+)
+
+// AuditEvent records an administrative change. Before and After only hold the
+// fields that changed; Before is empty on create and After on delete. Events
+// are only ever inserted, and ActorID has no foreign key so that they outlive
+// the actor.
+type AuditEvent struct {
+	ID         uint            `json:"id" gorm:"primaryKey"`
+	ActorID    uint            `json:"actor_id" gorm:"not null;index"`
+	Action     string          `json:"action" gorm:"size:20;not null;index"`
+	EntityType string          `json:"entity_type" gorm:"size:50;not null;index:idx_audit_events_entity"`
+	EntityID   uint            `json:"entity_id" gorm:"not null;index:idx_audit_events_entity"`
+	Before     json.RawMessage `json:"before" gorm:"type:json" swaggertype:"object"`
+	After      json.RawMessage `json:"after" gorm:"type:json" swaggertype:"object"`
+	IP         string          `json:"ip" gorm:"size:45"`
+	RequestID  string          `json:"request_id" gorm:"size:64;index"`
+	CreatedAt  time.Time       `json:"created_at" gorm:"autoCreateTime;index"`
+}
@@ -0,0 +1,37 @@
+package entities
+
+import "time"
+
+const (
+	DataRequestExport  = "export"
+	DataRequestErasure = "erasure"
+)
+
+const (
+	DataRequestPending    = "pending"
+	DataRequestProcessing = "processing"
+	DataRequestCompleted  = "completed"
+	DataRequestFailed     = "failed"
+	// DataRequestExpired exports no longer have their file.
+	DataRequestExpired = "expired"
+)
+
+// DataRequest is a user's request for a copy of their personal data or for its
+// erasure, carried out in the background. UserID has no foreign key so that
+// erasures stay on record once the user is gone.
+type DataRequest struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	UserID uint   `json:"user_id" gorm:"not null;index"`
+	Type   string `json:"type" gorm:"size:20;not null"`
+	Status string `json:"status" gorm:"size:20;not null;index"`
+	// ObjectPath is the ZIP of a completed export in MinIO, until ExpiresAt.
+	ObjectPath  string     `json:"-" gorm:"size:255"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// DownloadURL is a presigned link to the export, set when it is handed out.
+	DownloadURL string `json:"download_url,omitempty" gorm:"-"`
+}
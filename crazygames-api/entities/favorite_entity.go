@@ -1,11 +1,12 @@
-package entities
-
-import "time"
-
-type Favorite struct {
-	ID        uint `gorm:"primaryKey;autoIncrement"`
-	UserID    uint
-	GameID    uint
-	CreatedAt *time.Time `gorm:"autoCreateTime"`
-	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
-}
+package entities
+
+import "time"
+
+type Favorite struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `gorm:"uniqueIndex:idx_favorites_user_game"`
+	GameID    uint       `gorm:"uniqueIndex:idx_favorites_user_game"`
+	Game      *Game      `gorm:"foreignKey:GameID;constraint:-"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
+}
@@ -1,13 +1,13 @@
-package entities
-
-import (
-	"gorm.io/gorm"
-	"time"
-)
-
-type GameCategory struct {
-	CategoryID uint `gorm:"primaryKey"`
-	GameID     uint `gorm:"primaryKey"`
-	CreatedAt  time.Time
-	DeletedAt  gorm.DeletedAt
-}
+package entities
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+type GameCategory struct {
+	CategoryID uint `gorm:"primaryKey"`
+	GameID     uint `gorm:"primaryKey"`
+	CreatedAt  time.Time
+	DeletedAt  gorm.DeletedAt
+}
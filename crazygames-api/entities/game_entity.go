@@ -1,22 +1,48 @@
-package entities
-
-import (
-	"time"
-)
-
-type Game struct {
-	ID            uint   `gorm:"primaryKey;autoIncrement"`
-	GameTitle     string `gorm:"not null"`
-	Description   string
-	Developer     string
-	ReleaseDate   *time.Time
-	ThumbnailURL  string
-	Technology    string
-	Rating        float64
-	HoverVideoUrl string
-	GameURL       string     `gorm:"not null"`
-	PlayCount     int        `gorm:"default:0"`
-	Category      []Category `gorm:"many2many:game_categories;"`
-	CreatedAt     *time.Time `gorm:"autoCreateTime"`
-	UpdatedAt     *time.Time `gorm:"autoUpdateTime"`
-}
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Game struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	GameTitle     string `gorm:"not null"`
+	Description   string
+	Developer     string
+	ReleaseDate   *time.Time
+	ThumbnailURL  string
+	Technology    string
+	Rating        float64 // Bayesian average of the RatingCount reviews, kept up to date with them
+	RatingCount   int     `gorm:"not null;default:0"`
+	HoverVideoUrl string
+	GameURL       string     `gorm:"not null"`
+	PlayCount     int        `gorm:"default:0"`
+	UpVotes       int        `gorm:"not null;default:0"`
+	DownVotes     int        `gorm:"not null;default:0"`
+	Category      []Category `gorm:"many2many:game_categories;"`
+	Tags          []Tag      `gorm:"many2many:game_tags;"`
+	CreatedAt     *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     *time.Time `gorm:"autoUpdateTime"`
+	// PrimaryCategoryID is the one of Category shown in breadcrumbs
+	PrimaryCategoryID *uint `gorm:"index"`
+	// SessionCount and TotalPlaySeconds add up the ended play sessions
+	SessionCount     int64 `gorm:"not null;default:0"`
+	TotalPlaySeconds int64 `gorm:"not null;default:0"`
+	// AverageSessionSeconds and LikeRatio are worked out from the counters
+	AverageSessionSeconds float64 `gorm:"-"`
+	LikeRatio             float64 `gorm:"-"`
+	// IsFavorite is filled in for the logged in user
+	IsFavorite bool `gorm:"-" json:"is_favorite"`
+}
+
+// AfterFind works out the like ratio and average session length from the
+// counters.
+func (g *Game) AfterFind(tx *gorm.DB) error {
+	g.LikeRatio = LikeRatio(g.UpVotes, g.DownVotes)
+	if g.SessionCount > 0 {
+		g.AverageSessionSeconds = float64(g.TotalPlaySeconds) / float64(g.SessionCount)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package entities
+
+import "time"
+
+// Values of a GameVote.
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
+
+// GameVote is the thumbs up or down of a logged in user on a game. The votes
+// of anonymous players are kept in Redis by device instead.
+type GameVote struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	GameID    uint       `gorm:"not null;uniqueIndex:idx_game_votes_game_user"`
+	UserID    uint       `gorm:"not null;uniqueIndex:idx_game_votes_game_user"`
+	Value     int        `gorm:"not null;check:value IN (-1, 1)"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
+}
+
+// LikeRatio is the share of the votes that are up, 0 without votes.
+func LikeRatio(upVotes int, downVotes int) float64 {
+	if upVotes+downVotes == 0 {
+		return 0
+	}
+	return float64(upVotes) / float64(upVotes+downVotes)
+}
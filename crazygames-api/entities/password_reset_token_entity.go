@@ -1,15 +1,17 @@
-package entities
-
-import (
-	"time"
-)
-
-type PasswordResetToken struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement"`
-	Email     string    `json:"email" gorm:"unique;not null"`
-	Token     string    `gorm:"size:255;not null"`
-	ExpiredAt time.Time `gorm:"not null"`
-	IsUsed    bool      `gorm:"default:false"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
-}
+package entities
+
+import (
+	"time"
+)
+
+// PasswordResetToken is the outstanding reset of an email. Only the SHA-256
+// of the token is stored; the token itself is only in the email.
+type PasswordResetToken struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Email     string    `json:"email" gorm:"unique;not null"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	ExpiredAt time.Time `gorm:"index;not null"`
+	IsUsed    bool      `gorm:"default:false"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
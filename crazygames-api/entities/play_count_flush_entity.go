@@ -0,0 +1,10 @@
+package entities
+
+import "time"
+
+// PlayCountFlush is a batch of play counts added to the games, kept so that a
+// batch retried after the flush failed to clear it in Redis is added once.
+type PlayCountFlush struct {
+	ID        string     `gorm:"primaryKey;size:64"`
+	CreatedAt *time.Time `gorm:"autoCreateTime;index"`
+}
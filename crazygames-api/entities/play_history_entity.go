@@ -1,12 +1,21 @@
-package entities
-
-import "time"
-
-type PlayHistory struct {
-	ID         uint `gorm:"primaryKey;autoIncrement"`
-	UserID     uint
-	GameID     uint
-	DatePlayed *time.Time `gorm:"autoCreateTime"`
-	CreatedAt  *time.Time `gorm:"autoCreateTime"`
-	UpdatedAt  *time.Time `gorm:"autoUpdateTime"`
-}
+package entities
+
+import "time"
+
+// PlayHistory is one play session of a game, started by POST
+// /api/game/:id/play. The game page keeps it going with heartbeats until it
+// ends. DurationSeconds runs from DatePlayed to the last heartbeat or the end.
+type PlayHistory struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement"`
+	UserID     *uint      `gorm:"index:idx_play_histories_user_played"` // nil for anonymous players and once the player deleted their account
+	GameID     uint       `gorm:"index"`
+	DatePlayed *time.Time `gorm:"autoCreateTime;index:idx_play_histories_user_played"`
+	// SessionID is handed to the game page for the heartbeats, empty for plays
+	// recorded before sessions were tracked.
+	SessionID       string `gorm:"size:64;index" json:"-"`
+	LastHeartbeatAt *time.Time
+	EndedAt         *time.Time `gorm:"index"`
+	DurationSeconds int64      `gorm:"not null;default:0"`
+	CreatedAt       *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt       *time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,16 @@
+package entities
+
+import (
+	"time"
+)
+
+// RecoveryCode is a one-time code that replaces the TOTP code when the user
+// lost their authenticator. Only the SHA-256 of the code is stored.
+type RecoveryCode struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;index"`
+	User      *User  `gorm:"constraint:OnDelete:CASCADE"`
+	CodeHash  string `gorm:"size:64;not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -1,14 +1,17 @@
-package entities
-
-import "time"
-
-type Review struct {
-	ID         uint `gorm:"primaryKey;autoIncrement"`
-	GameID     uint
-	UserID     uint
-	Rating     int `gorm:"not null;check:rating >= 1 AND rating <= 5"`
-	Comment    string
-	DatePosted *time.Time `gorm:"autoCreateTime"`
-	CreatedAt  *time.Time `gorm:"autoCreateTime"`
-	UpdatedAt  *time.Time `gorm:"autoUpdateTime"`
-}
+package entities
+
+import "time"
+
+// Review is the review of a game by a user, one per user and game.
+// HelpfulCount is the number of ReviewVotes of other users.
+type Review struct {
+	ID           uint  `gorm:"primaryKey;autoIncrement"`
+	GameID       uint  `gorm:"uniqueIndex:idx_reviews_game_user"`
+	UserID       *uint `gorm:"uniqueIndex:idx_reviews_game_user"` // nil once the author deleted their account
+	Rating       int   `gorm:"not null;check:rating >= 1 AND rating <= 5"`
+	Comment      string
+	HelpfulCount int        `gorm:"not null;default:0"`
+	DatePosted   *time.Time `gorm:"autoCreateTime"`
+	CreatedAt    *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    *time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "time"
+
+// ReviewVote is a user finding a review helpful.
+type ReviewVote struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	ReviewID  uint       `gorm:"not null;uniqueIndex:idx_review_votes_review_user"`
+	UserID    uint       `gorm:"not null;uniqueIndex:idx_review_votes_review_user"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+}
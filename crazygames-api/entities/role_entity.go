@@ -0,0 +1,37 @@
+package entities
+
+import "time"
+
+const (
+	RoleEditor    = "editor"
+	RoleAdManager = "ad_manager"
+)
+
+// Permissions checked by the routes. A permission is named <resource>:<action>.
+const (
+	PermissionGameWrite     = "game:write"
+	PermissionCategoryWrite = "category:write"
+	PermissionAdsWrite      = "ads:write"
+	PermissionUserWrite     = "user:write"
+	PermissionUserRead      = "user:read"
+	PermissionRoleAssign    = "role:assign"
+	PermissionAuditRead     = "audit:read"
+	PermissionTagWrite      = "tag:write"
+)
+
+// Role is what User.Role refers to by name; its permissions decide what the
+// user may do.
+type Role struct {
+	ID          uint         `json:"id" gorm:"primaryKey"`
+	Name        string       `json:"name" gorm:"size:50;unique;not null"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions" gorm:"many2many:role_permissions;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+type Permission struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name" gorm:"size:100;unique;not null"`
+	Description string `json:"description"`
+}
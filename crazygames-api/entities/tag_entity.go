@@ -1,15 +1,17 @@
-package entities
-
-import "time"
-
-type Tag struct {
-	ID        uint       `gorm:"primaryKey;autoIncrement"`
-	TagName   string     `gorm:"unique;not null"`
-	CreatedAt *time.Time `gorm:"autoCreateTime"`
-	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
-}
-
-type GameTag struct {
-	GameID uint
-	TagID  uint
-}
+package entities
+
+import "time"
+
+type Tag struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement"`
+	TagName   string     `gorm:"unique;not null"`
+	Slug      string     `gorm:"size:100;not null;uniqueIndex:idx_tags_slug"`
+	CreatedAt *time.Time `gorm:"autoCreateTime"`
+	UpdatedAt *time.Time `gorm:"autoUpdateTime"`
+}
+
+// GameTag is the join table of Game.Tags.
+type GameTag struct {
+	GameID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID  uint `gorm:"primaryKey;autoIncrement:false;index"`
+}
@@ -1,20 +1,60 @@
-package entities
-
-import (
-	"time"
-)
-
-const (
-	RoleAdmin  = "admin"
-	RolePlayer = "player"
-)
-
-type User struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Username  string    `json:"username" gorm:"unique;not null;check:username <> ''"`
-	Password  string    `json:"-" gorm:"not null;check:password <> ''"`
-	Email     string    `json:"email" gorm:"unique;not null;check:email <> ''"`
-	Role      string    `json:"role" gorm:"not null;default:player"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
-}
+package entities
+
+import (
+	"time"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RolePlayer = "player"
+)
+
+// Statuses of a user. Suspended users can log in again once SuspendedUntil
+// has passed, banned users never.
+const (
+	UserActive    = "active"
+	UserSuspended = "suspended"
+	UserBanned    = "banned"
+)
+
+type User struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Username string `json:"username" gorm:"unique;not null;check:username <> ''"`
+	Password string `json:"-" gorm:"not null"` // empty for users that only log in through an OAuth provider
+	Email    string `json:"email" gorm:"unique;not null;check:email <> ''"`
+	Role     string `json:"role" gorm:"not null;default:player"`
+	// EmailVerified is set once the user proved they own Email.
+	EmailVerified bool `json:"email_verified" gorm:"not null;default:false"`
+	// TOTPSecret is set on enrollment, TOTPEnabled once the user confirmed it
+	// with a code. TOTPLastStep is the time step of the last accepted code.
+	TOTPSecret   string `json:"-"`
+	TOTPEnabled  bool   `json:"totp_enabled" gorm:"not null;default:false"`
+	TOTPLastStep int64  `json:"-" gorm:"not null;default:0"`
+	// Profile the user edits through /api/me. AvatarPath is the object of the
+	// uploaded avatar in MinIO and Country an ISO 3166-1 alpha-2 code.
+	DisplayName string `json:"display_name" gorm:"size:50"`
+	AvatarURL   string `json:"avatar_url"`
+	AvatarPath  string `json:"-"`
+	Bio         string `json:"bio" gorm:"size:500"`
+	Country     string `json:"country" gorm:"size:2"`
+	// Status is set by moderators, with the reason shown to the user and the
+	// moderator who set it last.
+	Status          string     `json:"status" gorm:"size:20;not null;default:active;index"`
+	SuspendedUntil  *time.Time `json:"suspended_until"`
+	StatusReason    string     `json:"status_reason" gorm:"size:500"`
+	StatusChangedBy *uint      `json:"status_changed_by"`
+	StatusChangedAt *time.Time `json:"status_changed_at"`
+	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Restricted reports whether the user is banned or suspended at now.
+func (u *User) Restricted(now time.Time) bool {
+	switch u.Status {
+	case UserBanned:
+		return true
+	case UserSuspended:
+		return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package entities
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to an account at an OAuth provider. Subject is the
+// provider's stable ID for that account; the email it reported can change.
+type UserIdentity struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_user_identities_user_provider"`
+	User      *User     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Provider  string    `json:"provider" gorm:"size:64;not null;uniqueIndex:idx_user_identities_provider_subject;uniqueIndex:idx_user_identities_user_provider"`
+	Subject   string    `json:"-" gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	svc services.AccountServiceInterface
+}
+
+func NewAccountHandler(svc services.AccountServiceInterface) *AccountHandler {
+	return &AccountHandler{svc: svc}
+}
+
+// GetProfile
+// @Description Get the account of the current user
+// @Tags Account
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.User}
+// @Security Bearer
+// @Router /me [get]
+func (h *AccountHandler) GetProfile(c *gin.Context) {
+	user, err := h.svc.GetProfile(c.GetUint(middlewares.UserIDKey))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Profile retrieved successfully", user)
+}
+
+// UpdateProfile
+// @Description Replace the display name, bio and country of the current user
+// @Tags Account
+// @Param ProfileRequest body request.ProfileRequest true "Profile request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.User}
+// @Security Bearer
+// @Router /me [put]
+func (h *AccountHandler) UpdateProfile(c *gin.Context) {
+	var profileRequest request.ProfileRequest
+	if err := c.ShouldBindJSON(&profileRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.UpdateProfile(c.GetUint(middlewares.UserIDKey), &profileRequest)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Profile updated successfully", user)
+}
+
+// UpdateAvatar
+// @Description Upload the avatar of the current user, a PNG, JPEG, GIF or WebP image of at most 2 MB
+// @Tags Account
+// @Param avatar formData file true "Avatar image"
+// @Accept multipart/form-data
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.User}
+// @Security Bearer
+// @Router /me/avatar [put]
+func (h *AccountHandler) UpdateAvatar(c *gin.Context) {
+	var avatarRequest request.AvatarRequest
+	if err := c.ShouldBind(&avatarRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.UpdateAvatar(c.GetUint(middlewares.UserIDKey), avatarRequest.Avatar)
+	if errors.Is(err, services.ErrInvalidAvatar) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Avatar updated successfully", user)
+}
+
+// ChangePassword
+// @Description Change the password of the current user, which logs out their other sessions. Users without a password must have logged in recently instead of giving it.
+// @Tags Account
+// @Param ChangePasswordRequest body request.ChangePasswordRequest true "Change password request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /me/password [put]
+func (h *AccountHandler) ChangePassword(c *gin.Context) {
+	var passwordRequest request.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&passwordRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.svc.ChangePassword(c.GetUint(middlewares.UserIDKey), c.GetString(middlewares.SessionIDKey), &passwordRequest)
+	if respondAccountError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Password changed successfully", nil)
+}
+
+// ChangeEmail
+// @Description Change the email of the current user, who has to verify it again. Users without a password must have logged in recently instead of giving it.
+// @Tags Account
+// @Param ChangeEmailRequest body request.ChangeEmailRequest true "Change email request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.User}
+// @Security Bearer
+// @Router /me/email [put]
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	var emailRequest request.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&emailRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.ChangeEmail(c.GetUint(middlewares.UserIDKey), c.GetString(middlewares.SessionIDKey), &emailRequest)
+	if respondAccountError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Email changed, please check your inbox to verify it", user)
+}
+
+// DeleteAccount
+// @Description Delete the account of the current user. Their reviews and play history are kept anonymously. Users without a password must have logged in recently instead of giving it.
+// @Tags Account
+// @Param DeleteAccountRequest body request.DeleteAccountRequest true "Delete account request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /me [delete]
+func (h *AccountHandler) DeleteAccount(c *gin.Context) {
+	var deleteRequest request.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&deleteRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.svc.DeleteAccount(c.GetUint(middlewares.UserIDKey), c.GetString(middlewares.SessionIDKey), deleteRequest.Password)
+	if respondAccountError(c, err) {
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Account deleted successfully", nil)
+}
+
+// respondAccountError answers err of a change that needs the user's password
+// and reports whether it did.
+func respondAccountError(c *gin.Context, err error) bool {
+	var rateLimitErr *services.RateLimitError
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &rateLimitErr):
+		response.TooManyRequestsResponse(c, rateLimitErr.RetryAfter)
+	case errors.Is(err, services.ErrWrongPassword), errors.Is(err, services.ErrLoginRequired):
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, services.ErrEmailInUse):
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+	return true
+}
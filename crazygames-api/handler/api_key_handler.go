@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	svc services.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(svc services.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{svc: svc}
+}
+
+// GetAll
+// @Description Get the API keys of the current user
+// @Tags API Keys
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.APIKey}
+// @Security Bearer
+// @Router /api-key [get]
+func (h *APIKeyHandler) GetAll(c *gin.Context) {
+	h.getAll(c, c.GetUint(middlewares.UserIDKey))
+}
+
+// Create
+// @Description Create an API key for the current user. The key is only shown in this response.
+// @Tags API Keys
+// @Param APIKeyRequest body request.APIKeyRequest true "API key request"
+// @Accept json
+// @Produce json
+// @Success 201 {object} response.Response{data=response.APIKeyCreatedResponse}
+// @Security Bearer
+// @Router /api-key [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	h.create(c, c.GetUint(middlewares.UserIDKey))
+}
+
+// Revoke
+// @Description Revoke an API key of the current user
+// @Tags API Keys
+// @Param id path uint true "API key ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /api-key/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	h.revoke(c, c.GetUint(middlewares.UserIDKey), c.Param("id"))
+}
+
+// GetAllForUser
+// @Description Get the API keys of a user, e.g. a service account
+// @Tags API Keys
+// @Param id path uint true "User ID"
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.APIKey}
+// @Security Bearer
+// @Router /user/{id}/api-key [get]
+func (h *APIKeyHandler) GetAllForUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	h.getAll(c, uint(userID))
+}
+
+// CreateForUser
+// @Description Create an API key for a user, e.g. a service account. The key is only shown in this response.
+// @Tags API Keys
+// @Param id path uint true "User ID"
+// @Param APIKeyRequest body request.APIKeyRequest true "API key request"
+// @Accept json
+// @Produce json
+// @Success 201 {object} response.Response{data=response.APIKeyCreatedResponse}
+// @Security Bearer
+// @Router /user/{id}/api-key [post]
+func (h *APIKeyHandler) CreateForUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	h.create(c, uint(userID))
+}
+
+// RevokeForUser
+// @Description Revoke an API key of a user
+// @Tags API Keys
+// @Param id path uint true "User ID"
+// @Param key_id path uint true "API key ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /user/{id}/api-key/{key_id} [delete]
+func (h *APIKeyHandler) RevokeForUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	h.revoke(c, uint(userID), c.Param("key_id"))
+}
+
+func (h *APIKeyHandler) getAll(c *gin.Context, userID uint) {
+	keys, err := h.svc.GetByUserID(userID)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "API keys retrieved successfully", keys)
+}
+
+func (h *APIKeyHandler) create(c *gin.Context, userID uint) {
+	var keyRequest request.APIKeyRequest
+	if err := c.ShouldBindJSON(&keyRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, apiKey, err := h.svc.Create(userID, &keyRequest)
+	if errors.Is(err, services.ErrInvalidScope) || errors.Is(err, services.ErrInvalidExpiry) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "API key created, store it now as it will not be shown again", response.APIKeyCreatedResponse{Key: key, APIKey: apiKey})
+}
+
+func (h *APIKeyHandler) revoke(c *gin.Context, userID uint, keyID string) {
+	id, err := strconv.ParseUint(keyID, 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	err = h.svc.Revoke(userID, uint(id))
+	if errors.Is(err, services.ErrAPIKeyNotFound) {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "API key revoked successfully", nil)
+}
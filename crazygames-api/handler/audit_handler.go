@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	svc services.AuditServiceInterface
+}
+
+func NewAuditHandler(svc services.AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{svc: svc}
+}
+
+// GetAll
+// @Description Get the audit log of administrative changes, newest first
+// @Tags Audit
+// @Param query query request.AuditRequestQuery true "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=response.AuditEventsResponse}
+// @Security Bearer
+// @Router /admin/audit [get]
+func (h *AuditHandler) GetAll(c *gin.Context) {
+	var query request.AuditRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, total, err := h.svc.GetAll(query)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Audit events retrieved successfully", response.AuditEventsResponse{
+		Events:     events,
+		Total:      total,
+		PageNumber: query.PageNumber,
+		PageSize:   query.PageSize,
+	})
+}
@@ -1,96 +1,349 @@
-package handler
-
-import (
-	"net/http"
-
-	"crazygames.io/handler/request"
-	"crazygames.io/handler/response"
-	"crazygames.io/services"
-	"github.com/gin-gonic/gin"
-)
-
-type AuthHandler struct {
-	authSvc services.AuthServiceInterface
-	userSvc services.UserServiceInterface
-}
-
-func NewAuthHandler(authSvc services.AuthServiceInterface, userSvc services.UserServiceInterface) *AuthHandler {
-	return &AuthHandler{authSvc: authSvc, userSvc: userSvc}
-}
-
-// Login
-// @Description Log in an existing user
-// @Tags Auth
-// @Param LoginRequest body request.LoginRequest true "Login request"
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string "Example: {\"message\": \"Login successful\", \"token\": \"your-jwt-token\"}"
-// @Router /auth/login [post]
-func (h *AuthHandler) Login(c *gin.Context) {
-	var loginRequest request.LoginRequest
-	if err := c.ShouldBindJSON(&loginRequest); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-
-		return
-	}
-
-	token, err := h.authSvc.Login(&loginRequest)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusUnauthorized, err.Error())
-
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusOK, "email_exists", token)
-
-}
-
-// Register
-// @Description Register a new user
-// @Tags Auth
-// @Param RegisterRequest body request.RegisterRequest true "Register request"
-// @Accept json
-// @Produce json
-// @Success 201 {object} entities.User
-// @Router /auth/register [post]
-func (h *AuthHandler) Register(c *gin.Context) {
-	var registerRequest request.RegisterRequest
-	if err := c.ShouldBindJSON(&registerRequest); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	user, err := h.authSvc.Register(&registerRequest)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusConflict, err.Error())
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusCreated, "User created successfully", user)
-}
-
-// CheckEmail
-// @Description Check if email exists and respond accordingly
-// @Tags Authentication
-// @Param UserEmailRequest body request.UserEmailRequest true "Email request"
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string "Example: {\"message\": \"email_exists\"}"
-// @Router /auth/check-email [post]
-func (h *AuthHandler) CheckEmail(c *gin.Context) {
-	var request request.UserEmailRequest
-
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-
-		return
-	}
-
-	user, err := h.userSvc.GetByEmail(request.Email)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, "email_not_found")
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusOK, "email_exists", user)
-}
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/repositories"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AuthHandler struct {
+	authSvc services.AuthServiceInterface
+	mfaSvc  services.MFAServiceInterface
+}
+
+func NewAuthHandler(authSvc services.AuthServiceInterface, mfaSvc services.MFAServiceInterface) *AuthHandler {
+	return &AuthHandler{authSvc: authSvc, mfaSvc: mfaSvc}
+}
+
+// Login
+// @Description Log in an existing user. Users with two-factor authentication get an MFA token to pass to /auth/2fa/verify instead of the tokens.
+// @Tags Auth
+// @Param LoginRequest body request.LoginRequest true "Login request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=services.LoginResult}
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var loginRequest request.LoginRequest
+	if err := c.ShouldBindJSON(&loginRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	tokens, err := h.authSvc.Login(&loginRequest)
+	var rateLimitErr *services.RateLimitError
+	var restrictedErr *services.AccountRestrictedError
+	if errors.As(err, &rateLimitErr) {
+		response.TooManyRequestsResponse(c, rateLimitErr.RetryAfter)
+
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) || errors.As(err, &restrictedErr) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "email_exists", tokens)
+
+}
+
+// VerifyMFA
+// @Description Finish a login with the code from the authenticator app or a recovery code
+// @Tags Auth
+// @Param MFAVerifyRequest body request.MFAVerifyRequest true "MFA verify request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=services.TokenPair}
+// @Router /auth/2fa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var verifyRequest request.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&verifyRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.authSvc.VerifyMFA(verifyRequest.MFAToken, verifyRequest.Code)
+	var rateLimitErr *services.RateLimitError
+	var restrictedErr *services.AccountRestrictedError
+	if errors.As(err, &rateLimitErr) {
+		response.TooManyRequestsResponse(c, rateLimitErr.RetryAfter)
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) || errors.As(err, &restrictedErr) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Login successful", tokens)
+}
+
+// SetupTOTP
+// @Description Start two-factor enrollment. Accepts an access token or the MFA token of a login that requires enrollment.
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.Response{data=services.TOTPSetup}
+// @Security Bearer
+// @Router /auth/2fa/setup [post]
+func (h *AuthHandler) SetupTOTP(c *gin.Context) {
+	setup, err := h.mfaSvc.Setup(c.GetUint(middlewares.UserIDKey))
+	if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Scan the provisioning URI with an authenticator app", setup)
+}
+
+// ConfirmTOTP
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns the recovery codes, and the tokens when called with an MFA token.
+// @Tags Auth
+// @Param MFACodeRequest body request.MFACodeRequest true "MFA code request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=response.TOTPConfirmResponse}
+// @Security Bearer
+// @Router /auth/2fa/confirm [post]
+func (h *AuthHandler) ConfirmTOTP(c *gin.Context) {
+	var codeRequest request.MFACodeRequest
+	if err := c.ShouldBindJSON(&codeRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID := c.GetUint(middlewares.UserIDKey)
+	recoveryCodes, err := h.mfaSvc.Confirm(userID, codeRequest.Code)
+	var rateLimitErr *services.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		response.TooManyRequestsResponse(c, rateLimitErr.RetryAfter)
+		return
+	}
+	if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrTOTPNotSetUp) || errors.Is(err, services.ErrInvalidMFACode) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	confirmResponse := response.TOTPConfirmResponse{RecoveryCodes: recoveryCodes}
+	// Without a session the user enrolled with an MFA token while logging in
+	if c.GetString(middlewares.SessionIDKey) == "" {
+		tokens, err := h.authSvc.IssueTokensForUser(userID)
+		var restrictedErr *services.AccountRestrictedError
+		if errors.As(err, &restrictedErr) {
+			response.ErrorResponse(c, http.StatusForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		confirmResponse.Tokens = tokens
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Two-factor authentication enabled, store the recovery codes safely", confirmResponse)
+}
+
+// DisableTOTP
+// @Description Disable two-factor authentication with a code from the authenticator app or a recovery code
+// @Tags Auth
+// @Param MFACodeRequest body request.MFACodeRequest true "MFA code request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /auth/2fa/disable [post]
+func (h *AuthHandler) DisableTOTP(c *gin.Context) {
+	var codeRequest request.MFACodeRequest
+	if err := c.ShouldBindJSON(&codeRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.mfaSvc.Disable(c.GetUint(middlewares.UserIDKey), codeRequest.Code)
+	var rateLimitErr *services.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		response.TooManyRequestsResponse(c, rateLimitErr.RetryAfter)
+		return
+	}
+	if errors.Is(err, services.ErrMFARequired) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrTOTPNotEnabled) || errors.Is(err, services.ErrInvalidMFACode) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// Refresh
+// @Description Exchange a refresh token for a new access and refresh token pair
+// @Tags Auth
+// @Param RefreshTokenRequest body request.RefreshTokenRequest true "Refresh token request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=services.TokenPair}
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var refreshRequest request.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&refreshRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.authSvc.Refresh(refreshRequest.RefreshToken)
+	var restrictedErr *services.AccountRestrictedError
+	if errors.Is(err, repositories.ErrRefreshTokenInvalid) || errors.Is(err, repositories.ErrRefreshTokenReused) {
+		response.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if errors.As(err, &restrictedErr) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Token refreshed successfully", tokens)
+}
+
+// Logout
+// @Description Revoke the current session and all of its tokens
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.authSvc.Logout(c.GetString(middlewares.SessionIDKey)); err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll
+// @Description Revoke every session of the current user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	if err := h.authSvc.LogoutAll(c.GetUint(middlewares.UserIDKey)); err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Logged out of all sessions successfully", nil)
+}
+
+// Register
+// @Description Register a new user
+// @Tags Auth
+// @Param RegisterRequest body request.RegisterRequest true "Register request"
+// @Accept json
+// @Produce json
+// @Success 201 {object} entities.User
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var registerRequest request.RegisterRequest
+	if err := c.ShouldBindJSON(&registerRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.authSvc.Register(&registerRequest)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "User created successfully", user)
+}
+
+// VerifyEmail
+// @Description Verify the email address with the token from the verification email
+// @Tags Auth
+// @Param VerifyEmailRequest body request.VerifyEmailRequest true "Verify email request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var verifyRequest request.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&verifyRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.authSvc.VerifyEmail(verifyRequest.Token); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Email verified successfully", nil)
+}
+
+// ResendVerification
+// @Description Send another verification email
+// @Tags Auth
+// @Param ResendVerificationRequest body request.ResendVerificationRequest true "Resend verification request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response
+// @Router /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var resendRequest request.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&resendRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.authSvc.ResendVerification(resendRequest.Email)
+	if errors.Is(err, services.ErrVerificationCooldown) {
+		response.ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to send email")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "If the email belongs to an unverified account, a verification email has been sent.", nil)
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DataRequestHandler struct {
+	svc services.DataRequestServiceInterface
+}
+
+func NewDataRequestHandler(svc services.DataRequestServiceInterface) *DataRequestHandler {
+	return &DataRequestHandler{svc: svc}
+}
+
+// RequestExport
+// @Description Queue an export of the personal data of the current user as a ZIP of JSON files
+// @Tags Account
+// @Produce json
+// @Success 202 {object} response.Response{data=entities.DataRequest}
+// @Security Bearer
+// @Router /me/export [post]
+func (h *DataRequestHandler) RequestExport(c *gin.Context) {
+	export, err := h.svc.RequestExport(c.GetUint(middlewares.UserIDKey))
+	if err != nil {
+		respondDataRequestError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusAccepted, "Export queued", export)
+}
+
+// GetExport
+// @Description Get an export of the current user, with a short-lived download link once it is completed
+// @Tags Account
+// @Param id path uint true "Export ID"
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.DataRequest}
+// @Security Bearer
+// @Router /me/export/{id} [get]
+func (h *DataRequestHandler) GetExport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	export, err := h.svc.GetExport(c.GetUint(middlewares.UserIDKey), uint(id))
+	if err != nil {
+		respondDataRequestError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Export retrieved successfully", export)
+}
+
+// RequestErasure
+// @Description Queue the erasure of everything tied to a user. Their reviews and play history are kept anonymized.
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Produce json
+// @Success 202 {object} response.Response{data=entities.DataRequest}
+// @Security Bearer
+// @Router /user/{id}/erasure [post]
+func (h *DataRequestHandler) RequestErasure(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	erasure, err := h.svc.RequestErasure(uint(userID))
+	if err != nil {
+		respondDataRequestError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusAccepted, "Erasure queued", erasure)
+}
+
+func respondDataRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDataRequestOpen):
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrDataRequestNotFound):
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type FavoriteHandler struct {
+	svc services.FavoriteServiceInterface
+}
+
+func NewFavoriteHandler(svc services.FavoriteServiceInterface) *FavoriteHandler {
+	return &FavoriteHandler{svc: svc}
+}
+
+// GetAll
+// @Description Get the favorite games of the current user, last added first
+// @Tags Account
+// @Param query query request.FavoritesRequestQuery true "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=response.FavoritesResponse}
+// @Security Bearer
+// @Router /me/favorites [get]
+func (h *FavoriteHandler) GetAll(c *gin.Context) {
+	var query request.FavoritesRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	favorites, total, err := h.svc.GetByUser(c.GetUint(middlewares.UserIDKey), query)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Favorites retrieved successfully", response.FavoritesResponse{
+		Favorites:  favorites,
+		Total:      total,
+		PageNumber: query.PageNumber,
+		PageSize:   query.PageSize,
+	})
+}
+
+// Add
+// @Description Add a game to the favorites of the current user
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security Bearer
+// @Router /game/{id}/favorite [post]
+func (h *FavoriteHandler) Add(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	err = h.svc.Add(c.GetUint(middlewares.UserIDKey), uint(id))
+	if errors.Is(err, services.ErrGameNotFound) {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Game added to favorites", nil)
+}
+
+// Remove
+// @Description Remove a game from the favorites of the current user
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /game/{id}/favorite [delete]
+func (h *FavoriteHandler) Remove(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Remove(c.GetUint(middlewares.UserIDKey), uint(id)); err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Game removed from favorites", nil)
+}
@@ -1,65 +1,221 @@
-package handler
-
-import (
-	"fmt"
-	"net/http"
-
-	"crazygames.io/config"
-	"crazygames.io/handler/response"
-	"crazygames.io/services"
-	"crazygames.io/utils"
-	"github.com/gin-gonic/gin"
-)
-
-type OAuthHandler struct {
-	svc services.OAuthServiceInterface
-}
-
-func NewOAuthHandler(svc services.OAuthServiceInterface) *OAuthHandler {
-	return &OAuthHandler{
-		svc: svc,
-	}
-}
-
-// Google Login Handler
-func (h *OAuthHandler) GoogleLogin(c *gin.Context) {
-	authURL := h.svc.GetGoogleAuthURL("state-token")
-	c.JSON(http.StatusOK, gin.H{"redirect_url": authURL})
-}
-
-// Google Callback Handler
-func (h *OAuthHandler) GoogleCallback(c *gin.Context) {
-	state := c.Query("state")
-	if state != "state-token" {
-		response.ErrorResponse(c, http.StatusBadRequest, "Invalid state")
-		return
-	}
-
-	code := c.Query("code")
-	if code == "" {
-		response.ErrorResponse(c, http.StatusBadRequest, "Missing code parameter")
-		return
-	}
-
-	token, err := h.svc.ExchangeCodeForToken(c.Request.Context(), code)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	// Fetch user info
-	userInfo, err := utils.FetchUserInfo(token.AccessToken)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	// Save user to database
-	if err := h.svc.HandleGoogleUser(userInfo); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	queryStrings := fmt.Sprintf("?token=%v&uid=%v&uname=%v", token.AccessToken, userInfo["email"], userInfo["name"])
-	c.Redirect(http.StatusTemporaryRedirect, config.AppConfig.FRONTEND_URL+queryStrings)
-}
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"crazygames.io/config"
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/repositories"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateCookie binds a login's state to the browser that started it.
+const oauthStateCookie = "oauth_state"
+
+type OAuthHandler struct {
+	svc services.OAuthServiceInterface
+}
+
+func NewOAuthHandler(svc services.OAuthServiceInterface) *OAuthHandler {
+	return &OAuthHandler{
+		svc: svc,
+	}
+}
+
+// Login Handler
+func (h *OAuthHandler) Login(c *gin.Context) {
+	authURL, state, err := h.svc.GetAuthURL(c.Param("provider"), c.Query("return_to"))
+	h.redirectToProvider(c, authURL, state, err)
+}
+
+// Link
+// @Description Start linking an OAuth provider to the current user
+// @Tags Auth
+// @Param provider path string true "OAuth provider"
+// @Param return_to query string false "Frontend page to return to after linking"
+// @Produce json
+// @Success 200 {object} map[string]string "Example: {\"redirect_url\": \"https://accounts.google.com/...\"}"
+// @Security Bearer
+// @Router /Oauth/{provider}/link [get]
+func (h *OAuthHandler) Link(c *gin.Context) {
+	authURL, state, err := h.svc.GetLinkURL(c.Param("provider"), c.GetUint(middlewares.UserIDKey), c.Query("return_to"))
+	h.redirectToProvider(c, authURL, state, err)
+}
+
+func (h *OAuthHandler) redirectToProvider(c *gin.Context, authURL string, state string, err error) {
+	if errors.Is(err, services.ErrUnknownProvider) {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrInvalidReturnURL) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, int(services.OAuthStateTTL.Seconds()), "/api/Oauth", "", config.AppConfig.CookieSecure, true)
+	c.JSON(http.StatusOK, gin.H{"redirect_url": authURL})
+}
+
+// Callback Handler
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(oauthStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, "/api/Oauth", "", config.AppConfig.CookieSecure, true)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	// The state also pins the provider, so a login started with one provider
+	// cannot be completed with another.
+	oauthState, err := h.svc.ConsumeState(state)
+	if err != nil || oauthState.Provider != c.Param("provider") {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid state")
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		response.ErrorResponse(c, http.StatusBadRequest, providerErr)
+		return
+	}
+
+	code := c.Query("code")
+	if code == "" {
+		response.ErrorResponse(c, http.StatusBadRequest, "Missing code parameter")
+		return
+	}
+
+	token, err := h.svc.ExchangeCodeForToken(c.Request.Context(), oauthState.Provider, code, oauthState.CodeVerifier)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Fetch user info
+	userInfo, err := h.svc.FetchUserInfo(c.Request.Context(), oauthState.Provider, token)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if oauthState.LinkUserID != 0 {
+		err := h.svc.LinkIdentity(oauthState.LinkUserID, userInfo)
+		if errors.Is(err, services.ErrIdentityInUse) || errors.Is(err, services.ErrProviderAlreadyLinked) {
+			response.ErrorResponse(c, http.StatusConflict, err.Error())
+			return
+		}
+		if err != nil {
+			response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		c.Redirect(http.StatusTemporaryRedirect, h.svc.LinkRedirectURL(oauthState.ReturnTo, oauthState.Provider))
+		return
+	}
+
+	// Save user to database
+	user, err := h.svc.HandleOAuthUser(userInfo)
+	if errors.Is(err, services.ErrOAuthEmailNotVerified) || errors.Is(err, services.ErrIdentityInUse) || errors.Is(err, services.ErrProviderAlreadyLinked) {
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	loginCode, err := h.svc.CreateLoginCode(user)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Redirect(http.StatusTemporaryRedirect, h.svc.LoginRedirectURL(oauthState.ReturnTo, loginCode))
+}
+
+// Exchange
+// @Description Trade the one-time code from the OAuth redirect for an access and refresh token pair
+// @Tags Auth
+// @Param OAuthExchangeRequest body request.OAuthExchangeRequest true "Exchange request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=services.LoginResult}
+// @Router /Oauth/exchange [post]
+func (h *OAuthHandler) Exchange(c *gin.Context) {
+	var exchangeRequest request.OAuthExchangeRequest
+	if err := c.ShouldBindJSON(&exchangeRequest); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokens, err := h.svc.ExchangeLoginCode(exchangeRequest.Code)
+	var restrictedErr *services.AccountRestrictedError
+	if errors.Is(err, repositories.ErrLoginCodeInvalid) {
+		response.ErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrEmailNotVerified) || errors.As(err, &restrictedErr) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Login successful", tokens)
+}
+
+// Unlink
+// @Description Unlink an OAuth provider from the current user
+// @Tags Auth
+// @Param provider path string true "OAuth provider"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /Oauth/{provider}/link [delete]
+func (h *OAuthHandler) Unlink(c *gin.Context) {
+	err := h.svc.UnlinkIdentity(c.GetUint(middlewares.UserIDKey), c.Param("provider"))
+	if errors.Is(err, services.ErrIdentityNotFound) {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrLastLoginMethod) {
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Provider unlinked successfully", nil)
+}
+
+// GetIdentities
+// @Description List the OAuth providers linked to the current user
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.UserIdentity}
+// @Security Bearer
+// @Router /Oauth/identities [get]
+func (h *OAuthHandler) GetIdentities(c *gin.Context) {
+	identities, err := h.svc.GetIdentities(c.GetUint(middlewares.UserIDKey))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Identities retrieved successfully", identities)
+}
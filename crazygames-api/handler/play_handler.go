@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PlayHandler struct {
+	svc services.PlayServiceInterface
+}
+
+func NewPlayHandler(svc services.PlayServiceInterface) *PlayHandler {
+	return &PlayHandler{svc: svc}
+}
+
+// Play
+// @Description Start a play session of a game, for the current user when logged in. The game page keeps it going with heartbeats and ends it when the player leaves.
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Produce json
+// @Success 202 {object} response.Response{data=response.PlaySessionResponse}
+// @Security Bearer
+// @Router /game/{id}/play [post]
+func (h *PlayHandler) Play(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	history, err := h.svc.StartSession(middlewares.OptionalUserID(c), uint(id))
+	if errors.Is(err, services.ErrGameNotFound) {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusAccepted, "Play recorded", response.PlaySessionResponse{
+		SessionID:        history.SessionID,
+		HeartbeatSeconds: int(h.svc.HeartbeatInterval().Seconds()),
+	})
+}
+
+// Heartbeat
+// @Description Keep a play session going. A session that timed out cannot be resumed, start a new one instead.
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Param session_id path string true "Session ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Router /game/{id}/play/{session_id}/heartbeat [post]
+func (h *PlayHandler) Heartbeat(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Heartbeat(uint(id), c.Param("session_id")); err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Heartbeat recorded", nil)
+}
+
+// EndSession
+// @Description End a play session, when the player leaves the game page
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Param session_id path string true "Session ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Router /game/{id}/play/{session_id}/end [post]
+func (h *PlayHandler) EndSession(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.EndSession(uint(id), c.Param("session_id")); err != nil {
+		respondPlaySessionError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Play session ended", nil)
+}
+
+func respondPlaySessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPlaySessionNotFound):
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrPlaySessionEnded):
+		response.ErrorResponse(c, http.StatusGone, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// GetRecentlyPlayed
+// @Description Get the games the current user played, each once, last played first
+// @Tags Account
+// @Param query query request.RecentlyPlayedRequestQuery false "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=[]repositories.RecentlyPlayedGame}
+// @Security Bearer
+// @Router /me/recently-played [get]
+func (h *PlayHandler) GetRecentlyPlayed(c *gin.Context) {
+	var query request.RecentlyPlayedRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	games, err := h.svc.GetRecentlyPlayed(c.GetUint(middlewares.UserIDKey), query.Limit)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Recently played games retrieved successfully", games)
+}
+
+// GetPlaytime
+// @Description Get how long the current user played, in total and in each game
+// @Tags Account
+// @Produce json
+// @Success 200 {object} response.Response{data=services.UserPlaytime}
+// @Security Bearer
+// @Router /me/playtime [get]
+func (h *PlayHandler) GetPlaytime(c *gin.Context) {
+	playtime, err := h.svc.GetUserPlaytime(c.GetUint(middlewares.UserIDKey))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Playtime retrieved successfully", playtime)
+}
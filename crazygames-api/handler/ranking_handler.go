@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RankingHandler struct {
+	svc services.RankingServiceInterface
+}
+
+func NewRankingHandler(svc services.RankingServiceInterface) *RankingHandler {
+	return &RankingHandler{svc: svc}
+}
+
+// GetTrending
+// @Description Get the games trending now, by their recent plays, flagging the favorites of the current user when logged in
+// @Tags Games
+// @Param query query request.RankingRequestQuery false "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.Game}
+// @Security Bearer
+// @Router /game/trending [get]
+func (h *RankingHandler) GetTrending(c *gin.Context) {
+	var query request.RankingRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	games, err := h.svc.GetTrending(query.Limit, middlewares.OptionalUserID(c))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Trending games retrieved successfully", games)
+}
+
+// GetPopular
+// @Description Get the most played games of the last day, the last week or all time, flagging the favorites of the current user when logged in
+// @Tags Games
+// @Param query query request.PopularRequestQuery false "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.Game}
+// @Security Bearer
+// @Router /game/popular [get]
+func (h *RankingHandler) GetPopular(c *gin.Context) {
+	var query request.PopularRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	games, err := h.svc.GetPopular(query.Window, query.Limit, middlewares.OptionalUserID(c))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Popular games retrieved successfully", games)
+}
+
+// GetNew
+// @Description Get the games last added, flagging the favorites of the current user when logged in
+// @Tags Games
+// @Param query query request.RankingRequestQuery false "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.Game}
+// @Security Bearer
+// @Router /game/new [get]
+func (h *RankingHandler) GetNew(c *gin.Context) {
+	var query request.RankingRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	games, err := h.svc.GetNew(query.Limit, middlewares.OptionalUserID(c))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "New games retrieved successfully", games)
+}
@@ -0,0 +1,32 @@
+package request
+
+import "mime/multipart"
+
+// ProfileRequest replaces the profile; empty fields clear it.
+type ProfileRequest struct {
+	DisplayName string `json:"display_name" binding:"omitempty,max=50"`
+	Bio         string `json:"bio" binding:"omitempty,max=500"`
+	Country     string `json:"country" binding:"omitempty,iso3166_1_alpha2"`
+}
+
+type AvatarRequest struct {
+	Avatar *multipart.FileHeader `form:"avatar" binding:"required"`
+}
+
+// CurrentPassword may be left out by users that only log in through an OAuth
+// provider and have no password yet, if they logged in recently.
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// Password is left out like CurrentPassword of ChangePasswordRequest.
+type ChangeEmailRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password"`
+}
+
+// Password is left out like CurrentPassword of ChangePasswordRequest.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,10 @@
+package request
+
+import "time"
+
+type APIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,required"`
+	// ExpiresAt is optional; keys without it stay valid until revoked.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,17 @@
+package request
+
+import "time"
+
+// AuditRequestQuery filters the audit log. From and To are RFC 3339 times; To
+// is exclusive.
+type AuditRequestQuery struct {
+	PageNumber int        `form:"page_number" binding:"required,min=1"`
+	PageSize   int        `form:"page_size" binding:"required,min=1,max=100"`
+	ActorID    uint       `form:"actor_id"`
+	Action     string     `form:"action" binding:"omitempty,oneof=create update delete"`
+	EntityType string     `form:"entity_type"`
+	EntityID   uint       `form:"entity_id"`
+	RequestID  string     `form:"request_id"`
+	From       *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
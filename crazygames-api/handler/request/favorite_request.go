@@ -0,0 +1,6 @@
+package request
+
+type FavoritesRequestQuery struct {
+	PageNumber int `form:"page_number" binding:"required,min=1"`
+	PageSize   int `form:"page_size" binding:"required,min=1,max=100"`
+}
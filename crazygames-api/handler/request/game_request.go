@@ -1,37 +1,43 @@
-package request
-
-import "mime/multipart"
-
-type GameRequestCreate struct {
-	GameTitle   string                `form:"game_title" binding:"required"`
-	Description string                `form:"description"`
-	Developer   string                `form:"developer"`
-	CategoryID  string                `form:"category_id"`
-	ReleaseDate string                `form:"release_date"`
-	Thumbnail   *multipart.FileHeader `form:"thumbnail"`
-	Technology  string                `form:"technology"`
-	Rating      float64               `form:"rating"`
-	HoverVideo  *multipart.FileHeader `form:"hover_video"`
-	GameURL     string                `form:"game_url"`
-	PlayCount   int                   `form:"play_count"`
-}
-
-type GameRequestUpdate struct {
-	GameTitle   string                `form:"game_title" binding:"required"`
-	Description string                `form:"description"`
-	Developer   string                `form:"developer"`
-	CategoryID  string                `form:"category_id"`
-	ReleaseDate string                `form:"release_date"`
-	Thumbnail   *multipart.FileHeader `form:"thumbnail"`
-	Technology  string                `form:"technology"`
-	Rating      float64               `form:"rating"`
-	HoverVideo  *multipart.FileHeader `form:"hover_video"`
-	GameURL     string                `form:"game_url"`
-	PlayCount   int                   `form:"play_count"`
-}
-
-type GamesRequestQuery struct {
-	PageNumber int    `form:"page_number" binding:"required,min=1"`
-	PageSize   int    `form:"page_size" binding:"required,min=1,max=100"`
-	Search     string `form:"search"`
-}
+package request
+
+import "mime/multipart"
+
+type GameRequestCreate struct {
+	GameTitle   string                `form:"game_title" binding:"required"`
+	Description string                `form:"description"`
+	Developer   string                `form:"developer"`
+	ReleaseDate string                `form:"release_date"`
+	Thumbnail   *multipart.FileHeader `form:"thumbnail"`
+	Technology  string                `form:"technology"`
+	HoverVideo  *multipart.FileHeader `form:"hover_video"`
+	GameURL     string                `form:"game_url"`
+
+	// PrimaryCategoryID must be one of CategoryIDs, the first by default
+	CategoryIDs       []uint `form:"category_ids" binding:"required,min=1,max=10"`
+	PrimaryCategoryID *uint  `form:"primary_category_id"`
+}
+
+type GameRequestUpdate struct {
+	GameTitle   string                `form:"game_title" binding:"required"`
+	Description string                `form:"description"`
+	Developer   string                `form:"developer"`
+	ReleaseDate string                `form:"release_date"`
+	Thumbnail   *multipart.FileHeader `form:"thumbnail"`
+	Technology  string                `form:"technology"`
+	HoverVideo  *multipart.FileHeader `form:"hover_video"`
+	GameURL     string                `form:"game_url"`
+
+	// CategoryIDs replaces the categories of the game, which keeps them when
+	// it is omitted. PrimaryCategoryID must be one of the game's categories
+	// and defaults to the current one if the game keeps it, else to the first
+	CategoryIDs       []uint `form:"category_ids" binding:"omitempty,max=10"`
+	PrimaryCategoryID *uint  `form:"primary_category_id"`
+}
+
+type GamesRequestQuery struct {
+	PageNumber int    `form:"page_number" binding:"required,min=1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100"`
+	Search     string `form:"search"`
+	// Tags is a comma separated list of tag slugs the games must all have
+	Tags string `form:"tags"`
+}
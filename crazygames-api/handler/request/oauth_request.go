@@ -0,0 +1,5 @@
+package request
+
+type OAuthExchangeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
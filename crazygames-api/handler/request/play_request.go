@@ -0,0 +1,5 @@
+package request
+
+type RecentlyPlayedRequestQuery struct {
+	Limit int `form:"limit,default=20" binding:"min=1,max=50"`
+}
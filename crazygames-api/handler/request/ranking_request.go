@@ -0,0 +1,10 @@
+package request
+
+type RankingRequestQuery struct {
+	Limit int `form:"limit,default=20" binding:"min=1,max=50"`
+}
+
+type PopularRequestQuery struct {
+	Window string `form:"window,default=week" binding:"oneof=day week all"`
+	Limit  int    `form:"limit,default=20" binding:"min=1,max=50"`
+}
@@ -0,0 +1,17 @@
+package request
+
+const (
+	ReviewSortNewest  = "newest"
+	ReviewSortHelpful = "helpful"
+)
+
+type ReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"omitempty,max=2000"`
+}
+
+type ReviewsRequestQuery struct {
+	PageNumber int    `form:"page_number" binding:"required,min=1"`
+	PageSize   int    `form:"page_size" binding:"required,min=1,max=100"`
+	Sort       string `form:"sort,default=newest" binding:"oneof=newest helpful"`
+}
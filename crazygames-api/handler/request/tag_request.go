@@ -0,0 +1,21 @@
+package request
+
+type TagRequest struct {
+	TagName string `json:"tag_name" binding:"required,max=100"`
+	// Slug defaults to the tag name in lowercase with hyphens
+	Slug string `json:"slug" binding:"omitempty,max=100"`
+}
+
+type TagsRequestQuery struct {
+	Search string `form:"search"`
+}
+
+type TagGamesRequestQuery struct {
+	PageNumber int `form:"page_number" binding:"required,min=1"`
+	PageSize   int `form:"page_size" binding:"required,min=1,max=100"`
+}
+
+// GameTagsRequest replaces the tags of a game; an empty list removes them all.
+type GameTagsRequest struct {
+	TagIDs []uint `json:"tag_ids" binding:"required,max=50"`
+}
@@ -1,15 +1,44 @@
-package request
-
-type UserRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required,min=6"`
-	Email    string `json:"email" binding:"required,email"`
-	Role     string `json:"role"`
-}
-
-type UserUpdateRequest struct {
-	Username string `json:"username" binding:"omitempty"`
-	Password string `json:"password" binding:"omitempty,min=6"`
-	Email    string `json:"email" binding:"omitempty,email"`
-	Role     string `json:"role" binding:"omitempty,oneof=player admin"`
-}
+package request
+
+import "time"
+
+type UserRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+	Email    string `json:"email" binding:"required,email"`
+	Role     string `json:"role"`
+}
+
+// UserUpdateRequest cannot change the role, which takes AssignRoleRequest and
+// the role:assign permission.
+type UserUpdateRequest struct {
+	Username string `json:"username" binding:"omitempty"`
+	Password string `json:"password" binding:"omitempty,min=6"`
+	Email    string `json:"email" binding:"omitempty,email"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UsersRequestQuery searches the users. Search matches part of the username or
+// email, CreatedFrom and CreatedTo are RFC 3339 times and CreatedTo is exclusive.
+type UsersRequestQuery struct {
+	Page        int        `form:"page,default=1" binding:"min=1"`
+	Limit       int        `form:"limit,default=10" binding:"min=1,max=100"`
+	Search      string     `form:"search"`
+	Role        string     `form:"role"`
+	Status      string     `form:"status" binding:"omitempty,oneof=active suspended banned"`
+	CreatedFrom *time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   *time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort        string     `form:"sort,default=id" binding:"oneof=id username email role status created_at"`
+	Order       string     `form:"order,default=asc" binding:"oneof=asc desc"`
+}
+
+// UserStatusRequest suspends a user until Until, bans them, or lifts either
+// with the active status.
+type UserStatusRequest struct {
+	Status string     `json:"status" binding:"required,oneof=active suspended banned"`
+	Until  *time.Time `json:"until" binding:"required_if=Status suspended"`
+	Reason string     `json:"reason" binding:"max=500"`
+}
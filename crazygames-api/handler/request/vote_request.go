@@ -0,0 +1,5 @@
+package request
+
+type VoteRequest struct {
+	Vote string `json:"vote" binding:"required,oneof=up down clear"`
+}
@@ -0,0 +1,9 @@
+package response
+
+import "crazygames.io/entities"
+
+type APIKeyCreatedResponse struct {
+	// Key is only returned when the key is created.
+	Key    string           `json:"key"`
+	APIKey *entities.APIKey `json:"api_key"`
+}
@@ -0,0 +1,10 @@
+package response
+
+import "crazygames.io/entities"
+
+type AuditEventsResponse struct {
+	Events     []entities.AuditEvent `json:"events"`
+	Total      int64                 `json:"total"`
+	PageNumber int                   `json:"pageNumber"`
+	PageSize   int                   `json:"pageSize"`
+}
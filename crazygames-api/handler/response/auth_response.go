@@ -0,0 +1,9 @@
+package response
+
+import "crazygames.io/services"
+
+type TOTPConfirmResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Tokens is set when the user enrolled during login with an MFA token.
+	Tokens *services.TokenPair `json:"tokens,omitempty"`
+}
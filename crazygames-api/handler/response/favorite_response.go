@@ -0,0 +1,10 @@
+package response
+
+import "crazygames.io/entities"
+
+type FavoritesResponse struct {
+	Favorites  []entities.Favorite `json:"favorites"`
+	Total      int64               `json:"total"`
+	PageNumber int                 `json:"pageNumber"`
+	PageSize   int                 `json:"pageSize"`
+}
@@ -0,0 +1,7 @@
+package response
+
+type PlaySessionResponse struct {
+	SessionID string `json:"session_id"`
+	// HeartbeatSeconds is how often the game page should send a heartbeat.
+	HeartbeatSeconds int `json:"heartbeat_seconds"`
+}
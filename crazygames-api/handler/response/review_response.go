@@ -0,0 +1,10 @@
+package response
+
+import "crazygames.io/repositories"
+
+type ReviewsResponse struct {
+	Reviews    []repositories.GameReview `json:"reviews"`
+	Total      int64                     `json:"total"`
+	PageNumber int                       `json:"pageNumber"`
+	PageSize   int                       `json:"pageSize"`
+}
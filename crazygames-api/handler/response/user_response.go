@@ -0,0 +1,12 @@
+package response
+
+import "crazygames.io/entities"
+
+// UsersResponse keeps the keys the user list has always had, with TotalCount
+// counting every matching user rather than the page.
+type UsersResponse struct {
+	TotalCount  int64           `json:"totalCount"`
+	CurrentPage int             `json:"currentPage"`
+	TotalPages  int64           `json:"totalPages"`
+	Users       []entities.User `json:"users"`
+}
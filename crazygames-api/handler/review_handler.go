@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewHandler struct {
+	svc services.ReviewServiceInterface
+}
+
+func NewReviewHandler(svc services.ReviewServiceInterface) *ReviewHandler {
+	return &ReviewHandler{svc: svc}
+}
+
+// GetByGame
+// @Description Get the reviews of a game, newest or most helpful first
+// @Tags Reviews
+// @Param id path uint true "Game ID"
+// @Param query query request.ReviewsRequestQuery true "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=response.ReviewsResponse}
+// @Failure 404 {object} response.Response
+// @Router /game/{id}/reviews [get]
+func (h *ReviewHandler) GetByGame(c *gin.Context) {
+	gameID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var query request.ReviewsRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reviews, total, err := h.svc.GetByGame(uint(gameID), query)
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Reviews retrieved successfully", response.ReviewsResponse{
+		Reviews:    reviews,
+		Total:      total,
+		PageNumber: query.PageNumber,
+		PageSize:   query.PageSize,
+	})
+}
+
+// Create
+// @Description Review a game as the current user, once per game
+// @Tags Reviews
+// @Param id path uint true "Game ID"
+// @Param request body request.ReviewRequest true "Review"
+// @Accept json
+// @Produce json
+// @Success 201 {object} response.Response{data=entities.Review}
+// @Failure 409 {object} response.Response
+// @Security Bearer
+// @Router /game/{id}/reviews [post]
+func (h *ReviewHandler) Create(c *gin.Context) {
+	gameID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req request.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.svc.Create(c.Request.Context(), c.GetUint(middlewares.UserIDKey), uint(gameID), &req)
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Review created successfully", review)
+}
+
+// Update
+// @Description Edit a review of the current user
+// @Tags Reviews
+// @Param id path uint true "Review ID"
+// @Param request body request.ReviewRequest true "Review"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.Review}
+// @Failure 403 {object} response.Response
+// @Security Bearer
+// @Router /review/{id} [put]
+func (h *ReviewHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req request.ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	review, err := h.svc.Update(c.Request.Context(), c.GetUint(middlewares.UserIDKey), uint(id), &req)
+	if err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Review updated successfully", review)
+}
+
+// Delete
+// @Description Delete a review of the current user
+// @Tags Reviews
+// @Param id path uint true "Review ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Security Bearer
+// @Router /review/{id} [delete]
+func (h *ReviewHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), c.GetUint(middlewares.UserIDKey), uint(id)); err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Review deleted successfully", nil)
+}
+
+// Vote
+// @Description Mark a review of another user as helpful
+// @Tags Reviews
+// @Param id path uint true "Review ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /review/{id}/helpful [post]
+func (h *ReviewHandler) Vote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Vote(c.GetUint(middlewares.UserIDKey), uint(id)); err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Review marked as helpful", nil)
+}
+
+// Unvote
+// @Description Take back marking a review as helpful
+// @Tags Reviews
+// @Param id path uint true "Review ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /review/{id}/helpful [delete]
+func (h *ReviewHandler) Unvote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Unvote(c.GetUint(middlewares.UserIDKey), uint(id)); err != nil {
+		respondReviewError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Review no longer marked as helpful", nil)
+}
+
+func respondReviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrGameNotFound), errors.Is(err, services.ErrReviewNotFound):
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrReviewExists):
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrNotReviewAuthor), errors.Is(err, services.ErrOwnReviewVote):
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
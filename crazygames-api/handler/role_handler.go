@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"crazygames.io/handler/response"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	svc services.RoleServiceInterface
+}
+
+func NewRoleHandler(svc services.RoleServiceInterface) *RoleHandler {
+	return &RoleHandler{svc: svc}
+}
+
+// GetAll
+// @Description Get all roles with their permissions
+// @Tags Roles
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.Role}
+// @Security Bearer
+// @Router /role [get]
+func (h *RoleHandler) GetAll(c *gin.Context) {
+	roles, err := h.svc.GetAll()
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Roles retrieved successfully", roles)
+}
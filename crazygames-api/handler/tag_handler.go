@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	svc services.TagServiceInterface
+}
+
+func NewTagHandler(svc services.TagServiceInterface) *TagHandler {
+	return &TagHandler{svc: svc}
+}
+
+// GetAll
+// @Description Get all tags by name
+// @Tags Tags
+// @Param query query request.TagsRequestQuery false "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.Tag}
+// @Router /tag [get]
+func (h *TagHandler) GetAll(c *gin.Context) {
+	var query request.TagsRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags, err := h.svc.GetAll(query)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Tags retrieved successfully", tags)
+}
+
+// GetGames
+// @Description Get the games with a tag, flagging the favorites of the current user when logged in
+// @Tags Tags
+// @Param slug path string true "Tag slug"
+// @Param query query request.TagGamesRequestQuery true "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=response.GamesResponse}
+// @Failure 404 {object} response.Response
+// @Security Bearer
+// @Router /tag/{slug}/games [get]
+func (h *TagHandler) GetGames(c *gin.Context) {
+	var query request.TagGamesRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	games, total, err := h.svc.GetGames(c.Param("slug"), query, middlewares.OptionalUserID(c))
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Games retrieved successfully", response.GamesResponse{
+		Games:      games,
+		Total:      total,
+		PageNumber: query.PageNumber,
+		PageSize:   query.PageSize,
+	})
+}
+
+// Create
+// @Description Create a tag
+// @Tags Tags
+// @Param request body request.TagRequest true "Tag"
+// @Accept json
+// @Produce json
+// @Success 201 {object} response.Response{data=entities.Tag}
+// @Failure 409 {object} response.Response
+// @Security Bearer
+// @Router /tag [post]
+func (h *TagHandler) Create(c *gin.Context) {
+	var request request.TagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tag, err := h.svc.Create(c.Request.Context(), &request)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "Tag created successfully", tag)
+}
+
+// Update
+// @Description Update a tag
+// @Tags Tags
+// @Param id path uint true "Tag ID"
+// @Param request body request.TagRequest true "Tag"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.Tag}
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Security Bearer
+// @Router /tag/{id} [put]
+func (h *TagHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var request request.TagRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tag, err := h.svc.Update(c.Request.Context(), uint(id), &request)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Tag updated successfully", tag)
+}
+
+// Delete
+// @Description Delete a tag and remove it from its games
+// @Tags Tags
+// @Param id path uint true "Tag ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Security Bearer
+// @Router /tag/{id} [delete]
+func (h *TagHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Tag deleted successfully", nil)
+}
+
+// SetGameTags
+// @Description Replace the tags of a game
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Param request body request.GameTagsRequest true "Tag IDs"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]entities.Tag}
+// @Failure 404 {object} response.Response
+// @Security Bearer
+// @Router /game/{id}/tags [put]
+func (h *TagHandler) SetGameTags(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var request request.GameTagsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tags, err := h.svc.SetGameTags(c.Request.Context(), uint(id), request.TagIDs)
+	if err != nil {
+		respondTagError(c, err)
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Game tags updated successfully", tags)
+}
+
+func respondTagError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTagNotFound), errors.Is(err, services.ErrGameNotFound):
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrTagExists):
+		response.ErrorResponse(c, http.StatusConflict, err.Error())
+	case errors.Is(err, services.ErrInvalidTagSlug):
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+	default:
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,273 +1,381 @@
-package handler
-
-import (
-	"bytes"
-	"fmt"
-	"html/template"
-	"net/http"
-	"os"
-	"strconv"
-	"time"
-
-	"crazygames.io/config"
-	"crazygames.io/handler/response"
-
-	"crazygames.io/handler/request"
-	"crazygames.io/services"
-	"github.com/gin-gonic/gin"
-)
-
-type UserHandler struct {
-	svc services.UserServiceInterface
-}
-
-func NewUserHandler(svc services.UserServiceInterface) *UserHandler {
-	return &UserHandler{svc: svc}
-}
-
-// Create
-// @Description Create a new user
-// @Tags Users
-// @Param UserRequest body request.UserRequest true "Create user request"
-// @Accept json
-// @Produce json
-// @Success 201 {object} entities.User
-// @Router /user [post]
-func (h *UserHandler) Create(c *gin.Context) {
-	var request request.UserRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	user, err := h.svc.Create(&request)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusCreated, "User created successfully", user)
-}
-
-// GetAll
-// @Description Get all users
-// @Tags Users
-// @Accept json
-// @Produce json
-// @Success 200 {array} []entities.User
-// @Router /user [get]
-func (h *UserHandler) GetAll(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		response.ErrorResponse(c, http.StatusBadRequest, "Invalid page number")
-		return
-	}
-
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil || limit < 1 {
-		response.ErrorResponse(c, http.StatusBadRequest, "Invalid limit number")
-		return
-	}
-
-	users, err := h.svc.GetAll(page, limit)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// Calculate total pages
-	totalPages := (len(users) + limit - 1) / limit
-
-	response.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", gin.H{
-		"totalCount":  len(users),
-		"currentPage": page,
-		"totalPages":  totalPages,
-		"users":       users,
-	})
-}
-
-// GetByID
-// @Description Get user by id
-// @Tags Users
-// @Param id path uint true "User ID"
-// @Accept json
-// @Produce json
-// @Success 200 {object} entities.User
-// @Router /user/{id} [get]
-func (h *UserHandler) GetByID(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
-		return
-	}
-
-	user, err := h.svc.GetByID(uint(id))
-	if err != nil {
-		response.ErrorResponse(c, http.StatusNotFound, "User not found")
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusOK, "successfully", user)
-}
-
-// Update
-// @Description Update a user
-// @Tags Users
-// @Param id path uint true "User ID"
-// @Param UserUpdateRequest body request.UserUpdateRequest true "Update user request"
-// @Accept json
-// @Produce json
-// @Success 200 {object} entities.User
-// @Router /user/{id} [put]
-func (h *UserHandler) Update(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
-		return
-	}
-
-	var request request.UserUpdateRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	user, err := h.svc.Update(uint(id), &request)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
-}
-
-// Delete
-// @Description Update a user
-// @Tags Users
-// @Param id path uint true "User ID"
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string "Example: {\"message\": \"User deleted successfully\"}"
-// @Router /user/{id} [delete]
-func (h *UserHandler) Delete(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
-		return
-	}
-
-	if err := h.svc.Delete(uint(id)); err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-	response.SuccessResponse(c, http.StatusOK, "User deleted successfully", nil)
-}
-
-// Forgot Password
-// @Description Forgot Password
-// @Tags Users
-// @Param UserEmailRequest body request.UserEmailRequest true "Forgot password Request"
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string "Example: {\"message\": \"The password reset email has been sent.\"}"
-// @Router /user/forgot-password [post]
-func (h *UserHandler) ForgotPassword(c *gin.Context) {
-	var request request.UserEmailRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	token, err := h.svc.GenerateResetToken(request.Email)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	emailService := services.NewEmailService()
-
-	redirectUrl := config.SMTP.RedirectUrl
-	resetLink := redirectUrl + fmt.Sprintf("/reset-password?token=%s", token)
-
-	htmlContent, err := os.ReadFile("templates/email_template.html")
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// Parse the template
-	tmpl, err := template.New("email").Parse(string(htmlContent))
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// Replace placeholders
-	var body bytes.Buffer
-	err = tmpl.Execute(&body, map[string]string{"ResetLink": resetLink})
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	err = emailService.SendEmail(request.Email, "Password Reset", body.String())
-
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, "Failed to send email")
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusOK, "The password reset email has been sent.", nil)
-}
-
-// Reset Password
-// @Description Reset Password
-// @Tags Users
-// @Query token path string true "token"
-// @Param UserResetPasswordRequest body request.UserResetPasswordRequest true "Reset password Request"
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string "Example: {\"message\": \"Password reset successful.\"}"
-// @Router /user/reset-password [post]
-func (h *UserHandler) ResetPassword(c *gin.Context) {
-	token := c.Query("token")
-	if token == "" {
-		response.ErrorResponse(c, http.StatusBadRequest, "Token is required")
-		return
-	}
-	resetToken, err := h.svc.ValidateResetToken(token)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	if resetToken.ExpiredAt.Before(time.Now()) {
-		response.ErrorResponse(c, http.StatusBadRequest, "Token has expired")
-		return
-	}
-	if resetToken.IsUsed {
-		response.ErrorResponse(c, http.StatusBadRequest, "Token has already been used")
-		return
-	}
-
-	var request request.UserResetPasswordRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	err = h.svc.ResetPassword(request.NewPassword, resetToken.Email)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	err = h.svc.MarkTokenAsUsed(resetToken.ID)
-	if err != nil {
-		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	response.SuccessResponse(c, http.StatusOK, "Password reset successful.", nil)
-}
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/config"
+	"crazygames.io/handler/response"
+
+	"crazygames.io/handler/request"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	svc services.UserServiceInterface
+}
+
+func NewUserHandler(svc services.UserServiceInterface) *UserHandler {
+	return &UserHandler{svc: svc}
+}
+
+// Create
+// @Description Create a new user
+// @Tags Users
+// @Param UserRequest body request.UserRequest true "Create user request"
+// @Accept json
+// @Produce json
+// @Success 201 {object} entities.User
+// @Security Bearer
+// @Router /user [post]
+func (h *UserHandler) Create(c *gin.Context) {
+	var request request.UserRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.Create(c.Request.Context(), &request)
+	if errors.Is(err, services.ErrUnknownRole) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusCreated, "User created successfully", user)
+}
+
+// GetAll
+// @Description Search the users by username or email, role, status and creation date
+// @Tags Users
+// @Param query query request.UsersRequestQuery false "Query parameters"
+// @Produce json
+// @Success 200 {object} response.Response{data=response.UsersResponse}
+// @Security Bearer
+// @Router /user [get]
+func (h *UserHandler) GetAll(c *gin.Context) {
+	var query request.UsersRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, total, err := h.svc.GetAll(query)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", response.UsersResponse{
+		TotalCount:  total,
+		CurrentPage: query.Page,
+		TotalPages:  (total + int64(query.Limit) - 1) / int64(query.Limit),
+		Users:       users,
+	})
+}
+
+// Export
+// @Description Export every user matching the search as CSV, ignoring the page
+// @Tags Users
+// @Param query query request.UsersRequestQuery false "Query parameters"
+// @Produce text/csv
+// @Success 200 {file} file
+// @Security Bearer
+// @Router /user/export [get]
+func (h *UserHandler) Export(c *gin.Context) {
+	var query request.UsersRequestQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="users.csv"`)
+	err := h.svc.ExportCSV(query, c.Writer)
+	if err != nil && !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err != nil {
+		// The status was sent with the first rows, so the export just ends
+		log.Printf("Failed to export users: %v", err)
+		c.Abort()
+	}
+}
+
+// GetByID
+// @Description Get user by id
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} entities.User
+// @Security Bearer
+// @Router /user/{id} [get]
+func (h *UserHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	user, err := h.svc.GetByID(uint(id))
+	if err != nil {
+		response.ErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "successfully", user)
+}
+
+// Update
+// @Description Update a user
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Param UserUpdateRequest body request.UserUpdateRequest true "Update user request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} entities.User
+// @Security Bearer
+// @Router /user/{id} [put]
+func (h *UserHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var request request.UserUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.Update(c.Request.Context(), uint(id), &request)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "User updated successfully", user)
+}
+
+// Delete
+// @Description Update a user
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]string "Example: {\"message\": \"User deleted successfully\"}"
+// @Security Bearer
+// @Router /user/{id} [delete]
+func (h *UserHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.Delete(c.Request.Context(), uint(id)); err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	response.SuccessResponse(c, http.StatusOK, "User deleted successfully", nil)
+}
+
+// Forgot Password
+// @Description Forgot Password
+// @Tags Users
+// @Param UserEmailRequest body request.UserEmailRequest true "Forgot password Request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]string "Example: {\"message\": \"If the email is registered, a password reset email has been sent.\"}"
+// @Router /user/forgot-password [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var request request.UserEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, err := h.svc.GenerateResetToken(request.Email)
+	var rateLimitErr *services.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		response.TooManyRequestsResponse(c, rateLimitErr.RetryAfter)
+		return
+	}
+	// Answer as if the email was sent, so the response does not tell which emails are registered
+	if errors.Is(err, services.ErrEmailNotRegistered) {
+		response.SuccessResponse(c, http.StatusOK, forgotPasswordMessage, nil)
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Send the email off the request path, so that registered emails are not
+	// told apart by how long the response takes
+	go sendResetEmail(request.Email, token)
+
+	response.SuccessResponse(c, http.StatusOK, forgotPasswordMessage, nil)
+}
+
+const forgotPasswordMessage = "If the email is registered, a password reset email has been sent."
+
+// sendResetEmail emails the password reset link with token. The user can ask
+// for another email if it fails, so the failure is only logged.
+func sendResetEmail(email string, token string) {
+	resetLink := config.SMTP.RedirectUrl + fmt.Sprintf("/reset-password?token=%s", token)
+	err := services.NewEmailService().SendTemplateEmail(email, "Password Reset", "email_template.html", map[string]string{"ResetLink": resetLink})
+	if err != nil {
+		log.Printf("failed to send password reset email: %v", err)
+	}
+}
+
+// Reset Password
+// @Description Reset Password
+// @Tags Users
+// @Query token path string true "token"
+// @Param UserResetPasswordRequest body request.UserResetPasswordRequest true "Reset password Request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} map[string]string "Example: {\"message\": \"Password reset successful.\"}"
+// @Router /user/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		response.ErrorResponse(c, http.StatusBadRequest, "Token is required")
+		return
+	}
+	var request request.UserResetPasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err := h.svc.ResetPassword(token, request.NewPassword)
+	if errors.Is(err, services.ErrInvalidResetToken) || errors.Is(err, services.ErrResetTokenExpired) || errors.Is(err, services.ErrResetTokenUsed) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Password reset successful.", nil)
+}
+
+// UnlockAccount
+// @Description Lift the login lockout of a user
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Produce json
+// @Success 200 {object} response.Response
+// @Security Bearer
+// @Router /user/{id}/unlock [post]
+func (h *UserHandler) UnlockAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err := h.svc.UnlockAccount(uint(id)); err != nil {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Account unlocked successfully", nil)
+}
+
+// AssignRole
+// @Description Assign a role to a user. The user is logged out so that the new role applies.
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Param AssignRoleRequest body request.AssignRoleRequest true "Assign role request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.User}
+// @Security Bearer
+// @Router /user/{id}/role [put]
+func (h *UserHandler) AssignRole(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var request request.AssignRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.AssignRole(c.Request.Context(), c.GetUint(middlewares.UserIDKey), uint(id), request.Role)
+	if errors.Is(err, services.ErrUnknownRole) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrChangeOwnRole) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Role assigned successfully", user)
+}
+
+// SetStatus
+// @Description Suspend a user until a date, ban them, or lift either by setting them active. Suspended and banned users are logged out and cannot log in.
+// @Tags Users
+// @Param id path uint true "User ID"
+// @Param UserStatusRequest body request.UserStatusRequest true "User status request"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=entities.User}
+// @Security Bearer
+// @Router /user/{id}/status [put]
+func (h *UserHandler) SetStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var request request.UserStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.SetStatus(c.Request.Context(), c.GetUint(middlewares.UserIDKey), uint(id), &request)
+	if errors.Is(err, services.ErrSuspensionEnded) {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, services.ErrChangeOwnStatus) {
+		response.ErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Status updated successfully", user)
+}
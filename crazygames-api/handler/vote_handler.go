@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"crazygames.io/config"
+	"crazygames.io/handler/request"
+	"crazygames.io/handler/response"
+	"crazygames.io/middlewares"
+	"crazygames.io/services"
+	"crazygames.io/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// deviceCookie identifies the browser of an anonymous player, so that they
+// can only vote once on each game.
+const deviceCookie = "device_id"
+
+type VoteHandler struct {
+	svc services.VoteServiceInterface
+}
+
+func NewVoteHandler(svc services.VoteServiceInterface) *VoteHandler {
+	return &VoteHandler{svc: svc}
+}
+
+// Vote
+// @Description Vote a game up or down, or clear the vote, as the current user or anonymously once per device
+// @Tags Games
+// @Param id path uint true "Game ID"
+// @Param request body request.VoteRequest true "Vote"
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=services.VoteResult}
+// @Failure 404 {object} response.Response
+// @Security Bearer
+// @Router /game/{id}/vote [post]
+func (h *VoteHandler) Vote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	var req request.VoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID := middlewares.OptionalUserID(c)
+	var deviceID string
+	if userID == nil {
+		if deviceID, err = h.deviceID(c); err != nil {
+			response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	result, err := h.svc.Vote(userID, deviceID, uint(id), req.Vote)
+	if errors.Is(err, services.ErrGameNotFound) {
+		response.ErrorResponse(c, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.SuccessResponse(c, http.StatusOK, "Vote recorded", result)
+}
+
+// deviceID returns the ID in the device cookie, setting a new one if the
+// browser has none yet.
+func (h *VoteHandler) deviceID(c *gin.Context) (string, error) {
+	deviceID, _ := c.Cookie(deviceCookie)
+	if decoded, err := hex.DecodeString(deviceID); err == nil && len(decoded) == 16 {
+		return deviceID, nil
+	}
+
+	deviceID, err := utils.RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(deviceCookie, deviceID, int(services.DeviceCookieTTL.Seconds()), "/api/game", "", config.AppConfig.CookieSecure, true)
+	return deviceID, nil
+}
@@ -1 +1 @@
-package main
+package main
@@ -1,64 +1,127 @@
-package main
-
-import (
-	"log"
-
-	"crazygames.io/config"
-	"crazygames.io/handler"
-	"crazygames.io/repositories"
-	routes "crazygames.io/route"
-	"crazygames.io/services"
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-)
-
-func main() {
-	config.LoadConfig()
-
-	db := config.ConnectDatabase()
-	redisClient := config.ConnectRedis()
-	defer redisClient.Close()
-	minioClient := config.ConnectMinIO()
-	minioService := services.NewMinIOService(minioClient)
-
-	r := gin.Default()
-
-	r.Use(gin.Logger(), gin.Recovery())
-
-	corsConf := cors.DefaultConfig()
-	corsConf.AllowHeaders = append(corsConf.AllowHeaders, "Authorization")
-	corsConf.AllowOrigins = config.AppConfig.ALLOW_ORIGINS
-	r.Use(cors.New(corsConf))
-
-	categoryRepo := repositories.NewCategoryRepository(db)
-	categoryService := services.NewCategoryService(categoryRepo, minioService)
-	categoryHandler := handler.NewCategoryHandler(categoryService)
-
-	userRepo := repositories.NewUserRepository(db)
-	passwordResetTokenRepo := repositories.NewPasswordResetTokenRepository(db)
-	userService := services.NewUserService(userRepo, passwordResetTokenRepo)
-	userHandler := handler.NewUserHandler(userService)
-
-	adsRepo := repositories.NewAdsRepository(db)
-	adsService := services.NewAdsService(adsRepo, minioClient)
-	adsHandler := handler.NewAdsHandler(adsService)
-
-	OAuthService := services.NewOAuthService(userRepo)
-	OAuthHandler := handler.NewOAuthHandler(OAuthService)
-
-	gameRepo := repositories.NewGameRepository(db)
-	gameService := services.NewGameService(gameRepo, minioClient)
-	gameHandler := handler.NewGameHandler(gameService)
-
-	authService := services.NewAuthService(userRepo)
-	authHandler := handler.NewAuthHandler(authService, userService)
-
-	router := routes.NewRouter(categoryHandler, userHandler, adsHandler, gameHandler, OAuthHandler, authHandler)
-
-	router.RegisterRoutes(r)
-
-	log.Println("Server is running on :8080")
-	if err := r.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"log"
+
+	"crazygames.io/config"
+	"crazygames.io/handler"
+	"crazygames.io/middlewares"
+	"crazygames.io/repositories"
+	routes "crazygames.io/route"
+	"crazygames.io/services"
+	"crazygames.io/utils"
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// @securityDefinitions.apikey Bearer
+// @in header
+// @name Authorization
+func main() {
+	config.LoadConfig()
+
+	db := config.ConnectDatabase()
+	redisClient := config.ConnectRedis()
+	defer redisClient.Close()
+	minioClient := config.ConnectMinIO()
+	minioService := services.NewMinIOService(minioClient)
+
+	r := gin.Default()
+
+	r.Use(gin.Logger(), gin.Recovery(), middlewares.RequestID())
+
+	corsConf := cors.DefaultConfig()
+	corsConf.AllowHeaders = append(corsConf.AllowHeaders, "Authorization", middlewares.APIKeyHeader, middlewares.RequestIDHeader)
+	corsConf.ExposeHeaders = append(corsConf.ExposeHeaders, middlewares.RequestIDHeader)
+	corsConf.AllowOrigins = config.AppConfig.ALLOW_ORIGINS
+	// The OAuth state cookie is set on a cross-origin XHR from the frontend.
+	corsConf.AllowCredentials = true
+	r.Use(cors.New(corsConf))
+
+	categoryRepo := repositories.NewCategoryRepository(db)
+	categoryService := services.NewCategoryService(categoryRepo, minioService)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+
+	rateLimitRepo := repositories.NewRateLimitRepository(redisClient)
+	rateLimitService := services.NewRateLimitService(rateLimitRepo)
+
+	sessionRepo := repositories.NewSessionRepository(redisClient)
+
+	roleRepo := repositories.NewRoleRepository(db)
+	roleService := services.NewRoleService(roleRepo)
+	roleHandler := handler.NewRoleHandler(roleService)
+
+	userRepo := repositories.NewUserRepository(db)
+	passwordResetTokenRepo := repositories.NewPasswordResetTokenRepository(db)
+	userService := services.NewUserService(userRepo, passwordResetTokenRepo, sessionRepo, roleRepo, rateLimitService)
+	userHandler := handler.NewUserHandler(userService)
+	go utils.RunEvery(context.Background(), "Purging expired password reset tokens", config.AppConfig.PasswordResetPurgeInterval, userService.PurgeExpiredResetTokens)
+	go utils.RunEvery(context.Background(), "Lifting expired suspensions", config.AppConfig.SuspensionCheckInterval, userService.LiftExpiredSuspensions)
+
+	adsRepo := repositories.NewAdsRepository(db)
+	adsService := services.NewAdsService(adsRepo, minioClient)
+	adsHandler := handler.NewAdsHandler(adsService)
+
+	gameRepo := repositories.NewGameRepository(db)
+	playHistoryRepo := repositories.NewPlayHistoryRepository(db)
+	favoriteRepo := repositories.NewFavoriteRepository(db)
+	gameService := services.NewGameService(gameRepo, minioClient, favoriteRepo)
+	gameHandler := handler.NewGameHandler(gameService)
+	favoriteHandler := handler.NewFavoriteHandler(services.NewFavoriteService(favoriteRepo, gameRepo))
+	tagHandler := handler.NewTagHandler(services.NewTagService(repositories.NewTagRepository(db), gameRepo, gameService))
+
+	playCountRepo := repositories.NewPlayCountRepository(redisClient)
+	rankingService := services.NewRankingService(gameRepo, playHistoryRepo, repositories.NewRankingRepository(redisClient), favoriteRepo)
+	rankingHandler := handler.NewRankingHandler(rankingService)
+	playService := services.NewPlayService(gameRepo, playHistoryRepo, playCountRepo, rankingService)
+	voteHandler := handler.NewVoteHandler(services.NewVoteService(gameRepo, repositories.NewGameVoteRepository(db), repositories.NewDeviceVoteRepository(redisClient)))
+	reviewHandler := handler.NewReviewHandler(services.NewReviewService(repositories.NewReviewRepository(db), gameRepo))
+	playHandler := handler.NewPlayHandler(playService)
+	go utils.RunEvery(context.Background(), "Flushing play counts", config.AppConfig.PlayCountFlushInterval, playService.FlushPlayCounts)
+	go utils.RunEvery(context.Background(), "Ending stale play sessions", config.AppConfig.PlaySessionCleanupInterval, playService.EndStaleSessions)
+
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	mfaService := services.NewMFAService(userRepo, recoveryCodeRepo, rateLimitService)
+
+	emailVerificationRepo := repositories.NewEmailVerificationRepository(redisClient)
+	authService := services.NewAuthService(userRepo, sessionRepo, emailVerificationRepo, services.NewEmailService(), rateLimitService, mfaService)
+	authHandler := handler.NewAuthHandler(authService, mfaService)
+
+	oauthRepo := repositories.NewOAuthRepository(redisClient)
+	userIdentityRepo := repositories.NewUserIdentityRepository(db)
+	oauthProviders := services.NewOAuthProviders(config.OauthProviders)
+	OAuthService := services.NewOAuthService(userRepo, userIdentityRepo, oauthRepo, authService, oauthProviders)
+	OAuthHandler := handler.NewOAuthHandler(OAuthService)
+
+	apiKeyRepo := repositories.NewAPIKeyRepository(db)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, roleRepo)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+
+	auditRepo := repositories.NewAuditRepository(db)
+	auditService := services.NewAuditService(auditRepo)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	dataRequestRepo := repositories.NewDataRequestRepository(db)
+	dataRequestService := services.NewDataRequestService(dataRequestRepo, userRepo, sessionRepo, minioService)
+	dataRequestHandler := handler.NewDataRequestHandler(dataRequestService)
+	go utils.RunEvery(context.Background(), "Processing personal data requests", config.AppConfig.DataRequestPollInterval, dataRequestService.ProcessPending)
+	go utils.RunEvery(context.Background(), "Purging expired data exports", config.AppConfig.DataExportPurgeInterval, dataRequestService.PurgeExpiredExports)
+
+	accountService := services.NewAccountService(userRepo, sessionRepo, minioService, authService, rateLimitService, dataRequestService)
+	accountHandler := handler.NewAccountHandler(accountService)
+
+	authMiddleware := middlewares.AuthMiddleware(authService)
+	optionalAuthMiddleware := middlewares.OptionalAuthMiddleware(authService)
+	apiKeyMiddleware := middlewares.AuthOrAPIKeyMiddleware(authService, apiKeyService)
+	enrollmentMiddleware := middlewares.AuthMiddleware(middlewares.TokenValidatorFunc(authService.ValidateEnrollmentToken))
+
+	router := routes.NewRouter(categoryHandler, userHandler, adsHandler, gameHandler, OAuthHandler, authHandler, roleHandler, apiKeyHandler, auditHandler, accountHandler, dataRequestHandler, playHandler, rankingHandler, reviewHandler, voteHandler, favoriteHandler, tagHandler, authMiddleware, optionalAuthMiddleware, enrollmentMiddleware, apiKeyMiddleware, rateLimitRepo, roleService)
+
+	router.RegisterRoutes(r)
+
+	log.Println("Server is running on :8080")
+	if err := r.Run(":8080"); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
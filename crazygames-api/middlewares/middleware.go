@@ -1,37 +1,257 @@
-package middlewares
-
-import (
-	"log"
-	"time"
-
-	"github.com/gin-gonic/gin"
-)
-
-func LoggerMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		startTime := time.Now()
-
-		c.Next()
-
-		duration := time.Since(startTime)
-		log.Printf("[%s] %s %s %d %s",
-			c.Request.Method,
-			c.Request.RequestURI,
-			c.ClientIP(),
-			c.Writer.Status(),
-			duration,
-		)
-	}
-}
-
-func AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		token := c.GetHeader("Authorization")
-		if token != "Bearer mysecrettoken" {
-			c.JSON(401, gin.H{"error": "Unauthorized"})
-			c.Abort()
-			return
-		}
-		c.Next()
-	}
-}
+package middlewares
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/response"
+	"crazygames.io/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Keys under which AuthMiddleware stores the authenticated user in the gin context.
+const (
+	UserIDKey    = "user_id"
+	RoleKey      = "role"
+	SessionIDKey = "session_id"
+	// ScopesKey is only set for requests made with an API key.
+	ScopesKey = "scopes"
+	// RequestIDKey is set by RequestID on every request.
+	RequestIDKey = "request_id"
+)
+
+// APIKeyHeader carries the API key of an integration instead of a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// RequestIDHeader carries the ID of a request, from the client or a proxy in
+// front of the API, and back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 64
+
+type TokenValidator interface {
+	ValidateToken(tokenString string) (*utils.Claims, error)
+}
+
+// TokenValidatorFunc lets a function be used as a TokenValidator.
+type TokenValidatorFunc func(tokenString string) (*utils.Claims, error)
+
+func (f TokenValidatorFunc) ValidateToken(tokenString string) (*utils.Claims, error) {
+	return f(tokenString)
+}
+
+type APIKeyValidator interface {
+	ValidateAPIKey(key string) (*entities.APIKey, error)
+}
+
+type PermissionChecker interface {
+	HasPermission(role string, permission string) (bool, error)
+}
+
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+}
+
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+
+		c.Next()
+
+		duration := time.Since(startTime)
+		log.Printf("[%s] %s %s %d %s",
+			c.Request.Method,
+			c.Request.RequestURI,
+			c.ClientIP(),
+			c.Writer.Status(),
+			duration,
+		)
+	}
+}
+
+// RequestID keeps the request ID the request came with, or gives it a new one.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			var err error
+			requestID, err = utils.RandomToken(16)
+			if err != nil {
+				response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+				c.Abort()
+				return
+			}
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range requestID {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// AuditActor makes the changes of the request recorded in the audit log, on
+// behalf of the authenticated user. It must run after AuthMiddleware or
+// AuthOrAPIKeyMiddleware.
+func AuditActor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := utils.WithAuditActor(c.Request.Context(), utils.AuditActor{
+			UserID:    c.GetUint(UserIDKey),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString(RequestIDKey),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+func AuthMiddleware(validator TokenValidator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found || tokenString == "" {
+			response.ErrorResponse(c, http.StatusUnauthorized, "Missing bearer token")
+			c.Abort()
+			return
+		}
+
+		claims, err := validator.ValidateToken(tokenString)
+		if err != nil {
+			response.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token")
+			c.Abort()
+			return
+		}
+
+		c.Set(UserIDKey, claims.UserID)
+		c.Set(RoleKey, claims.Role)
+		c.Set(SessionIDKey, claims.SessionID)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware is AuthMiddleware for routes that anonymous users may
+// call too: requests without a bearer token go through without a user.
+func OptionalAuthMiddleware(validator TokenValidator) gin.HandlerFunc {
+	authMiddleware := AuthMiddleware(validator)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authMiddleware(c)
+	}
+}
+
+// OptionalUserID returns the ID of the user after OptionalAuthMiddleware, or
+// nil for anonymous users.
+func OptionalUserID(c *gin.Context) *uint {
+	value, ok := c.Get(UserIDKey)
+	if !ok {
+		return nil
+	}
+	userID := value.(uint)
+	return &userID
+}
+
+// AuthOrAPIKeyMiddleware is AuthMiddleware that also accepts an API key. Such
+// requests are limited to the key's scopes, which only RequirePermission
+// checks, so it must be followed by RequirePermission.
+func AuthOrAPIKeyMiddleware(validator TokenValidator, apiKeys APIKeyValidator) gin.HandlerFunc {
+	authMiddleware := AuthMiddleware(validator)
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			authMiddleware(c)
+			return
+		}
+
+		apiKey, err := apiKeys.ValidateAPIKey(key)
+		if err != nil {
+			response.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired API key")
+			c.Abort()
+			return
+		}
+
+		c.Set(UserIDKey, apiKey.UserID)
+		c.Set(RoleKey, apiKey.User.Role)
+		c.Set(ScopesKey, apiKey.Scopes)
+		c.Next()
+	}
+}
+
+// RequireRole must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString(RoleKey)
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		response.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions")
+		c.Abort()
+	}
+}
+
+// RequirePermission must run after AuthMiddleware or AuthOrAPIKeyMiddleware.
+func RequirePermission(checker PermissionChecker, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, err := checker.HasPermission(c.GetString(RoleKey), permission)
+		if err != nil {
+			response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.ErrorResponse(c, http.StatusForbidden, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+
+		if scopes, ok := c.Get(ScopesKey); ok && !slices.Contains(scopes.([]string), permission) {
+			response.ErrorResponse(c, http.StatusForbidden, "API key is missing the "+permission+" scope")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimit allows each client IP limit requests per window to the routes it
+// guards; name keeps the count of each group of routes apart.
+func RateLimit(limiter RateLimiter, name string, limit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter, err := limiter.Allow(c.Request.Context(), name+":ip:"+c.ClientIP(), limit, window)
+		if err != nil {
+			response.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+			c.Abort()
+			return
+		}
+		if !allowed {
+			response.TooManyRequestsResponse(c, retryAfter)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
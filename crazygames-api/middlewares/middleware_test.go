@@ -0,0 +1,336 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "test-secret"
+
+type secretValidator string
+
+func (s secretValidator) ValidateToken(tokenString string) (*utils.Claims, error) {
+	return utils.ParseToken(string(s), tokenString)
+}
+
+func newAdminRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.DELETE("/game/:id", AuthMiddleware(secretValidator(testSecret)), RequireRole(entities.RoleAdmin), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint(UserIDKey)})
+	})
+	return r
+}
+
+func doRequest(r *gin.Engine, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/game/1", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func Test_AuthMiddleware(t *testing.T) {
+	r := newAdminRouter()
+
+	t.Run("valid admin token should succeed", func(t *testing.T) {
+		token, err := utils.GenerateToken(testSecret, 1, entities.RoleAdmin, "session", time.Hour)
+		assert.NoError(t, err, "failed to generate token")
+
+		w := doRequest(r, "Bearer "+token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":1}`, w.Body.String())
+	})
+
+	t.Run("missing token should fail", func(t *testing.T) {
+		w := doRequest(r, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("token without bearer scheme should fail", func(t *testing.T) {
+		token, _ := utils.GenerateToken(testSecret, 1, entities.RoleAdmin, "session", time.Hour)
+
+		w := doRequest(r, token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("expired token should fail", func(t *testing.T) {
+		token, _ := utils.GenerateToken(testSecret, 1, entities.RoleAdmin, "session", -time.Minute)
+
+		w := doRequest(r, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("token signed with another secret should fail", func(t *testing.T) {
+		token, _ := utils.GenerateToken("another-secret", 1, entities.RoleAdmin, "session", time.Hour)
+
+		w := doRequest(r, "Bearer "+token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("tampered claims should fail", func(t *testing.T) {
+		playerToken, _ := utils.GenerateToken(testSecret, 1, entities.RolePlayer, "session", time.Hour)
+		adminToken, _ := utils.GenerateToken(testSecret, 1, entities.RoleAdmin, "session", time.Hour)
+
+		// Keep the player's signature but swap in the admin payload.
+		player := strings.Split(playerToken, ".")
+		admin := strings.Split(adminToken, ".")
+		w := doRequest(r, "Bearer "+player[0]+"."+admin[1]+"."+player[2])
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("unsigned token should fail", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, utils.Claims{
+			UserID: 1,
+			Role:   entities.RoleAdmin,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		})
+		tokenString, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		assert.NoError(t, err, "failed to generate token")
+
+		w := doRequest(r, "Bearer "+tokenString)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("player token on admin route should be forbidden", func(t *testing.T) {
+		token, _ := utils.GenerateToken(testSecret, 2, entities.RolePlayer, "session", time.Hour)
+
+		w := doRequest(r, "Bearer "+token)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+// rolePermissions grants each role its listed permissions.
+type rolePermissions map[string][]string
+
+func (p rolePermissions) HasPermission(role string, permission string) (bool, error) {
+	for _, granted := range p[role] {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func Test_OptionalAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/game/:id/play", OptionalAuthMiddleware(secretValidator(testSecret)), func(c *gin.Context) {
+		_, loggedIn := c.Get(UserIDKey)
+		c.JSON(http.StatusOK, gin.H{"logged_in": loggedIn, "user_id": c.GetUint(UserIDKey)})
+	})
+	play := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/game/1/play", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("anonymous request should go through without a user", func(t *testing.T) {
+		w := play("")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"logged_in":false,"user_id":0}`, w.Body.String())
+	})
+
+	t.Run("valid token should set the user", func(t *testing.T) {
+		token, _ := utils.GenerateToken(testSecret, 7, entities.RolePlayer, "session", time.Hour)
+
+		w := play("Bearer " + token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"logged_in":true,"user_id":7}`, w.Body.String())
+	})
+
+	t.Run("invalid token should fail rather than play anonymously", func(t *testing.T) {
+		token, _ := utils.GenerateToken(testSecret, 7, entities.RolePlayer, "session", -time.Minute)
+
+		w := play("Bearer " + token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func Test_RequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	checker := rolePermissions{entities.RoleEditor: {entities.PermissionGameWrite}}
+	r.DELETE("/game/:id", AuthMiddleware(secretValidator(testSecret)), RequirePermission(checker, entities.PermissionGameWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	r.DELETE("/ads/:id", AuthMiddleware(secretValidator(testSecret)), RequirePermission(checker, entities.PermissionAdsWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	editorToken, _ := utils.GenerateToken(testSecret, 1, entities.RoleEditor, "session", time.Hour)
+	request := func(path string) int {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer "+editorToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("role with the permission should succeed", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request("/game/1"))
+	})
+
+	t.Run("role without the permission should be forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request("/ads/1"))
+	})
+}
+
+// staticAPIKeys accepts the keys it holds.
+type staticAPIKeys map[string]*entities.APIKey
+
+func (k staticAPIKeys) ValidateAPIKey(key string) (*entities.APIKey, error) {
+	if apiKey, ok := k[key]; ok {
+		return apiKey, nil
+	}
+	return nil, errors.New("invalid API key")
+}
+
+func Test_AuthOrAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	checker := rolePermissions{entities.RoleEditor: {entities.PermissionGameWrite, entities.PermissionCategoryWrite}}
+	apiKeys := staticAPIKeys{"cg_gamekey": {UserID: 7, User: &entities.User{Role: entities.RoleEditor}, Scopes: []string{entities.PermissionGameWrite}}}
+	authMiddleware := AuthOrAPIKeyMiddleware(secretValidator(testSecret), apiKeys)
+	r.POST("/game", authMiddleware, RequirePermission(checker, entities.PermissionGameWrite), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetUint(UserIDKey)})
+	})
+	r.POST("/category", authMiddleware, RequirePermission(checker, entities.PermissionCategoryWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(path string, header string, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(header, value)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("key with the scope should act as its user", func(t *testing.T) {
+		w := request("/game", APIKeyHeader, "cg_gamekey")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id":7}`, w.Body.String())
+	})
+
+	t.Run("key without the scope should be forbidden even if the role has it", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, request("/category", APIKeyHeader, "cg_gamekey").Code)
+	})
+
+	t.Run("unknown key should fail", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request("/game", APIKeyHeader, "cg_unknown").Code)
+	})
+
+	t.Run("bearer token should still be accepted", func(t *testing.T) {
+		token, _ := utils.GenerateToken(testSecret, 1, entities.RoleEditor, "session", time.Hour)
+		assert.Equal(t, http.StatusOK, request("/category", "Authorization", "Bearer "+token).Code)
+	})
+}
+
+// countingLimiter allows limit requests per key and never forgets them.
+type countingLimiter struct {
+	counts map[string]int
+}
+
+func (l *countingLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if l.counts[key] >= limit {
+		return false, window, nil
+	}
+	l.counts[key]++
+	return true, 0, nil
+}
+
+func Test_RateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/auth/login", RateLimit(&countingLimiter{counts: map[string]int{}}, "login", 2, time.Minute), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	login := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("requests within the limit should succeed", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, login("10.0.0.1").Code)
+		assert.Equal(t, http.StatusOK, login("10.0.0.1").Code)
+	})
+
+	t.Run("request over the limit should be refused with retry after", func(t *testing.T) {
+		w := login("10.0.0.1")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	})
+
+	t.Run("other ip should not be limited", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, login("10.0.0.2").Code)
+	})
+}
+
+func Test_AuditActor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID())
+	r.DELETE("/game/:id", AuthMiddleware(secretValidator(testSecret)), AuditActor(), func(c *gin.Context) {
+		actor, ok := utils.AuditActorFrom(c.Request.Context())
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"user_id": actor.UserID, "ip": actor.IP, "request_id": actor.RequestID})
+	})
+
+	token, err := utils.GenerateToken(testSecret, 1, entities.RoleAdmin, "session", time.Hour)
+	assert.NoError(t, err, "failed to generate token")
+
+	deleteGame := func(requestID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/game/1", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Authorization", "Bearer "+token)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("request id from the client should be kept", func(t *testing.T) {
+		w := deleteGame("req-42")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "req-42", w.Header().Get(RequestIDHeader))
+		assert.JSONEq(t, `{"user_id":1,"ip":"10.0.0.1","request_id":"req-42"}`, w.Body.String())
+	})
+
+	t.Run("missing or invalid request id should be replaced", func(t *testing.T) {
+		w := deleteGame("")
+		assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+
+		w = deleteGame("bad id\n")
+		assert.Len(t, w.Header().Get(RequestIDHeader), 32)
+		assert.NotContains(t, w.Body.String(), "bad id")
+	})
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+type APIKeyRepositoryInterface interface {
+	Create(key *entities.APIKey) error
+	GetByHash(keyHash string) (*entities.APIKey, error)
+	GetByUserID(userID uint) ([]entities.APIKey, error)
+	Revoke(userID uint, id uint) error
+	TouchLastUsed(id uint, usedAt time.Time) error
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(key *entities.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+// GetByHash returns the key with its user.
+func (r *APIKeyRepository) GetByHash(keyHash string) (*entities.APIKey, error) {
+	var key entities.APIKey
+	err := r.db.Preload("User").Where("key_hash = ?", keyHash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) GetByUserID(userID uint) ([]entities.APIKey, error) {
+	var keys []entities.APIKey
+	err := r.db.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// Revoke revokes the key id of userID, returning gorm.ErrRecordNotFound if the
+// user has no such key that is still active.
+func (r *APIKeyRepository) Revoke(userID uint, id uint) error {
+	result := r.db.Model(&entities.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(id uint, usedAt time.Time) error {
+	return r.db.Model(&entities.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumn("last_used_at", usedAt).
+		Error
+}
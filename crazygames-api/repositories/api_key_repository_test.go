@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func Test_APIKeys(t *testing.T) {
+	db.Exec("DELETE FROM api_keys")
+	db.Exec("DELETE FROM users")
+
+	user, err := createUser("loader", "loader@gmail.com", "password", entities.RoleEditor)
+	assert.NoError(t, err, "failed to create user for test")
+
+	key := &entities.APIKey{UserID: user.ID, Name: "dataloader", Prefix: "cg_12345678", KeyHash: "keyhash", Scopes: []string{entities.PermissionGameWrite}}
+	assert.NoError(t, apiKeyRepository.Create(key), "failed to create API key")
+
+	t.Run("get by hash should load the scopes and the user", func(t *testing.T) {
+		found, err := apiKeyRepository.GetByHash("keyhash")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{entities.PermissionGameWrite}, found.Scopes)
+		assert.Equal(t, entities.RoleEditor, found.User.Role)
+	})
+
+	t.Run("touch last used should record the time", func(t *testing.T) {
+		usedAt := time.Now().Truncate(time.Second)
+		assert.NoError(t, apiKeyRepository.TouchLastUsed(key.ID, usedAt))
+
+		found, _ := apiKeyRepository.GetByHash("keyhash")
+		assert.Equal(t, usedAt.Unix(), found.LastUsedAt.Unix())
+	})
+
+	t.Run("revoke key of another user should fail", func(t *testing.T) {
+		err := apiKeyRepository.Revoke(user.ID+1, key.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("revoke should only succeed once", func(t *testing.T) {
+		assert.NoError(t, apiKeyRepository.Revoke(user.ID, key.ID))
+		assert.ErrorIs(t, apiKeyRepository.Revoke(user.ID, key.ID), gorm.ErrRecordNotFound)
+
+		keys, err := apiKeyRepository.GetByUserID(user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, keys, 1)
+		assert.NotNil(t, keys[0].RevokedAt)
+	})
+}
@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/utils"
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	db *gorm.DB
+}
+
+type AuditRepositoryInterface interface {
+	GetAll(query request.AuditRequestQuery) ([]entities.AuditEvent, int64, error)
+}
+
+func NewAuditRepository(db *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) GetAll(queryParams request.AuditRequestQuery) ([]entities.AuditEvent, int64, error) {
+	var events []entities.AuditEvent
+	var total int64
+
+	query := r.db.Model(&entities.AuditEvent{})
+	if queryParams.ActorID != 0 {
+		query = query.Where("actor_id = ?", queryParams.ActorID)
+	}
+	if queryParams.Action != "" {
+		query = query.Where("action = ?", queryParams.Action)
+	}
+	if queryParams.EntityType != "" {
+		query = query.Where("entity_type = ?", queryParams.EntityType)
+	}
+	if queryParams.EntityID != 0 {
+		query = query.Where("entity_id = ?", queryParams.EntityID)
+	}
+	if queryParams.RequestID != "" {
+		query = query.Where("request_id = ?", queryParams.RequestID)
+	}
+	if queryParams.From != nil {
+		query = query.Where("created_at >= ?", queryParams.From)
+	}
+	if queryParams.To != nil {
+		query = query.Where("created_at < ?", queryParams.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id DESC").
+		Offset(queryParams.PageSize * (queryParams.PageNumber - 1)).
+		Limit(queryParams.PageSize).Find(&events).Error
+
+	return events, total, err
+}
+
+// auditIgnoredFields change on every write and would only clutter the diff.
+var auditIgnoredFields = []string{"CreatedAt", "UpdatedAt", "created_at", "updated_at"}
+
+// recordAudit writes the audit event of a change made in tx, so that it is
+// committed or rolled back with the change. Changes whose ctx has no actor are
+// not administrative and are not recorded. before is nil on create and after
+// on delete.
+func recordAudit(ctx context.Context, tx *gorm.DB, action string, entityType string, entityID uint, before interface{}, after interface{}) error {
+	actor, ok := utils.AuditActorFrom(ctx)
+	if !ok {
+		return nil
+	}
+
+	beforeJSON, afterJSON, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&entities.AuditEvent{
+		ActorID:    actor.UserID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Before:     beforeJSON,
+		After:      afterJSON,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+	}).Error
+}
+
+// auditDiff returns the JSON of the fields of before and after that differ.
+// Fields hidden from JSON, like password hashes, are never recorded.
+func auditDiff(before interface{}, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if beforeFields != nil && afterFields != nil {
+		for name, value := range beforeFields {
+			if reflect.DeepEqual(value, afterFields[name]) {
+				delete(beforeFields, name)
+				delete(afterFields, name)
+			}
+		}
+	}
+
+	beforeJSON, err := marshalAuditFields(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	afterJSON, err := marshalAuditFields(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+	return beforeJSON, afterJSON, nil
+}
+
+func auditFields(entity interface{}) (map[string]interface{}, error) {
+	if entity == nil || reflect.ValueOf(entity).IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range auditIgnoredFields {
+		delete(fields, name)
+	}
+	return fields, nil
+}
+
+func marshalAuditFields(fields map[string]interface{}) (json.RawMessage, error) {
+	if fields == nil {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuditDiff(t *testing.T) {
+	before := &entities.User{ID: 1, Username: "player", Email: "player@example.com", Password: "old-hash", Role: entities.RolePlayer}
+	after := &entities.User{ID: 1, Username: "player", Email: "player@example.com", Password: "new-hash", Role: entities.RoleEditor}
+
+	t.Run("update should only keep the changed fields", func(t *testing.T) {
+		beforeJSON, afterJSON, err := auditDiff(before, after)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"role":"player"}`, string(beforeJSON))
+		assert.JSONEq(t, `{"role":"editor"}`, string(afterJSON))
+	})
+
+	t.Run("create should only have the after state", func(t *testing.T) {
+		beforeJSON, afterJSON, err := auditDiff(nil, after)
+		assert.NoError(t, err)
+		assert.Nil(t, beforeJSON)
+		assert.Contains(t, string(afterJSON), `"username":"player"`)
+		assert.NotContains(t, string(afterJSON), "new-hash", "hidden fields should not be recorded")
+	})
+
+	t.Run("delete should only have the before state", func(t *testing.T) {
+		var noUser *entities.User
+		beforeJSON, afterJSON, err := auditDiff(before, noUser)
+		assert.NoError(t, err)
+		assert.Contains(t, string(beforeJSON), `"email":"player@example.com"`)
+		assert.Nil(t, afterJSON)
+	})
+}
+
+func Test_AuditEvents(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0;")
+	db.Exec("TRUNCATE TABLE audit_events;")
+	db.Exec("TRUNCATE TABLE categories;")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1;")
+
+	ctx := utils.WithAuditActor(context.Background(), utils.AuditActor{UserID: 7, IP: "203.0.113.9", RequestID: "req-1"})
+
+	category := &entities.Category{CategoryName: "puzzle", Description: "Puzzle games"}
+	assert.NoError(t, categoryRepository.Create(ctx, category), "failed to create category for test")
+	_, err := categoryRepository.Update(ctx, &entities.Category{ID: category.ID, CategoryName: "puzzle", Description: "Brain teasers"})
+	assert.NoError(t, err, "failed to update category for test")
+
+	t.Run("changes without an actor should not be recorded", func(t *testing.T) {
+		other := &entities.Category{CategoryName: "racing"}
+		assert.NoError(t, categoryRepository.Create(context.Background(), other))
+
+		_, total, err := auditRepository.GetAll(request.AuditRequestQuery{PageNumber: 1, PageSize: 10, EntityID: other.ID, EntityType: entities.AuditEntityCategory})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), total)
+	})
+
+	t.Run("update should record the actor and the diff", func(t *testing.T) {
+		events, total, err := auditRepository.GetAll(request.AuditRequestQuery{PageNumber: 1, PageSize: 10, Action: entities.AuditActionUpdate})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, uint(7), events[0].ActorID)
+		assert.Equal(t, entities.AuditEntityCategory, events[0].EntityType)
+		assert.Equal(t, category.ID, events[0].EntityID)
+		assert.Equal(t, "203.0.113.9", events[0].IP)
+		assert.Equal(t, "req-1", events[0].RequestID)
+
+		var after map[string]interface{}
+		assert.NoError(t, json.Unmarshal(events[0].After, &after))
+		assert.Equal(t, map[string]interface{}{"Description": "Brain teasers"}, after)
+	})
+
+	t.Run("events should be filtered and newest first", func(t *testing.T) {
+		events, total, err := auditRepository.GetAll(request.AuditRequestQuery{PageNumber: 1, PageSize: 10, ActorID: 7, RequestID: "req-1"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, entities.AuditActionUpdate, events[0].Action)
+		assert.Equal(t, entities.AuditActionCreate, events[1].Action)
+
+		_, total, _ = auditRepository.GetAll(request.AuditRequestQuery{PageNumber: 1, PageSize: 10, ActorID: 8})
+		assert.Equal(t, int64(0), total)
+	})
+
+	t.Run("delete should record the deleted entity", func(t *testing.T) {
+		assert.NoError(t, categoryRepository.Delete(ctx, category.ID))
+
+		events, total, err := auditRepository.GetAll(request.AuditRequestQuery{PageNumber: 1, PageSize: 10, Action: entities.AuditActionDelete})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Contains(t, string(events[0].Before), `"CategoryName":"puzzle"`)
+		assert.Nil(t, events[0].After)
+	})
+}
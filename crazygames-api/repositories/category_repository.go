@@ -1,75 +1,102 @@
-package repositories
-
-import (
-	"time"
-
-	"crazygames.io/entities"
-	"gorm.io/gorm"
-)
-
-type CategoryRepository struct {
-	db *gorm.DB
-}
-
-type CategoryRepositoryInterface interface {
-	Create(category *entities.Category) error
-	GetAll() ([]entities.Category, error)
-	GetMenu() ([]entities.Category, error)
-	GetByID(id uint) (*entities.Category, error)
-	Update(category *entities.Category) (*entities.Category, error)
-	Delete(id uint) error
-}
-
-func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
-	return &CategoryRepository{db: db}
-}
-
-func (r *CategoryRepository) Create(category *entities.Category) error {
-	return r.db.Create(category).Error
-}
-
-func (r *CategoryRepository) GetAll() ([]entities.Category, error) {
-	var categories []entities.Category
-	err := r.db.Find(&categories).Error
-	return categories, err
-}
-func (r *CategoryRepository) GetMenu() ([]entities.Category, error) {
-	var categories []entities.Category
-	err := r.db.Where("is_menu = ?", true).Find(&categories).Error
-	return categories, err
-}
-
-func (r *CategoryRepository) GetByID(id uint) (*entities.Category, error) {
-	var category entities.Category
-	err := r.db.First(&category, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &category, nil
-}
-
-func (r *CategoryRepository) Update(category *entities.Category) (*entities.Category, error) {
-	err := r.db.Model(&entities.Category{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
-		"category_name": category.CategoryName,
-		"description":   category.Description,
-		"icon":          category.Icon,
-		"path":          category.Path,
-		"updated_at":    time.Now(),
-	}).Error
-	if err != nil {
-		return nil, err
-	}
-
-	// Fetch updated record
-	var updatedCategory entities.Category
-	err = r.db.First(&updatedCategory, category.ID).Error
-	if err != nil {
-		return nil, err
-	}
-
-	return &updatedCategory, nil
-}
-
-func (r *CategoryRepository) Delete(id uint) error {
-	return r.db.Delete(&entities.Category{}, id).Error
-}
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+type CategoryRepositoryInterface interface {
+	Create(ctx context.Context, category *entities.Category) error
+	GetAll() ([]entities.Category, error)
+	GetMenu() ([]entities.Category, error)
+	GetByID(id uint) (*entities.Category, error)
+	Update(ctx context.Context, category *entities.Category) (*entities.Category, error)
+	Delete(ctx context.Context, id uint) error
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func (r *CategoryRepository) Create(ctx context.Context, category *entities.Category) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(category).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionCreate, entities.AuditEntityCategory, category.ID, nil, category)
+	})
+}
+
+func (r *CategoryRepository) GetAll() ([]entities.Category, error) {
+	var categories []entities.Category
+	err := r.db.Find(&categories).Error
+	return categories, err
+}
+func (r *CategoryRepository) GetMenu() ([]entities.Category, error) {
+	var categories []entities.Category
+	err := r.db.Where("is_menu = ?", true).Find(&categories).Error
+	return categories, err
+}
+
+func (r *CategoryRepository) GetByID(id uint) (*entities.Category, error) {
+	var category entities.Category
+	err := r.db.First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+func (r *CategoryRepository) Update(ctx context.Context, category *entities.Category) (*entities.Category, error) {
+	var updatedCategory entities.Category
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingCategory entities.Category
+		if err := tx.First(&existingCategory, category.ID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&entities.Category{}).Where("id = ?", category.ID).Updates(map[string]interface{}{
+			"category_name": category.CategoryName,
+			"description":   category.Description,
+			"icon":          category.Icon,
+			"path":          category.Path,
+			"updated_at":    time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// Fetch updated record
+		if err := tx.First(&updatedCategory, category.ID).Error; err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, tx, entities.AuditActionUpdate, entities.AuditEntityCategory, category.ID, &existingCategory, &updatedCategory)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedCategory, nil
+}
+
+func (r *CategoryRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var category entities.Category
+		result := tx.Limit(1).Find(&category, id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Delete(&category).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionDelete, entities.AuditEntityCategory, id, &category, nil)
+	})
+}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+// PersonalData is everything stored about a user, as handed to them in an
+// export.
+type PersonalData struct {
+	Account     *entities.User
+	PlayHistory []entities.PlayHistory
+	Favorites   []entities.Favorite
+	Reviews     []entities.Review
+	Identities  []entities.UserIdentity
+}
+
+type DataRequestRepository struct {
+	db *gorm.DB
+}
+
+type DataRequestRepositoryInterface interface {
+	Create(request *entities.DataRequest) error
+	GetByID(userID uint, id uint) (*entities.DataRequest, error)
+	HasOpen(userID uint, requestType string) (bool, error)
+	ClaimNext() (*entities.DataRequest, error)
+	ReleaseStale(before time.Time) error
+	Update(request *entities.DataRequest) error
+	GetExportsWithFile(userID uint) ([]entities.DataRequest, error)
+	GetExpiredExports(before time.Time) ([]entities.DataRequest, error)
+	GetPersonalData(userID uint) (*PersonalData, error)
+}
+
+func NewDataRequestRepository(db *gorm.DB) *DataRequestRepository {
+	return &DataRequestRepository{db: db}
+}
+
+func (r *DataRequestRepository) Create(request *entities.DataRequest) error {
+	return r.db.Create(request).Error
+}
+
+// GetByID returns the request id if it belongs to the user.
+func (r *DataRequestRepository) GetByID(userID uint, id uint) (*entities.DataRequest, error) {
+	var request entities.DataRequest
+	if err := r.db.Where("user_id = ?", userID).First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+// HasOpen reports whether a request of the type is waiting or being carried
+// out for the user.
+func (r *DataRequestRepository) HasOpen(userID uint, requestType string) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.DataRequest{}).
+		Where("user_id = ? AND type = ? AND status IN ?", userID, requestType, []string{entities.DataRequestPending, entities.DataRequestProcessing}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ClaimNext marks the oldest pending request as processing and returns it, or
+// nil when there is none. A request is only claimed by one worker.
+func (r *DataRequestRepository) ClaimNext() (*entities.DataRequest, error) {
+	for {
+		var request entities.DataRequest
+		err := r.db.Where("status = ?", entities.DataRequestPending).Order("id").First(&request).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result := r.db.Model(&entities.DataRequest{}).
+			Where("id = ? AND status = ?", request.ID, entities.DataRequestPending).
+			Update("status", entities.DataRequestProcessing)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			request.Status = entities.DataRequestProcessing
+			return &request, nil
+		}
+		// Another worker claimed it first
+	}
+}
+
+// ReleaseStale puts back the requests whose worker stopped before finishing
+// them, i.e. that are still processing since before.
+func (r *DataRequestRepository) ReleaseStale(before time.Time) error {
+	return r.db.Model(&entities.DataRequest{}).
+		Where("status = ? AND updated_at < ?", entities.DataRequestProcessing, before).
+		Update("status", entities.DataRequestPending).Error
+}
+
+func (r *DataRequestRepository) Update(request *entities.DataRequest) error {
+	return r.db.Save(request).Error
+}
+
+// GetExportsWithFile returns the exports of the user whose file is still stored.
+func (r *DataRequestRepository) GetExportsWithFile(userID uint) ([]entities.DataRequest, error) {
+	var requests []entities.DataRequest
+	err := r.db.Where("user_id = ? AND type = ? AND object_path <> ''", userID, entities.DataRequestExport).Find(&requests).Error
+	return requests, err
+}
+
+// GetExpiredExports returns the exports whose file is still stored past their expiry.
+func (r *DataRequestRepository) GetExpiredExports(before time.Time) ([]entities.DataRequest, error) {
+	var requests []entities.DataRequest
+	err := r.db.Where("type = ? AND object_path <> '' AND expires_at < ?", entities.DataRequestExport, before).Find(&requests).Error
+	return requests, err
+}
+
+func (r *DataRequestRepository) GetPersonalData(userID uint) (*PersonalData, error) {
+	var account entities.User
+	if err := r.db.First(&account, userID).Error; err != nil {
+		return nil, err
+	}
+
+	data := PersonalData{Account: &account}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.PlayHistory).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Favorites).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Reviews).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&data.Identities).Error; err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DataRequests(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0;")
+	db.Exec("TRUNCATE TABLE data_requests;")
+	db.Exec("TRUNCATE TABLE favorites;")
+	db.Exec("TRUNCATE TABLE users;")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1;")
+
+	user := &entities.User{Username: "player", Email: "player@example.com", Password: "hashed"}
+	assert.NoError(t, userRepository.Create(context.Background(), user))
+
+	first := &entities.DataRequest{UserID: user.ID, Type: entities.DataRequestExport, Status: entities.DataRequestPending}
+	second := &entities.DataRequest{UserID: user.ID, Type: entities.DataRequestErasure, Status: entities.DataRequestPending}
+	assert.NoError(t, dataRequestRepository.Create(first))
+	assert.NoError(t, dataRequestRepository.Create(second))
+
+	t.Run("pending request should be open", func(t *testing.T) {
+		open, err := dataRequestRepository.HasOpen(user.ID, entities.DataRequestExport)
+		assert.NoError(t, err)
+		assert.True(t, open)
+	})
+
+	t.Run("claim should take the oldest pending request once", func(t *testing.T) {
+		claimed, err := dataRequestRepository.ClaimNext()
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, claimed.ID)
+		assert.Equal(t, entities.DataRequestProcessing, claimed.Status)
+
+		claimed, err = dataRequestRepository.ClaimNext()
+		assert.NoError(t, err)
+		assert.Equal(t, second.ID, claimed.ID)
+
+		claimed, err = dataRequestRepository.ClaimNext()
+		assert.NoError(t, err)
+		assert.Nil(t, claimed, "no request should be left")
+	})
+
+	t.Run("stale request should be claimed again", func(t *testing.T) {
+		assert.NoError(t, dataRequestRepository.ReleaseStale(time.Now().Add(time.Minute)))
+
+		claimed, err := dataRequestRepository.ClaimNext()
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, claimed.ID)
+	})
+
+	t.Run("expired export should be found", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		first.Status = entities.DataRequestCompleted
+		first.ObjectPath = "exports/1/export.zip"
+		first.ExpiresAt = &expiresAt
+		assert.NoError(t, dataRequestRepository.Update(first))
+
+		open, _ := dataRequestRepository.HasOpen(user.ID, entities.DataRequestExport)
+		assert.False(t, open)
+
+		expired, err := dataRequestRepository.GetExpiredExports(time.Now())
+		assert.NoError(t, err)
+		assert.Len(t, expired, 1)
+
+		withFile, err := dataRequestRepository.GetExportsWithFile(user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, withFile, 1)
+	})
+
+	t.Run("request of another user should not be found", func(t *testing.T) {
+		_, err := dataRequestRepository.GetByID(user.ID+1, first.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("personal data should hold the account and activity", func(t *testing.T) {
+		db.Create(&entities.Favorite{UserID: user.ID, GameID: 1})
+
+		data, err := dataRequestRepository.GetPersonalData(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, user.Email, data.Account.Email)
+		assert.Len(t, data.Favorites, 1)
+		assert.Empty(t, data.Reviews)
+	})
+}
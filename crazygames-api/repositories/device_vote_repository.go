@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DeviceVoteRepository keeps the votes of anonymous players on the games by
+// the device cookie of their browser:
+//
+//	game_votes:<game ID>:<device ID>  1 for up, -1 for down
+type DeviceVoteRepository struct {
+	rdb *redis.Client
+}
+
+type DeviceVoteRepositoryInterface interface {
+	Swap(ctx context.Context, gameID uint, deviceID string, value int, ttl time.Duration) (int, error)
+}
+
+func NewDeviceVoteRepository(rdb *redis.Client) *DeviceVoteRepository {
+	return &DeviceVoteRepository{rdb: rdb}
+}
+
+func deviceVoteKey(gameID uint, deviceID string) string {
+	return fmt.Sprintf("game_votes:%d:%s", gameID, deviceID)
+}
+
+// Swap replaces the vote of the device on the game, 0 clearing it, and
+// returns the previous one. The vote is forgotten after ttl.
+func (r *DeviceVoteRepository) Swap(ctx context.Context, gameID uint, deviceID string, value int, ttl time.Duration) (int, error) {
+	key := deviceVoteKey(gameID, deviceID)
+	var previous string
+	var err error
+	if value == 0 {
+		previous, err = r.rdb.GetDel(ctx, key).Result()
+	} else {
+		previous, err = r.rdb.SetArgs(ctx, key, value, redis.SetArgs{Get: true, TTL: ttl}).Result()
+	}
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(previous)
+}
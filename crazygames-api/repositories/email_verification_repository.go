@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type EmailVerificationRepository struct {
+	rdb *redis.Client
+}
+
+type EmailVerificationRepositoryInterface interface {
+	StartResendCooldown(ctx context.Context, email string, cooldown time.Duration) (bool, error)
+}
+
+func NewEmailVerificationRepository(rdb *redis.Client) *EmailVerificationRepository {
+	return &EmailVerificationRepository{rdb: rdb}
+}
+
+func resendCooldownKey(email string) string {
+	return "email_verification_cooldown:" + strings.ToLower(email)
+}
+
+// StartResendCooldown reports whether a verification email may be sent to
+// email now, and if so blocks further ones until cooldown has passed.
+func (r *EmailVerificationRepository) StartResendCooldown(ctx context.Context, email string, cooldown time.Duration) (bool, error) {
+	return r.rdb.SetNX(ctx, resendCooldownKey(email), 1, cooldown).Result()
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResendCooldown(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	t.Run("first resend should be allowed", func(t *testing.T) {
+		allowed, err := emailVerificationRepository.StartResendCooldown(ctx, "player@gmail.com", time.Minute)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("resend during cooldown should be refused", func(t *testing.T) {
+		allowed, err := emailVerificationRepository.StartResendCooldown(ctx, "Player@gmail.com", time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("resend to another email should be allowed", func(t *testing.T) {
+		allowed, _ := emailVerificationRepository.StartResendCooldown(ctx, "other@gmail.com", time.Minute)
+		assert.True(t, allowed)
+	})
+}
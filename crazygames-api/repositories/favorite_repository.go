@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FavoriteRepository struct {
+	db *gorm.DB
+}
+
+type FavoriteRepositoryInterface interface {
+	Add(userID uint, gameID uint) error
+	Remove(userID uint, gameID uint) error
+	GetByUser(userID uint, query request.FavoritesRequestQuery) ([]entities.Favorite, int64, error)
+	GetFavoriteGameIDs(userID uint, gameIDs []uint) (map[uint]bool, error)
+}
+
+func NewFavoriteRepository(db *gorm.DB) *FavoriteRepository {
+	return &FavoriteRepository{db: db}
+}
+
+// Add makes the game a favorite of the user, once.
+func (r *FavoriteRepository) Add(userID uint, gameID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entities.Favorite{UserID: userID, GameID: gameID}).Error
+}
+
+func (r *FavoriteRepository) Remove(userID uint, gameID uint) error {
+	return r.db.Where("user_id = ? AND game_id = ?", userID, gameID).Delete(&entities.Favorite{}).Error
+}
+
+// GetByUser returns the favorites of the user with their game, last added
+// first.
+func (r *FavoriteRepository) GetByUser(userID uint, queryParams request.FavoritesRequestQuery) ([]entities.Favorite, int64, error) {
+	var favorites []entities.Favorite
+	var total int64
+
+	query := r.db.Model(&entities.Favorite{}).
+		Joins("JOIN games ON games.id = favorites.game_id").
+		Where("favorites.user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Preload("Game.Category").Preload("Game.Tags").
+		Order("favorites.created_at DESC, favorites.id DESC").
+		Offset(queryParams.PageSize * (queryParams.PageNumber - 1)).
+		Limit(queryParams.PageSize).
+		Find(&favorites).Error
+	return favorites, total, err
+}
+
+// GetFavoriteGameIDs returns which of the games are favorites of the user,
+// with one query for all of them.
+func (r *FavoriteRepository) GetFavoriteGameIDs(userID uint, gameIDs []uint) (map[uint]bool, error) {
+	favorites := make(map[uint]bool)
+	if len(gameIDs) == 0 {
+		return favorites, nil
+	}
+
+	var ids []uint
+	err := r.db.Model(&entities.Favorite{}).Where("user_id = ? AND game_id IN ?", userID, gameIDs).
+		Pluck("game_id", &ids).Error
+	for _, id := range ids {
+		favorites[id] = true
+	}
+	return favorites, err
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Favorites(t *testing.T) {
+	db.Exec("DELETE FROM favorites")
+	db.Exec("DELETE FROM games")
+
+	first := &entities.Game{GameTitle: "First", GameURL: "http://first.com"}
+	second := &entities.Game{GameTitle: "Second", GameURL: "http://second.com"}
+	db.Create(first)
+	db.Create(second)
+	userID := uint(1)
+
+	t.Run("adding a favorite twice should keep one", func(t *testing.T) {
+		assert.NoError(t, favoriteRepository.Add(userID, first.ID))
+		assert.NoError(t, favoriteRepository.Add(userID, first.ID))
+		assert.NoError(t, favoriteRepository.Add(userID, second.ID))
+
+		favorites, total, err := favoriteRepository.GetByUser(userID, request.FavoritesRequestQuery{PageNumber: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, second.ID, favorites[0].GameID, "last added should be first")
+		assert.Equal(t, "Second", favorites[0].Game.GameTitle)
+	})
+
+	t.Run("favorites should be looked up for many games at once", func(t *testing.T) {
+		favorites, err := favoriteRepository.GetFavoriteGameIDs(userID, []uint{first.ID, second.ID, 999})
+		assert.NoError(t, err)
+		assert.Equal(t, map[uint]bool{first.ID: true, second.ID: true}, favorites)
+
+		favorites, err = favoriteRepository.GetFavoriteGameIDs(2, []uint{first.ID})
+		assert.NoError(t, err)
+		assert.Empty(t, favorites)
+	})
+
+	t.Run("removed and deleted games should not be favorites", func(t *testing.T) {
+		assert.NoError(t, favoriteRepository.Remove(userID, first.ID))
+		assert.NoError(t, gameRepository.Delete(context.Background(), second.ID))
+
+		_, total, err := favoriteRepository.GetByUser(userID, request.FavoritesRequestQuery{PageNumber: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.Zero(t, total)
+	})
+}
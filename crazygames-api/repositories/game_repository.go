@@ -1,184 +1,361 @@
-package repositories
-
-import (
-	"errors"
-	"strconv"
-
-	"crazygames.io/entities"
-	"crazygames.io/handler/request"
-	"crazygames.io/repositories/scopes"
-	"gorm.io/gorm"
-)
-
-type GameRepository struct {
-	db *gorm.DB
-}
-
-type GameRepositoryInterface interface {
-	Create(game *entities.Game, categoryID string) error
-	GetAll(query request.GamesRequestQuery) ([]entities.Game, int64, error)
-	GetByID(id uint) (*entities.Game, error)
-	GetByCategoryID(id uint) ([]entities.Game, error)
-	Update(game *entities.Game, categoryID string) (*entities.Game, error)
-	Delete(id uint) error
-	ListByCategory(categoryId uint) ([]entities.Game, error)
-}
-
-func NewGameRepository(db *gorm.DB) *GameRepository {
-	return &GameRepository{db: db}
-}
-
-func (r *GameRepository) Create(game *entities.Game, categoryId string) error {
-	categoryIDUint, err := strconv.ParseUint(categoryId, 10, 32)
-	if err != nil {
-		return errors.New("invalid category ID")
-	}
-	var category entities.Category
-	err = r.db.Find(&category, uint(categoryIDUint)).Error
-	if err != nil {
-		return errors.New("category not found")
-	}
-
-	// Start a transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	// Create the game
-	if err = tx.Create(game).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Associate the game with the category
-	if err = tx.Model(game).Association("Category").Append(&category); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Commit the transaction
-	if err = tx.Commit().Error; err != nil {
-		return err
-	}
-
-	game.Category = []entities.Category{category}
-	return nil
-}
-
-func (r *GameRepository) GetAll(queryParams request.GamesRequestQuery) ([]entities.Game, int64, error) {
-	var games []entities.Game
-	var total int64
-
-	query := r.db.Model(&entities.Game{}).Scopes(scopes.FilterByGameTitle(queryParams.Search)).Preload("Category")
-
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	err := query.Offset(queryParams.PageSize * (queryParams.PageNumber - 1)).
-		Limit(queryParams.PageSize).Find(&games).Error
-
-	return games, total, err
-}
-
-func (r *GameRepository) GetByID(id uint) (*entities.Game, error) {
-	var game entities.Game
-	err := r.db.Preload("Category").First(&game, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &game, nil
-}
-
-func (r *GameRepository) GetByCategoryID(id uint) ([]entities.Game, error) {
-	var category entities.Category
-	err := r.db.Preload("Game.Category").First(&category, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return category.Game, nil
-}
-
-func (r *GameRepository) Update(game *entities.Game, categoryID string) (*entities.Game, error) {
-	var category entities.Category
-	if categoryID != "" {
-		categoryIDUint, err := strconv.ParseUint(categoryID, 10, 32)
-		if err != nil {
-			return nil, errors.New("invalid category ID")
-		}
-		err = r.db.Find(&category, uint(categoryIDUint)).Error
-		if err != nil {
-			return nil, errors.New("category not found")
-		}
-	}
-
-	// Start a transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-
-	// Create the game
-	if err := tx.Save(game).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	// Associate the game with the category
-	if err := tx.Model(game).Association("Category").Replace(&category); err != nil {
-		tx.Rollback()
-		return nil, err
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, err
-	}
-	game.Category = []entities.Category{category}
-	return game, nil
-}
-
-func (r *GameRepository) Delete(id uint) error {
-	var loadedGame entities.Game
-	err := r.db.Preload("Category").First(&loadedGame, id).Error
-
-	if err != nil {
-		return errors.New("game not found")
-	}
-	// Start a transaction
-	tx := r.db.Begin()
-	if tx.Error != nil {
-		return tx.Error
-	}
-
-	if err := tx.Model(&loadedGame).Association("Category").Clear(); err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Create the game
-	if err := tx.Delete(&loadedGame).Error; err != nil {
-		tx.Rollback()
-		return err
-	}
-
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (r *GameRepository) ListByCategory(categoryId uint) ([]entities.Game, error) {
-	var games []entities.Game
-	err := r.db.Joins("JOIN game_categories ON game_categories.game_id = games.id").
-		Where("game_categories.category_id = ?", categoryId).
-		Preload("Category").
-		Find(&games).Error
-	if err != nil {
-		return nil, err
-	}
-	return games, nil
-}
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories/scopes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrCategoryNotFound = errors.New("category not found")
+
+// How long a flush of play counts is remembered, to skip it if retried.
+const playCountFlushRetention = 24 * time.Hour
+
+type GameRepository struct {
+	db *gorm.DB
+}
+
+type GameRepositoryInterface interface {
+	Create(ctx context.Context, game *entities.Game, categoryIDs []uint) error
+	GetAll(query request.GamesRequestQuery) ([]entities.Game, int64, error)
+	GetByID(id uint) (*entities.Game, error)
+	GetByCategoryID(id uint) ([]entities.Game, error)
+	Update(ctx context.Context, game *entities.Game, categoryIDs []uint) (*entities.Game, error)
+	Delete(ctx context.Context, id uint) error
+	ListByCategory(categoryId uint) ([]entities.Game, error)
+	Exists(id uint) (bool, error)
+	IncrementPlayCounts(flushID string, counts map[uint]int64) error
+	GetByIDs(ids []uint) ([]entities.Game, error)
+	GetNewest(limit int) ([]entities.Game, error)
+	GetPlayCounts() (map[uint]int64, error)
+	CheckCategories(ids []uint) error
+}
+
+func NewGameRepository(db *gorm.DB) *GameRepository {
+	return &GameRepository{db: db}
+}
+
+// Create adds the game to the categories, failing with ErrCategoryNotFound if
+// any of them does not exist.
+func (r *GameRepository) Create(ctx context.Context, game *entities.Game, categoryIDs []uint) error {
+	categories, err := r.findCategories(categoryIDs)
+	if err != nil {
+		return err
+	}
+
+	// Start a transaction
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	// Create the game
+	if err = tx.Create(game).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Associate the game with the categories
+	if len(categories) > 0 {
+		if err = tx.Model(game).Association("Category").Append(categories); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	game.Category = categories
+	if err = recordAudit(ctx, tx, entities.AuditActionCreate, entities.AuditEntityGame, game.ID, nil, game); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err = tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *GameRepository) GetAll(queryParams request.GamesRequestQuery) ([]entities.Game, int64, error) {
+	var games []entities.Game
+	var total int64
+
+	query := r.db.Model(&entities.Game{}).
+		Scopes(scopes.FilterByGameTitle(queryParams.Search), scopes.FilterByTags(queryParams.Tags)).
+		Preload("Category").Preload("Tags")
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Offset(queryParams.PageSize * (queryParams.PageNumber - 1)).
+		Limit(queryParams.PageSize).Find(&games).Error
+
+	return games, total, err
+}
+
+func (r *GameRepository) GetByID(id uint) (*entities.Game, error) {
+	var game entities.Game
+	err := r.db.Preload("Category").Preload("Tags").First(&game, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &game, nil
+}
+
+func (r *GameRepository) GetByCategoryID(id uint) ([]entities.Game, error) {
+	var category entities.Category
+	err := r.db.Preload("Game.Category").Preload("Game.Tags").First(&category, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return category.Game, nil
+}
+
+// gameEditableColumns are the columns of a game set by the admin API
+var gameEditableColumns = []string{
+	"game_title", "description", "developer", "release_date", "thumbnail_url",
+	"technology", "hover_video_url", "game_url", "primary_category_id", "updated_at",
+}
+
+// Update saves the game and moves it to the categories, which must all exist.
+// The categories are left as they are when categoryIDs is nil.
+func (r *GameRepository) Update(ctx context.Context, game *entities.Game, categoryIDs []uint) (*entities.Game, error) {
+	var categories []entities.Category
+	if categoryIDs != nil {
+		var err error
+		if categories, err = r.findCategories(categoryIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Start a transaction
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	// Keep the stored game for the audit log
+	var existingGame entities.Game
+	if err := tx.Preload("Category").Preload("Tags").First(&existingGame, game.ID).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Update the editable columns only, the counters kept by votes, reviews
+	// and plays may have changed since the game was loaded
+	if err := tx.Model(game).Select(gameEditableColumns).Updates(game).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	game.Rating = existingGame.Rating
+	game.RatingCount = existingGame.RatingCount
+	game.PlayCount = existingGame.PlayCount
+	game.UpVotes = existingGame.UpVotes
+	game.DownVotes = existingGame.DownVotes
+	game.SessionCount = existingGame.SessionCount
+	game.TotalPlaySeconds = existingGame.TotalPlaySeconds
+	game.LikeRatio = existingGame.LikeRatio
+	game.AverageSessionSeconds = existingGame.AverageSessionSeconds
+
+	// Associate the game with the categories
+	if categoryIDs != nil {
+		if err := tx.Model(game).Association("Category").Replace(categories); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		game.Category = categories
+	} else {
+		game.Category = existingGame.Category
+	}
+
+	if err := recordAudit(ctx, tx, entities.AuditActionUpdate, entities.AuditEntityGame, game.ID, &existingGame, game); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+func (r *GameRepository) Delete(ctx context.Context, id uint) error {
+	var loadedGame entities.Game
+	err := r.db.WithContext(ctx).Preload("Category").First(&loadedGame, id).Error
+
+	if err != nil {
+		return errors.New("game not found")
+	}
+	// Start a transaction
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	if err := tx.Model(&loadedGame).Association("Category").Clear(); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("game_id = ?", id).Delete(&entities.Favorite{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("game_id = ?", id).Delete(&entities.GameTag{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	reviewIDs := tx.Model(&entities.Review{}).Select("id").Where("game_id = ?", id)
+	if err := tx.Where("review_id IN (?)", reviewIDs).Delete(&entities.ReviewVote{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("game_id = ?", id).Delete(&entities.Review{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("game_id = ?", id).Delete(&entities.GameVote{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Create the game
+	if err := tx.Delete(&loadedGame).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := recordAudit(ctx, tx, entities.AuditActionDelete, entities.AuditEntityGame, id, &loadedGame, nil); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Commit the transaction
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CheckCategories fails with ErrCategoryNotFound if any of the categories does
+// not exist.
+func (r *GameRepository) CheckCategories(ids []uint) error {
+	_, err := r.findCategories(ids)
+	return err
+}
+
+// findCategories loads the categories in the order of their IDs with one
+// query, failing with ErrCategoryNotFound if any of them does not exist.
+func (r *GameRepository) findCategories(ids []uint) ([]entities.Category, error) {
+	categories := []entities.Category{}
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	if err := r.db.Find(&categories, ids).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]entities.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+	ordered := make([]entities.Category, 0, len(ids))
+	added := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		category, ok := byID[id]
+		if !ok {
+			return nil, ErrCategoryNotFound
+		}
+		if !added[id] {
+			added[id] = true
+			ordered = append(ordered, category)
+		}
+	}
+	return ordered, nil
+}
+
+func (r *GameRepository) ListByCategory(categoryId uint) ([]entities.Game, error) {
+	var games []entities.Game
+	err := r.db.Joins("JOIN game_categories ON game_categories.game_id = games.id").
+		Where("game_categories.category_id = ?", categoryId).
+		Preload("Category").Preload("Tags").
+		Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+	return games, nil
+}
+
+func (r *GameRepository) Exists(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.Game{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+// IncrementPlayCounts adds the plays of the flush to the play count of each
+// game ID. A flush that was already added is skipped, so that it can be retried.
+func (r *GameRepository) IncrementPlayCounts(flushID string, counts map[uint]int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entities.PlayCountFlush{ID: flushID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		for gameID, count := range counts {
+			err := tx.Model(&entities.Game{}).Where("id = ?", gameID).
+				UpdateColumn("play_count", gorm.Expr("play_count + ?", count)).Error
+			if err != nil {
+				return err
+			}
+		}
+		// Flushes are retried within minutes, older ones need not be kept.
+		return tx.Where("created_at < ?", time.Now().Add(-playCountFlushRetention)).
+			Delete(&entities.PlayCountFlush{}).Error
+	})
+}
+
+// GetByIDs returns the games that exist among the IDs, in no particular order.
+func (r *GameRepository) GetByIDs(ids []uint) ([]entities.Game, error) {
+	var games []entities.Game
+	if len(ids) == 0 {
+		return games, nil
+	}
+	err := r.db.Preload("Category").Preload("Tags").Find(&games, ids).Error
+	return games, err
+}
+
+// GetNewest returns the games last added first.
+func (r *GameRepository) GetNewest(limit int) ([]entities.Game, error) {
+	var games []entities.Game
+	err := r.db.Preload("Category").Preload("Tags").Order("created_at DESC, id DESC").Limit(limit).Find(&games).Error
+	return games, err
+}
+
+// GetPlayCounts returns the play count of every game that was played.
+func (r *GameRepository) GetPlayCounts() (map[uint]int64, error) {
+	var games []entities.Game
+	err := r.db.Select("id, play_count").Where("play_count > 0").Find(&games).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(games))
+	for _, game := range games {
+		counts[game.ID] = int64(game.PlayCount)
+	}
+	return counts, nil
+}
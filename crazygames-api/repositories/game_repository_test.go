@@ -1,486 +1,604 @@
-package repositories
-
-import (
-	"strconv"
-	"testing"
-
-	"crazygames.io/entities"
-	"crazygames.io/handler/request"
-)
-
-func createGame(game *entities.Game, categoryId string) (*entities.Game, error) {
-	err := gameRepository.Create(game, categoryId)
-	if err != nil {
-		return nil, err
-	}
-
-	return game, nil
-}
-
-func TestGameRepository_Create_Success(t *testing.T) {
-	db.Exec("DELETE FROM games")
-	db.Exec("DELETE FROM categories")
-
-	category := &entities.Category{CategoryName: "Action"}
-	categoryRepository.Create(category)
-
-	game := &entities.Game{GameTitle: "Test Game", GameURL: "http://testgame.com"}
-	err := gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if game.ID == 0 {
-		t.Errorf("expected game to have an ID, got 0")
-	}
-
-	if len(game.Category) != 1 || game.Category[0].ID != category.ID {
-		t.Errorf("expected game to be associated with category, got %v", game.Category)
-	}
-}
-
-func TestGameRepository_GetByID_Success(t *testing.T) {
-	db.Exec("DELETE FROM games")
-	db.Exec("DELETE FROM categories")
-
-	category := &entities.Category{CategoryName: "Adventure"}
-	categoryRepository.Create(category)
-
-	game := &entities.Game{GameTitle: "Adventure Game", GameURL: "http://adventuregame.com"}
-	gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-
-	fetchedGame, err := gameRepository.GetByID(game.ID)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if fetchedGame.ID != game.ID {
-		t.Errorf("expected game ID %d, got %d", game.ID, fetchedGame.ID)
-	}
-}
-
-func TestGameRepository_Update_Success(t *testing.T) {
-	db.Exec("DELETE FROM games")
-	db.Exec("DELETE FROM categories")
-
-	category1 := &entities.Category{CategoryName: "Old Category"}
-	category2 := &entities.Category{CategoryName: "New Category"}
-	categoryRepository.Create(category1)
-	categoryRepository.Create(category2)
-
-	game := &entities.Game{GameTitle: "Old Game", GameURL: "http://oldgame.com"}
-	gameRepository.Create(game, strconv.Itoa(int(category1.ID)))
-
-	game.GameTitle = "Updated Game"
-	updatedGame, err := gameRepository.Update(game, strconv.Itoa(int(category2.ID)))
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if updatedGame.GameTitle != "Updated Game" {
-		t.Errorf("expected updated game title, got %s", updatedGame.GameTitle)
-	}
-
-	if len(updatedGame.Category) != 1 || updatedGame.Category[0].ID != category2.ID {
-		t.Errorf("expected game to be associated with new category, got %v", updatedGame.Category)
-	}
-}
-
-func TestGameRepository_Delete_Success(t *testing.T) {
-	db.Exec("DELETE FROM games")
-	db.Exec("DELETE FROM categories")
-
-	category := &entities.Category{CategoryName: "Delete Test"}
-	err := categoryRepository.Create(category)
-	if err != nil {
-		t.Fatalf("failed to create category: %v", err)
-	}
-
-	game := &entities.Game{GameTitle: "Game to Delete", GameURL: "http://deletegame.com"}
-	err = gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game: %v", err)
-	}
-
-	// Fetch the game to ensure it exists
-	fetchedGame, err := gameRepository.GetByID(game.ID)
-	if err != nil {
-		t.Fatalf("failed to fetch game before deletion: %v", err)
-	}
-	t.Logf("fetched game: %+v", fetchedGame)
-
-	// Delete the game
-	err = gameRepository.Delete(game.ID)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	// Try to fetch the game again to ensure it is deleted
-	_, err = gameRepository.GetByID(game.ID)
-	if err == nil {
-		t.Errorf("expected error for deleted game, got nil")
-	}
-}
-
-func TestGameRepository_ListByCategory_Success(t *testing.T) {
-	db.Exec("DELETE FROM games")
-	db.Exec("DELETE FROM categories")
-
-	category := &entities.Category{CategoryName: "Category Testing"}
-	err := categoryRepository.Create(category)
-	if err != nil {
-		t.Fatalf("failed to create category: %v", err)
-	}
-
-	game1 := &entities.Game{GameTitle: "Game 1", GameURL: "http://game1.com"}
-	game2 := &entities.Game{GameTitle: "Game 2", GameURL: "http://game2.com"}
-	err = gameRepository.Create(game1, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game1: %v", err)
-	}
-	err = gameRepository.Create(game2, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game2: %v", err)
-	}
-
-	games, err := gameRepository.ListByCategory(category.ID)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if len(games) != 2 {
-		t.Errorf("expected 2 games, got %d", len(games))
-	}
-}
-
-func TestGameRepository_GetAll_Success(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	category := &entities.Category{CategoryName: "All Games Test"}
-	err := categoryRepository.Create(category)
-	if err != nil {
-		t.Fatalf("failed to create category: %v", err)
-	}
-
-	game1 := &entities.Game{GameTitle: "Game 1", GameURL: "http://game1.com"}
-	game2 := &entities.Game{GameTitle: "Game 2", GameURL: "http://game2.com"}
-	err = gameRepository.Create(game1, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game1: %v", err)
-	}
-	err = gameRepository.Create(game2, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game2: %v", err)
-	}
-
-	var query = request.GamesRequestQuery{
-		PageNumber: 1,
-		PageSize:   10,
-		Search:     "",
-	}
-	games, _, err := gameRepository.GetAll(query)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if len(games) != 2 {
-		t.Errorf("expected 2 games, got %d", len(games))
-	}
-}
-
-func TestGameRepository_Create_InvalidCategoryID(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	game := &entities.Game{GameTitle: "Invalid Category Game", GameURL: "http://invalidcategory.com"}
-	err := gameRepository.Create(game, "invalid_id")
-	if err == nil {
-		t.Errorf("expected error for invalid category ID, got nil")
-	}
-}
-
-func TestGameRepository_Create_CategoryNotFound(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	game := &entities.Game{GameTitle: "Nonexistent Category Game", GameURL: "http://nonexistentcategory.com"}
-	err := gameRepository.Create(game, "9999") // Nonexistent category ID
-	if err == nil {
-		t.Errorf("expected error for nonexistent category, got nil")
-	}
-}
-
-func TestGameRepository_Update_InvalidCategoryID(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	category := &entities.Category{CategoryName: "Valid Category"}
-	categoryRepository.Create(category)
-
-	game := &entities.Game{GameTitle: "Game to Update", GameURL: "http://updategame.com"}
-	gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-
-	game.GameTitle = "Updated Game"
-	_, err := gameRepository.Update(game, "invalid_id")
-	if err == nil {
-		t.Errorf("expected error for invalid category ID, got nil")
-	}
-}
-
-func TestGameRepository_Update_CategoryNotFound(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	category := &entities.Category{CategoryName: "Valid Category"}
-	categoryRepository.Create(category)
-
-	game := &entities.Game{GameTitle: "Game to Update", GameURL: "http://updategame.com"}
-	gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-
-	game.GameTitle = "Updated Game"
-	_, err := gameRepository.Update(game, "9999") // Nonexistent category ID
-	if err == nil {
-		t.Errorf("expected error for nonexistent category, got nil")
-	}
-}
-
-func TestGameRepository_Delete_GameNotFound(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	err := gameRepository.Delete(9999) // Nonexistent game ID
-	if err == nil {
-		t.Errorf("expected error for nonexistent game, got nil")
-	}
-}
-
-func TestGameRepository_GetByID_GameNotFound(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-	_, err := gameRepository.GetByID(9999) // Nonexistent game ID
-	if err == nil {
-		t.Errorf("expected error for nonexistent game, got nil")
-	}
-}
-
-func TestGameRepository_ListByCategory_NoGames(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	category := &entities.Category{CategoryName: "Empty Category"}
-	categoryRepository.Create(category)
-
-	games, err := gameRepository.ListByCategory(category.ID)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if len(games) != 0 {
-		t.Errorf("expected 0 games, got %d", len(games))
-	}
-}
-
-func TestGameRepository_Create_CategoryDeleted(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	category := &entities.Category{CategoryName: "Temporary Category"}
-	categoryRepository.Create(category)
-
-	// Delete the category
-	categoryRepository.Delete(category.ID)
-
-	game := &entities.Game{GameTitle: "Game with Deleted Category", GameURL: "http://deletedcategory.com"}
-	err := gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-	if err == nil {
-		t.Errorf("expected error for deleted category, got nil")
-	}
-}
-
-func TestGameRepository_GetAll_Preloading(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	category := &entities.Category{CategoryName: "Preload Test"}
-	categoryRepository.Create(category)
-
-	game := &entities.Game{GameTitle: "Game with Category", GameURL: "http://preloadtest.com"}
-	gameRepository.Create(game, strconv.Itoa(int(category.ID)))
-
-	var query = request.GamesRequestQuery{
-		PageNumber: 1,
-		PageSize:   10,
-		Search:     "",
-	}
-	games, _, err := gameRepository.GetAll(query)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if len(games) != 1 {
-		t.Errorf("expected 1 game, got %d", len(games))
-	}
-
-	if len(games[0].Category) != 1 || games[0].Category[0].ID != category.ID {
-		t.Errorf("expected game to be associated with category, got %v", games[0].Category)
-	}
-}
-
-func TestGameRepository_ListByCategory_CategoryNotFound(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	games, err := gameRepository.ListByCategory(9999) // Nonexistent category ID
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	if len(games) != 0 {
-		t.Errorf("expected 0 games, got %d", len(games))
-	}
-}
-
-func TestGameRepository_GetByCategoryID_Success(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	// Create a category
-	category := &entities.Category{CategoryName: "Test Category"}
-	err := categoryRepository.Create(category)
-	if err != nil {
-		t.Fatalf("failed to create category: %v", err)
-	}
-
-	// Create games associated with the category
-	game1 := &entities.Game{GameTitle: "Game 1", GameURL: "http://game1.com"}
-	game2 := &entities.Game{GameTitle: "Game 2", GameURL: "http://game2.com"}
-	err = gameRepository.Create(game1, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game1: %v", err)
-	}
-	err = gameRepository.Create(game2, strconv.Itoa(int(category.ID)))
-	if err != nil {
-		t.Fatalf("failed to create game2: %v", err)
-	}
-
-	// Fetch games by category ID
-	games, err := gameRepository.GetByCategoryID(category.ID)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	// Verify the number of games returned
-	if len(games) != 2 {
-		t.Errorf("expected 2 games, got %d", len(games))
-	}
-
-	// Verify the games' details
-	if games[0].GameTitle != "Game 1" && games[1].GameTitle != "Game 2" {
-		t.Errorf("expected games to match, got %+v", games)
-	}
-}
-
-func TestGameRepository_GetByCategoryID_CategoryNotFound(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	// Attempt to fetch games for a nonexistent category
-	_, err := gameRepository.GetByCategoryID(9999) // Nonexistent category ID
-	if err == nil {
-		t.Errorf("expected error for nonexistent category, got nil")
-	}
-}
-
-func TestGameRepository_GetByCategoryID_NoGames(t *testing.T) {
-	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
-	db.Exec("TRUNCATE TABLE reviews")
-	db.Exec("TRUNCATE TABLE favorites")
-	db.Exec("TRUNCATE TABLE game_categories")
-	db.Exec("TRUNCATE TABLE games")
-	db.Exec("TRUNCATE TABLE categories")
-	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
-
-	// Create a category with no games
-	category := &entities.Category{CategoryName: "Empty Category"}
-	err := categoryRepository.Create(category)
-	if err != nil {
-		t.Fatalf("failed to create category: %v", err)
-	}
-
-	// Fetch games by category ID
-	games, err := gameRepository.GetByCategoryID(category.ID)
-	if err != nil {
-		t.Errorf("expected no error, got %v", err)
-	}
-
-	// Verify that the returned slice is empty
-	if len(games) != 0 {
-		t.Errorf("expected 0 games, got %d", len(games))
-	}
-}
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+)
+
+func createGame(game *entities.Game, categoryIDs []uint) (*entities.Game, error) {
+	err := gameRepository.Create(context.Background(), game, categoryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+func TestGameRepository_Create_Success(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category := &entities.Category{CategoryName: "Action"}
+	categoryRepository.Create(context.Background(), category)
+
+	game := &entities.Game{GameTitle: "Test Game", GameURL: "http://testgame.com"}
+	err := gameRepository.Create(context.Background(), game, []uint{category.ID})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if game.ID == 0 {
+		t.Errorf("expected game to have an ID, got 0")
+	}
+
+	if len(game.Category) != 1 || game.Category[0].ID != category.ID {
+		t.Errorf("expected game to be associated with category, got %v", game.Category)
+	}
+}
+
+func TestGameRepository_GetByID_Success(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category := &entities.Category{CategoryName: "Adventure"}
+	categoryRepository.Create(context.Background(), category)
+
+	game := &entities.Game{GameTitle: "Adventure Game", GameURL: "http://adventuregame.com"}
+	gameRepository.Create(context.Background(), game, []uint{category.ID})
+
+	fetchedGame, err := gameRepository.GetByID(game.ID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if fetchedGame.ID != game.ID {
+		t.Errorf("expected game ID %d, got %d", game.ID, fetchedGame.ID)
+	}
+}
+
+func TestGameRepository_Update_Success(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category1 := &entities.Category{CategoryName: "Old Category"}
+	category2 := &entities.Category{CategoryName: "New Category"}
+	categoryRepository.Create(context.Background(), category1)
+	categoryRepository.Create(context.Background(), category2)
+
+	game := &entities.Game{GameTitle: "Old Game", GameURL: "http://oldgame.com"}
+	gameRepository.Create(context.Background(), game, []uint{category1.ID})
+
+	game.GameTitle = "Updated Game"
+	updatedGame, err := gameRepository.Update(context.Background(), game, []uint{category2.ID})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if updatedGame.GameTitle != "Updated Game" {
+		t.Errorf("expected updated game title, got %s", updatedGame.GameTitle)
+	}
+
+	if len(updatedGame.Category) != 1 || updatedGame.Category[0].ID != category2.ID {
+		t.Errorf("expected game to be associated with new category, got %v", updatedGame.Category)
+	}
+}
+
+func TestGameRepository_Delete_Success(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category := &entities.Category{CategoryName: "Delete Test"}
+	err := categoryRepository.Create(context.Background(), category)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	game := &entities.Game{GameTitle: "Game to Delete", GameURL: "http://deletegame.com"}
+	err = gameRepository.Create(context.Background(), game, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+
+	// Fetch the game to ensure it exists
+	fetchedGame, err := gameRepository.GetByID(game.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch game before deletion: %v", err)
+	}
+	t.Logf("fetched game: %+v", fetchedGame)
+
+	// Delete the game
+	err = gameRepository.Delete(context.Background(), game.ID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// Try to fetch the game again to ensure it is deleted
+	_, err = gameRepository.GetByID(game.ID)
+	if err == nil {
+		t.Errorf("expected error for deleted game, got nil")
+	}
+}
+
+func TestGameRepository_Delete_RemovesReviewsAndVotes(t *testing.T) {
+	db.Exec("DELETE FROM review_votes")
+	db.Exec("DELETE FROM reviews")
+	db.Exec("DELETE FROM game_votes")
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM users")
+
+	game := &entities.Game{GameTitle: "Voted Game", GameURL: "http://votedgame.com"}
+	if err := gameRepository.Create(context.Background(), game, nil); err != nil {
+		t.Fatalf("failed to create game: %v", err)
+	}
+	user, err := createUser("gamevoter", "gamevoter@gmail.com", "password", "player")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	review := &entities.Review{GameID: game.ID, UserID: &user.ID, Rating: 4}
+	if err := reviewRepository.Create(context.Background(), review, RatingPrior{Mean: 3, Weight: 5}); err != nil {
+		t.Fatalf("failed to create review: %v", err)
+	}
+	if err := reviewRepository.AddVote(review.ID, user.ID); err != nil {
+		t.Fatalf("failed to vote on review: %v", err)
+	}
+	if _, err := gameVoteRepository.SetUserVote(game.ID, user.ID, entities.VoteUp); err != nil {
+		t.Fatalf("failed to vote on game: %v", err)
+	}
+
+	if err := gameRepository.Delete(context.Background(), game.ID); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var reviews, reviewVotes, gameVotes int64
+	db.Model(&entities.Review{}).Where("game_id = ?", game.ID).Count(&reviews)
+	db.Model(&entities.ReviewVote{}).Where("review_id = ?", review.ID).Count(&reviewVotes)
+	db.Model(&entities.GameVote{}).Where("game_id = ?", game.ID).Count(&gameVotes)
+	if reviews != 0 || reviewVotes != 0 || gameVotes != 0 {
+		t.Errorf("expected no reviews or votes left, got %d reviews, %d review votes and %d game votes", reviews, reviewVotes, gameVotes)
+	}
+}
+
+func TestGameRepository_ListByCategory_Success(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category := &entities.Category{CategoryName: "Category Testing"}
+	err := categoryRepository.Create(context.Background(), category)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	game1 := &entities.Game{GameTitle: "Game 1", GameURL: "http://game1.com"}
+	game2 := &entities.Game{GameTitle: "Game 2", GameURL: "http://game2.com"}
+	err = gameRepository.Create(context.Background(), game1, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game1: %v", err)
+	}
+	err = gameRepository.Create(context.Background(), game2, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game2: %v", err)
+	}
+
+	games, err := gameRepository.ListByCategory(category.ID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(games) != 2 {
+		t.Errorf("expected 2 games, got %d", len(games))
+	}
+}
+
+func TestGameRepository_GetAll_Success(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	category := &entities.Category{CategoryName: "All Games Test"}
+	err := categoryRepository.Create(context.Background(), category)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	game1 := &entities.Game{GameTitle: "Game 1", GameURL: "http://game1.com"}
+	game2 := &entities.Game{GameTitle: "Game 2", GameURL: "http://game2.com"}
+	err = gameRepository.Create(context.Background(), game1, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game1: %v", err)
+	}
+	err = gameRepository.Create(context.Background(), game2, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game2: %v", err)
+	}
+
+	var query = request.GamesRequestQuery{
+		PageNumber: 1,
+		PageSize:   10,
+		Search:     "",
+	}
+	games, _, err := gameRepository.GetAll(query)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(games) != 2 {
+		t.Errorf("expected 2 games, got %d", len(games))
+	}
+}
+
+func TestGameRepository_Create_InvalidCategoryID(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	game := &entities.Game{GameTitle: "Invalid Category Game", GameURL: "http://invalidcategory.com"}
+	err := gameRepository.Create(context.Background(), game, []uint{0})
+	if err == nil {
+		t.Errorf("expected error for invalid category ID, got nil")
+	}
+}
+
+func TestGameRepository_Create_CategoryNotFound(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	game := &entities.Game{GameTitle: "Nonexistent Category Game", GameURL: "http://nonexistentcategory.com"}
+	err := gameRepository.Create(context.Background(), game, []uint{9999}) // Nonexistent category ID
+	if err == nil {
+		t.Errorf("expected error for nonexistent category, got nil")
+	}
+}
+
+func TestGameRepository_Update_InvalidCategoryID(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	category := &entities.Category{CategoryName: "Valid Category"}
+	categoryRepository.Create(context.Background(), category)
+
+	game := &entities.Game{GameTitle: "Game to Update", GameURL: "http://updategame.com"}
+	gameRepository.Create(context.Background(), game, []uint{category.ID})
+
+	game.GameTitle = "Updated Game"
+	_, err := gameRepository.Update(context.Background(), game, []uint{0})
+	if err == nil {
+		t.Errorf("expected error for invalid category ID, got nil")
+	}
+}
+
+func TestGameRepository_Update_CategoryNotFound(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	category := &entities.Category{CategoryName: "Valid Category"}
+	categoryRepository.Create(context.Background(), category)
+
+	game := &entities.Game{GameTitle: "Game to Update", GameURL: "http://updategame.com"}
+	gameRepository.Create(context.Background(), game, []uint{category.ID})
+
+	game.GameTitle = "Updated Game"
+	_, err := gameRepository.Update(context.Background(), game, []uint{9999}) // Nonexistent category ID
+	if err == nil {
+		t.Errorf("expected error for nonexistent category, got nil")
+	}
+}
+
+func TestGameRepository_Delete_GameNotFound(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	err := gameRepository.Delete(context.Background(), 9999) // Nonexistent game ID
+	if err == nil {
+		t.Errorf("expected error for nonexistent game, got nil")
+	}
+}
+
+func TestGameRepository_GetByID_GameNotFound(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+	_, err := gameRepository.GetByID(9999) // Nonexistent game ID
+	if err == nil {
+		t.Errorf("expected error for nonexistent game, got nil")
+	}
+}
+
+func TestGameRepository_ListByCategory_NoGames(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	category := &entities.Category{CategoryName: "Empty Category"}
+	categoryRepository.Create(context.Background(), category)
+
+	games, err := gameRepository.ListByCategory(category.ID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(games) != 0 {
+		t.Errorf("expected 0 games, got %d", len(games))
+	}
+}
+
+func TestGameRepository_Create_CategoryDeleted(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	category := &entities.Category{CategoryName: "Temporary Category"}
+	categoryRepository.Create(context.Background(), category)
+
+	// Delete the category
+	categoryRepository.Delete(context.Background(), category.ID)
+
+	game := &entities.Game{GameTitle: "Game with Deleted Category", GameURL: "http://deletedcategory.com"}
+	err := gameRepository.Create(context.Background(), game, []uint{category.ID})
+	if err == nil {
+		t.Errorf("expected error for deleted category, got nil")
+	}
+}
+
+func TestGameRepository_GetAll_Preloading(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	category := &entities.Category{CategoryName: "Preload Test"}
+	categoryRepository.Create(context.Background(), category)
+
+	game := &entities.Game{GameTitle: "Game with Category", GameURL: "http://preloadtest.com"}
+	gameRepository.Create(context.Background(), game, []uint{category.ID})
+
+	var query = request.GamesRequestQuery{
+		PageNumber: 1,
+		PageSize:   10,
+		Search:     "",
+	}
+	games, _, err := gameRepository.GetAll(query)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(games) != 1 {
+		t.Errorf("expected 1 game, got %d", len(games))
+	}
+
+	if len(games[0].Category) != 1 || games[0].Category[0].ID != category.ID {
+		t.Errorf("expected game to be associated with category, got %v", games[0].Category)
+	}
+}
+
+func TestGameRepository_ListByCategory_CategoryNotFound(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	games, err := gameRepository.ListByCategory(9999) // Nonexistent category ID
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(games) != 0 {
+		t.Errorf("expected 0 games, got %d", len(games))
+	}
+}
+
+func TestGameRepository_GetByCategoryID_Success(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	// Create a category
+	category := &entities.Category{CategoryName: "Test Category"}
+	err := categoryRepository.Create(context.Background(), category)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	// Create games associated with the category
+	game1 := &entities.Game{GameTitle: "Game 1", GameURL: "http://game1.com"}
+	game2 := &entities.Game{GameTitle: "Game 2", GameURL: "http://game2.com"}
+	err = gameRepository.Create(context.Background(), game1, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game1: %v", err)
+	}
+	err = gameRepository.Create(context.Background(), game2, []uint{category.ID})
+	if err != nil {
+		t.Fatalf("failed to create game2: %v", err)
+	}
+
+	// Fetch games by category ID
+	games, err := gameRepository.GetByCategoryID(category.ID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// Verify the number of games returned
+	if len(games) != 2 {
+		t.Errorf("expected 2 games, got %d", len(games))
+	}
+
+	// Verify the games' details
+	if games[0].GameTitle != "Game 1" && games[1].GameTitle != "Game 2" {
+		t.Errorf("expected games to match, got %+v", games)
+	}
+}
+
+func TestGameRepository_GetByCategoryID_CategoryNotFound(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	// Attempt to fetch games for a nonexistent category
+	_, err := gameRepository.GetByCategoryID(9999) // Nonexistent category ID
+	if err == nil {
+		t.Errorf("expected error for nonexistent category, got nil")
+	}
+}
+
+func TestGameRepository_GetByCategoryID_NoGames(t *testing.T) {
+	db.Exec("SET FOREIGN_KEY_CHECKS = 0")
+	db.Exec("TRUNCATE TABLE reviews")
+	db.Exec("TRUNCATE TABLE favorites")
+	db.Exec("TRUNCATE TABLE game_categories")
+	db.Exec("TRUNCATE TABLE games")
+	db.Exec("TRUNCATE TABLE categories")
+	db.Exec("SET FOREIGN_KEY_CHECKS = 1")
+
+	// Create a category with no games
+	category := &entities.Category{CategoryName: "Empty Category"}
+	err := categoryRepository.Create(context.Background(), category)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	// Fetch games by category ID
+	games, err := gameRepository.GetByCategoryID(category.ID)
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// Verify that the returned slice is empty
+	if len(games) != 0 {
+		t.Errorf("expected 0 games, got %d", len(games))
+	}
+}
+
+func TestGameRepository_Create_ManyCategories(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category1 := &entities.Category{CategoryName: "First Category"}
+	category2 := &entities.Category{CategoryName: "Second Category"}
+	categoryRepository.Create(context.Background(), category1)
+	categoryRepository.Create(context.Background(), category2)
+
+	game := &entities.Game{GameTitle: "Many Categories", GameURL: "http://manycategories.com"}
+	err := gameRepository.Create(context.Background(), game, []uint{category2.ID, category1.ID, category2.ID})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	if len(game.Category) != 2 || game.Category[0].ID != category2.ID || game.Category[1].ID != category1.ID {
+		t.Errorf("expected game to be associated with both categories in order, got %v", game.Category)
+	}
+
+	err = gameRepository.Create(context.Background(), &entities.Game{GameTitle: "Missing", GameURL: "http://missing.com"}, []uint{category1.ID, 9999})
+	if !errors.Is(err, ErrCategoryNotFound) {
+		t.Errorf("expected ErrCategoryNotFound, got %v", err)
+	}
+}
+
+func TestGameRepository_Update_KeepsCategories(t *testing.T) {
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM categories")
+
+	category1 := &entities.Category{CategoryName: "Kept Category"}
+	category2 := &entities.Category{CategoryName: "Other Category"}
+	categoryRepository.Create(context.Background(), category1)
+	categoryRepository.Create(context.Background(), category2)
+
+	game := &entities.Game{GameTitle: "Kept Game", GameURL: "http://keptgame.com"}
+	gameRepository.Create(context.Background(), game, []uint{category1.ID, category2.ID})
+
+	game.GameTitle = "Renamed Game"
+	game.Category = nil
+	if _, err := gameRepository.Update(context.Background(), game, nil); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	fetchedGame, err := gameRepository.GetByID(game.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch game: %v", err)
+	}
+	if fetchedGame.GameTitle != "Renamed Game" {
+		t.Errorf("expected updated game title, got %s", fetchedGame.GameTitle)
+	}
+	if len(fetchedGame.Category) != 2 {
+		t.Errorf("expected game to keep its 2 categories, got %v", fetchedGame.Category)
+	}
+}
+
+func TestGameRepository_IncrementPlayCounts_Once(t *testing.T) {
+	db.Exec("DELETE FROM play_count_flushes")
+	db.Exec("DELETE FROM games")
+
+	game := &entities.Game{GameTitle: "Played Game", GameURL: "http://playedgame.com"}
+	gameRepository.Create(context.Background(), game, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := gameRepository.IncrementPlayCounts("flush", map[uint]int64{game.ID: 3}); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	}
+
+	fetchedGame, err := gameRepository.GetByID(game.ID)
+	if err != nil {
+		t.Fatalf("failed to fetch game: %v", err)
+	}
+	if fetchedGame.PlayCount != 3 {
+		t.Errorf("expected a retried flush to be added once, got %d plays", fetchedGame.PlayCount)
+	}
+}
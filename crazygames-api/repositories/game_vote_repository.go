@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VoteCounts are the vote counters of a game.
+type VoteCounts struct {
+	UpVotes   int
+	DownVotes int
+}
+
+// GameVoteRepository keeps the votes of logged in users, and the counters on
+// the games that also add up the votes of anonymous players.
+type GameVoteRepository struct {
+	db *gorm.DB
+}
+
+type GameVoteRepositoryInterface interface {
+	SetUserVote(gameID uint, userID uint, value int) (*VoteCounts, error)
+	UpdateCounts(gameID uint, previous int, value int) (*VoteCounts, error)
+}
+
+func NewGameVoteRepository(db *gorm.DB) *GameVoteRepository {
+	return &GameVoteRepository{db: db}
+}
+
+// SetUserVote replaces the vote of the user on the game, 0 clearing it, and
+// returns the counters of the game.
+func (r *GameVoteRepository) SetUserVote(gameID uint, userID uint, value int) (*VoteCounts, error) {
+	var counts *VoteCounts
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var votes []entities.GameVote
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("game_id = ? AND user_id = ?", gameID, userID).Limit(1).Find(&votes).Error
+		if err != nil {
+			return err
+		}
+
+		previous := 0
+		if len(votes) > 0 {
+			previous = votes[0].Value
+		}
+		switch {
+		case value == 0:
+			err = tx.Where("game_id = ? AND user_id = ?", gameID, userID).Delete(&entities.GameVote{}).Error
+		case len(votes) > 0:
+			err = tx.Model(&votes[0]).Update("value", value).Error
+		default:
+			err = tx.Create(&entities.GameVote{GameID: gameID, UserID: userID, Value: value}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		counts, err = updateVoteCounts(tx, gameID, previous, value)
+		return err
+	})
+	return counts, err
+}
+
+// UpdateCounts moves a vote that is not kept here, from previous to value.
+func (r *GameVoteRepository) UpdateCounts(gameID uint, previous int, value int) (*VoteCounts, error) {
+	return updateVoteCounts(r.db, gameID, previous, value)
+}
+
+// updateVoteCounts moves a vote of the game from previous to value in its
+// counters, and returns them.
+func updateVoteCounts(tx *gorm.DB, gameID uint, previous int, value int) (*VoteCounts, error) {
+	delta := func(vote int) int {
+		change := 0
+		if value == vote {
+			change++
+		}
+		if previous == vote {
+			change--
+		}
+		return change
+	}
+	if previous != value {
+		err := tx.Model(&entities.Game{}).Where("id = ?", gameID).UpdateColumns(map[string]interface{}{
+			"up_votes":   gorm.Expr("up_votes + ?", delta(entities.VoteUp)),
+			"down_votes": gorm.Expr("down_votes + ?", delta(entities.VoteDown)),
+		}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var counts VoteCounts
+	err := tx.Model(&entities.Game{}).Select("up_votes, down_votes").Where("id = ?", gameID).Scan(&counts).Error
+	return &counts, err
+}
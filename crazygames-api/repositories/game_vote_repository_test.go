@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GameVotes(t *testing.T) {
+	db.Exec("DELETE FROM game_votes")
+	db.Exec("DELETE FROM games")
+	game := &entities.Game{GameTitle: "Voted", GameURL: "http://voted.com"}
+	db.Create(game)
+
+	t.Run("user vote should be replaced", func(t *testing.T) {
+		counts, err := gameVoteRepository.SetUserVote(game.ID, 1, entities.VoteUp)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteCounts{UpVotes: 1}, counts)
+
+		counts, err = gameVoteRepository.SetUserVote(game.ID, 1, entities.VoteUp)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteCounts{UpVotes: 1}, counts, "same vote should count once")
+
+		counts, err = gameVoteRepository.SetUserVote(game.ID, 1, entities.VoteDown)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteCounts{DownVotes: 1}, counts)
+	})
+
+	t.Run("cleared vote should be deleted", func(t *testing.T) {
+		counts, err := gameVoteRepository.SetUserVote(game.ID, 1, 0)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteCounts{}, counts)
+
+		var votes int64
+		db.Model(&entities.GameVote{}).Count(&votes)
+		assert.Zero(t, votes)
+	})
+
+	t.Run("like ratio should be found with the game", func(t *testing.T) {
+		gameVoteRepository.UpdateCounts(game.ID, 0, entities.VoteUp)
+		gameVoteRepository.UpdateCounts(game.ID, 0, entities.VoteUp)
+		gameVoteRepository.UpdateCounts(game.ID, 0, entities.VoteUp)
+		gameVoteRepository.UpdateCounts(game.ID, 0, entities.VoteDown)
+
+		found, err := gameRepository.GetByID(game.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 0.75, found.LikeRatio)
+	})
+}
+
+func Test_DeviceVotes(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	previous, err := deviceVoteRepository.Swap(ctx, 1, "device", entities.VoteUp, time.Hour)
+	assert.NoError(t, err)
+	assert.Zero(t, previous)
+
+	previous, err = deviceVoteRepository.Swap(ctx, 1, "device", entities.VoteDown, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.VoteUp, previous)
+	assert.Greater(t, rdb.TTL(ctx, deviceVoteKey(1, "device")).Val(), time.Duration(0))
+
+	previous, err = deviceVoteRepository.Swap(ctx, 1, "device", 0, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.VoteDown, previous)
+	assert.Zero(t, rdb.Exists(ctx, deviceVoteKey(1, "device")).Val())
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrLoginCodeInvalid  = errors.New("invalid or expired login code")
+	ErrOAuthStateInvalid = errors.New("invalid or expired state")
+)
+
+// OAuthState is what we remember about a login between redirecting to the provider and its callback.
+type OAuthState struct {
+	Provider     string `json:"provider"`
+	CodeVerifier string `json:"code_verifier"`
+	ReturnTo     string `json:"return_to"`
+	// LinkUserID is set when a logged-in user links the provider instead of logging in.
+	LinkUserID uint `json:"link_user_id,omitempty"`
+}
+
+type OAuthRepository struct {
+	rdb *redis.Client
+}
+
+type OAuthRepositoryInterface interface {
+	SaveLoginCode(ctx context.Context, codeHash string, userID uint, ttl time.Duration) error
+	ConsumeLoginCode(ctx context.Context, codeHash string) (uint, error)
+	SaveState(ctx context.Context, state string, oauthState *OAuthState, ttl time.Duration) error
+	ConsumeState(ctx context.Context, state string) (*OAuthState, error)
+}
+
+func NewOAuthRepository(rdb *redis.Client) *OAuthRepository {
+	return &OAuthRepository{rdb: rdb}
+}
+
+func loginCodeKey(codeHash string) string {
+	return "oauth_login_code:" + codeHash
+}
+
+func oauthStateKey(state string) string {
+	return "oauth_state:" + state
+}
+
+func (r *OAuthRepository) SaveLoginCode(ctx context.Context, codeHash string, userID uint, ttl time.Duration) error {
+	return r.rdb.Set(ctx, loginCodeKey(codeHash), userID, ttl).Err()
+}
+
+// ConsumeLoginCode returns the user the code was issued for and deletes it, so a code works only once.
+func (r *OAuthRepository) ConsumeLoginCode(ctx context.Context, codeHash string) (uint, error) {
+	userID, err := r.rdb.GetDel(ctx, loginCodeKey(codeHash)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, ErrLoginCodeInvalid
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uint(userID), nil
+}
+
+func (r *OAuthRepository) SaveState(ctx context.Context, state string, oauthState *OAuthState, ttl time.Duration) error {
+	data, err := json.Marshal(oauthState)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, oauthStateKey(state), data, ttl).Err()
+}
+
+// ConsumeState returns the stored state and deletes it, so each state can complete only one login.
+func (r *OAuthRepository) ConsumeState(ctx context.Context, state string) (*OAuthState, error) {
+	data, err := r.rdb.GetDel(ctx, oauthStateKey(state)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrOAuthStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var oauthState OAuthState
+	if err := json.Unmarshal(data, &oauthState); err != nil {
+		return nil, err
+	}
+	return &oauthState, nil
+}
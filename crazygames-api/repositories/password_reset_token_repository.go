@@ -1,83 +1,99 @@
-package repositories
-
-import (
-	"time"
-
-	"crazygames.io/entities"
-	"gorm.io/gorm"
-)
-
-type PasswordResetTokenRepository struct {
-	db *gorm.DB
-}
-
-type PasswordResetTokenRepositoryInterface interface {
-	Create(token *entities.PasswordResetToken) error
-	Update(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error)
-	GetByEmail(email string) (*entities.PasswordResetToken, error)
-	GetByToken(token string) (*entities.PasswordResetToken, error)
-	SetResetToken(email, token string, expiresAt time.Time) error
-	GetUserByResetToken(token string) (*entities.PasswordResetToken, error)
-	MarkTokenAsUsed(tokenID uint) error
-}
-
-func NewPasswordResetTokenRepository(db *gorm.DB) *PasswordResetTokenRepository {
-	return &PasswordResetTokenRepository{db: db}
-}
-
-func (r *PasswordResetTokenRepository) Create(token *entities.PasswordResetToken) error {
-	return r.db.Create(token).Error
-}
-
-func (r *PasswordResetTokenRepository) Update(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error) {
-	err := r.db.Save(token).Error
-	if err != nil {
-		return nil, err
-	}
-	return token, nil
-}
-
-func (r *PasswordResetTokenRepository) GetByEmail(email string) (*entities.PasswordResetToken, error) {
-	var resetToken entities.PasswordResetToken
-	err := r.db.Where("email = ?", email).First(&resetToken).Error
-	if err != nil {
-		return nil, err
-	}
-	return &resetToken, nil
-}
-
-func (r *PasswordResetTokenRepository) GetByToken(token string) (*entities.PasswordResetToken, error) {
-	var resetToken entities.PasswordResetToken
-	err := r.db.Where("token = ?", token).First(&resetToken).Error
-	if err != nil {
-		return nil, err
-	}
-	return &resetToken, nil
-}
-
-func (r *PasswordResetTokenRepository) SetResetToken(email, token string, expiresAt time.Time) error {
-	return r.db.Model(&entities.PasswordResetToken{}).
-		Where("email = ?", email).
-		Updates(map[string]interface{}{
-			"token":      token,
-			"expired_at": expiresAt,
-			"is_used":    false,
-		}).Error
-}
-
-func (r *PasswordResetTokenRepository) GetUserByResetToken(token string) (*entities.PasswordResetToken, error) {
-	var resetToken entities.PasswordResetToken
-	err := r.db.Where("token = ? AND expired_at > ? AND is_used = ?", token, time.Now(), false).
-		First(&resetToken).Error
-	if err != nil {
-		return nil, err
-	}
-	return &resetToken, nil
-}
-
-func (r *PasswordResetTokenRepository) MarkTokenAsUsed(tokenID uint) error {
-	return r.db.Model(&entities.PasswordResetToken{}).
-		Where("id = ?", tokenID).
-		Update("is_used", true).
-		Error
-}
+package repositories
+
+import (
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+type PasswordResetTokenRepository struct {
+	db *gorm.DB
+}
+
+type PasswordResetTokenRepositoryInterface interface {
+	Create(token *entities.PasswordResetToken) error
+	Update(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error)
+	GetByEmail(email string) (*entities.PasswordResetToken, error)
+	GetByTokenHash(tokenHash string) (*entities.PasswordResetToken, error)
+	SetResetToken(email, tokenHash string, expiresAt time.Time) error
+	GetUserByResetToken(tokenHash string) (*entities.PasswordResetToken, error)
+	MarkTokenAsUsed(tokenID uint) error
+	DeleteExpired(before time.Time) (int64, error)
+}
+
+func NewPasswordResetTokenRepository(db *gorm.DB) *PasswordResetTokenRepository {
+	return &PasswordResetTokenRepository{db: db}
+}
+
+func (r *PasswordResetTokenRepository) Create(token *entities.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *PasswordResetTokenRepository) Update(token *entities.PasswordResetToken) (*entities.PasswordResetToken, error) {
+	err := r.db.Save(token).Error
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (r *PasswordResetTokenRepository) GetByEmail(email string) (*entities.PasswordResetToken, error) {
+	var resetToken entities.PasswordResetToken
+	err := r.db.Where("email = ?", email).First(&resetToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resetToken, nil
+}
+
+func (r *PasswordResetTokenRepository) GetByTokenHash(tokenHash string) (*entities.PasswordResetToken, error) {
+	var resetToken entities.PasswordResetToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&resetToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resetToken, nil
+}
+
+// SetResetToken replaces the token of email, so the previous one stops working.
+func (r *PasswordResetTokenRepository) SetResetToken(email, tokenHash string, expiresAt time.Time) error {
+	return r.db.Model(&entities.PasswordResetToken{}).
+		Where("email = ?", email).
+		Updates(map[string]interface{}{
+			"token_hash": tokenHash,
+			"expired_at": expiresAt,
+			"is_used":    false,
+		}).Error
+}
+
+func (r *PasswordResetTokenRepository) GetUserByResetToken(tokenHash string) (*entities.PasswordResetToken, error) {
+	var resetToken entities.PasswordResetToken
+	err := r.db.Where("token_hash = ? AND expired_at > ? AND is_used = ?", tokenHash, time.Now(), false).
+		First(&resetToken).Error
+	if err != nil {
+		return nil, err
+	}
+	return &resetToken, nil
+}
+
+// MarkTokenAsUsed uses up the token, returning gorm.ErrRecordNotFound if it
+// was already used so that concurrent resets cannot both succeed.
+func (r *PasswordResetTokenRepository) MarkTokenAsUsed(tokenID uint) error {
+	result := r.db.Model(&entities.PasswordResetToken{}).
+		Where("id = ? AND is_used = ?", tokenID, false).
+		Update("is_used", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteExpired removes the tokens that expired before before.
+func (r *PasswordResetTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expired_at < ?", before).Delete(&entities.PasswordResetToken{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"crazygames.io/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Plays are counted in Redis and added to games.play_count in batches, so that
+// popular games do not contend for their row on every play:
+//
+//	play_counts              hash game ID -> plays not yet flushed
+//	play_counts:flushing     the hash being flushed, renamed from play_counts
+//	play_counts:flushing_id  the ID of that flush, for MySQL to add it once
+//	play_counts:lock         the token of the instance flushing
+type PlayCountRepository struct {
+	rdb *redis.Client
+}
+
+type PlayCountRepositoryInterface interface {
+	Increment(ctx context.Context, gameID uint) error
+	LockFlush(ctx context.Context, ttl time.Duration) (string, error)
+	UnlockFlush(ctx context.Context, token string) error
+	TakePending(ctx context.Context) (string, map[uint]int64, error)
+	ClearFlushed(ctx context.Context) error
+}
+
+const (
+	playCountsKey           = "play_counts"
+	playCountsFlushingKey   = "play_counts:flushing"
+	playCountsFlushingIDKey = "play_counts:flushing_id"
+	playCountsLockKey       = "play_counts:lock"
+)
+
+// takePendingScript moves the pending counts aside with a new flush ID unless
+// a previous flush left some there, and returns the flush ID followed by the
+// counts to flush.
+var takePendingScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	if redis.call('EXISTS', KEYS[1]) == 0 then
+		return {}
+	end
+	redis.call('RENAME', KEYS[1], KEYS[2])
+	redis.call('SET', KEYS[3], ARGV[1])
+end
+local id = redis.call('GET', KEYS[3])
+if not id then
+	id = ARGV[1]
+	redis.call('SET', KEYS[3], id)
+end
+local result = redis.call('HGETALL', KEYS[2])
+table.insert(result, 1, id)
+return result
+`)
+
+// unlockScript deletes the lock only if it still holds the token, as it may
+// have expired and been taken by another instance meanwhile.
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func NewPlayCountRepository(rdb *redis.Client) *PlayCountRepository {
+	return &PlayCountRepository{rdb: rdb}
+}
+
+func (r *PlayCountRepository) Increment(ctx context.Context, gameID uint) error {
+	return r.rdb.HIncrBy(ctx, playCountsKey, strconv.FormatUint(uint64(gameID), 10), 1).Err()
+}
+
+// LockFlush makes this instance the only one flushing for at most ttl. It
+// returns the token to unlock with, or "" if another instance is flushing.
+func (r *PlayCountRepository) LockFlush(ctx context.Context, ttl time.Duration) (string, error) {
+	token, err := utils.RandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	locked, err := r.rdb.SetNX(ctx, playCountsLockKey, token, ttl).Result()
+	if err != nil || !locked {
+		return "", err
+	}
+	return token, nil
+}
+
+// UnlockFlush releases the lock taken with the token, if it still holds it.
+func (r *PlayCountRepository) UnlockFlush(ctx context.Context, token string) error {
+	return unlockScript.Run(ctx, r.rdb, []string{playCountsLockKey}, token).Err()
+}
+
+// TakePending returns the ID of the flush and the plays counted since the last
+// one by game ID. They are returned again with the same ID until ClearFlushed,
+// so that a failed flush is retried and added once.
+func (r *PlayCountRepository) TakePending(ctx context.Context) (string, map[uint]int64, error) {
+	flushID, err := utils.RandomToken(16)
+	if err != nil {
+		return "", nil, err
+	}
+	keys := []string{playCountsKey, playCountsFlushingKey, playCountsFlushingIDKey}
+	values, err := takePendingScript.Run(ctx, r.rdb, keys, flushID).StringSlice()
+	if err != nil || len(values) == 0 {
+		return "", nil, err
+	}
+
+	counts := make(map[uint]int64, len(values)/2)
+	for i := 1; i+1 < len(values); i += 2 {
+		gameID, err := strconv.ParseUint(values[i], 10, 32)
+		if err != nil {
+			return "", nil, err
+		}
+		count, err := strconv.ParseInt(values[i+1], 10, 64)
+		if err != nil {
+			return "", nil, err
+		}
+		counts[uint(gameID)] = count
+	}
+	return values[0], counts, nil
+}
+
+// ClearFlushed forgets the counts of TakePending once they are stored.
+func (r *PlayCountRepository) ClearFlushed(ctx context.Context) error {
+	return r.rdb.Del(ctx, playCountsFlushingKey, playCountsFlushingIDKey).Err()
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PlayCounts(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	t.Run("nothing pending should take nothing", func(t *testing.T) {
+		_, counts, err := playCountRepository.TakePending(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, counts)
+	})
+
+	playCountRepository.Increment(ctx, 1)
+	playCountRepository.Increment(ctx, 1)
+	playCountRepository.Increment(ctx, 2)
+
+	t.Run("take should return the pending counts until cleared", func(t *testing.T) {
+		flushID, counts, err := playCountRepository.TakePending(ctx)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, flushID)
+		assert.Equal(t, map[uint]int64{1: 2, 2: 1}, counts)
+
+		playCountRepository.Increment(ctx, 1)
+		retryID, counts, _ := playCountRepository.TakePending(ctx)
+		assert.Equal(t, flushID, retryID, "a failed flush should be retried with its ID")
+		assert.Equal(t, map[uint]int64{1: 2, 2: 1}, counts, "a failed flush should be retried as is")
+
+		assert.NoError(t, playCountRepository.ClearFlushed(ctx))
+		nextID, counts, _ := playCountRepository.TakePending(ctx)
+		assert.NotEqual(t, flushID, nextID)
+		assert.Equal(t, map[uint]int64{1: 1}, counts)
+	})
+
+	t.Run("flush should be locked to one instance", func(t *testing.T) {
+		token, err := playCountRepository.LockFlush(ctx, time.Minute)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, token)
+
+		other, _ := playCountRepository.LockFlush(ctx, time.Minute)
+		assert.Empty(t, other)
+
+		assert.NoError(t, playCountRepository.UnlockFlush(ctx, "expired"))
+		other, _ = playCountRepository.LockFlush(ctx, time.Minute)
+		assert.Empty(t, other, "only the holder should unlock")
+
+		assert.NoError(t, playCountRepository.UnlockFlush(ctx, token))
+		token, _ = playCountRepository.LockFlush(ctx, time.Minute)
+		assert.NotEmpty(t, token)
+	})
+}
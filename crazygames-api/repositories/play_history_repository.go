@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecentlyPlayedGame is a game with when the user last played it.
+type RecentlyPlayedGame struct {
+	entities.Game
+	LastPlayedAt time.Time
+}
+
+// PlaytimeStats sums up the ended play sessions of a user in a game.
+type PlaytimeStats struct {
+	GameID         uint    `json:"game_id"`
+	Sessions       int64   `json:"sessions"`
+	TotalSeconds   int64   `json:"total_seconds"`
+	AverageSeconds float64 `json:"average_seconds"`
+}
+
+type PlayHistoryRepository struct {
+	db *gorm.DB
+}
+
+type PlayHistoryRepositoryInterface interface {
+	Create(history *entities.PlayHistory) error
+	GetRecentlyPlayed(userID uint, limit int) ([]RecentlyPlayedGame, error)
+	GetSession(gameID uint, sessionID string) (*entities.PlayHistory, error)
+	Extend(history *entities.PlayHistory) error
+	End(history *entities.PlayHistory) error
+	EndStaleSessions(before time.Time) (int64, error)
+	GetUserPlaytime(userID uint) ([]PlaytimeStats, error)
+	EachPlaySince(since time.Time, fn func(history *entities.PlayHistory) error) error
+}
+
+func NewPlayHistoryRepository(db *gorm.DB) *PlayHistoryRepository {
+	return &PlayHistoryRepository{db: db}
+}
+
+func (r *PlayHistoryRepository) Create(history *entities.PlayHistory) error {
+	return r.db.Create(history).Error
+}
+
+// GetRecentlyPlayed returns the games the user played, each once, last played
+// first.
+func (r *PlayHistoryRepository) GetRecentlyPlayed(userID uint, limit int) ([]RecentlyPlayedGame, error) {
+	var games []RecentlyPlayedGame
+	err := r.db.Table("play_histories").
+		Select("games.*, MAX(play_histories.date_played) AS last_played_at").
+		Joins("JOIN games ON games.id = play_histories.game_id").
+		Where("play_histories.user_id = ?", userID).
+		Group("games.id").
+		Order("last_played_at DESC").
+		Limit(limit).
+		Scan(&games).Error
+	return games, err
+}
+
+// GetSession returns the play session of the game, or nil if there is none.
+func (r *PlayHistoryRepository) GetSession(gameID uint, sessionID string) (*entities.PlayHistory, error) {
+	var histories []entities.PlayHistory
+	err := r.db.Where("session_id = ? AND game_id = ?", sessionID, gameID).Limit(1).Find(&histories).Error
+	if err != nil || len(histories) == 0 {
+		return nil, err
+	}
+	return &histories[0], nil
+}
+
+// Extend saves the heartbeat of the session, unless it has ended meanwhile.
+func (r *PlayHistoryRepository) Extend(history *entities.PlayHistory) error {
+	return r.db.Model(history).Where("ended_at IS NULL").
+		Select("last_heartbeat_at", "duration_seconds", "updated_at").Updates(history).Error
+}
+
+// End ends the session and adds it to the playtime of its game. A session
+// ended twice at the same time is only added once.
+func (r *PlayHistoryRepository) End(history *entities.PlayHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(history).Where("ended_at IS NULL").
+			Select("ended_at", "duration_seconds", "updated_at").Updates(history)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return addPlaytime(tx, history.GameID, 1, history.DurationSeconds)
+	})
+}
+
+// EndStaleSessions ends the sessions without a heartbeat since before at their
+// last heartbeat, adds them to the playtime of their games, and returns how
+// many there were.
+func (r *PlayHistoryRepository) EndStaleSessions(before time.Time) (int64, error) {
+	var ended int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var stale []entities.PlayHistory
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "game_id", "duration_seconds").
+			Where("ended_at IS NULL AND session_id <> '' AND COALESCE(last_heartbeat_at, date_played) < ?", before).
+			Find(&stale).Error
+		if err != nil || len(stale) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(stale))
+		sessions := make(map[uint]int64)
+		seconds := make(map[uint]int64)
+		for i, history := range stale {
+			ids[i] = history.ID
+			sessions[history.GameID]++
+			seconds[history.GameID] += history.DurationSeconds
+		}
+		result := tx.Model(&entities.PlayHistory{}).Where("id IN ?", ids).
+			Update("ended_at", gorm.Expr("COALESCE(last_heartbeat_at, date_played)"))
+		if result.Error != nil {
+			return result.Error
+		}
+		ended = result.RowsAffected
+
+		for gameID, count := range sessions {
+			if err := addPlaytime(tx, gameID, count, seconds[gameID]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ended, err
+}
+
+// addPlaytime adds ended sessions to the playtime kept on the game, so that
+// reading games does not sum up their play history.
+func addPlaytime(tx *gorm.DB, gameID uint, sessions int64, seconds int64) error {
+	return tx.Model(&entities.Game{}).Where("id = ?", gameID).UpdateColumns(map[string]interface{}{
+		"session_count":      gorm.Expr("session_count + ?", sessions),
+		"total_play_seconds": gorm.Expr("total_play_seconds + ?", seconds),
+	}).Error
+}
+
+// GetUserPlaytime returns the playtime of the user in each game they played,
+// most played first.
+func (r *PlayHistoryRepository) GetUserPlaytime(userID uint) ([]PlaytimeStats, error) {
+	var stats []PlaytimeStats
+	err := r.playtime().Where("user_id = ?", userID).Order("total_seconds DESC").Scan(&stats).Error
+	return stats, err
+}
+
+// EachPlaySince calls fn with the game and date of each play since the given
+// time, without loading them all at once.
+func (r *PlayHistoryRepository) EachPlaySince(since time.Time, fn func(history *entities.PlayHistory) error) error {
+	rows, err := r.db.Model(&entities.PlayHistory{}).Select("game_id, date_played").
+		Where("date_played >= ?", since).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var history entities.PlayHistory
+		if err := r.db.ScanRows(rows, &history); err != nil {
+			return err
+		}
+		if err := fn(&history); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *PlayHistoryRepository) playtime() *gorm.DB {
+	return r.db.Model(&entities.PlayHistory{}).
+		Select("game_id, COUNT(*) AS sessions, SUM(duration_seconds) AS total_seconds, AVG(duration_seconds) AS average_seconds").
+		Where("ended_at IS NOT NULL").
+		Group("game_id")
+}
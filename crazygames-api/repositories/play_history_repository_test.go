@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecentlyPlayed(t *testing.T) {
+	db.Exec("DELETE FROM play_histories")
+	db.Exec("DELETE FROM games")
+
+	first := &entities.Game{GameTitle: "First", GameURL: "http://first.com"}
+	second := &entities.Game{GameTitle: "Second", GameURL: "http://second.com"}
+	db.Create(first)
+	db.Create(second)
+	userID, otherUserID := uint(1), uint(2)
+
+	now := time.Now()
+	plays := []struct {
+		userID *uint
+		gameID uint
+		at     time.Time
+	}{
+		{&userID, first.ID, now.Add(-3 * time.Hour)},
+		{&userID, second.ID, now.Add(-2 * time.Hour)},
+		{&userID, first.ID, now.Add(-time.Hour)},
+		{&otherUserID, second.ID, now},
+		{nil, second.ID, now},
+	}
+	for _, play := range plays {
+		at := play.at
+		err := playHistoryRepository.Create(&entities.PlayHistory{UserID: play.userID, GameID: play.gameID, DatePlayed: &at})
+		assert.NoError(t, err, "failed to create play for test")
+	}
+
+	t.Run("games should be listed once, last played first", func(t *testing.T) {
+		games, err := playHistoryRepository.GetRecentlyPlayed(userID, 10)
+		assert.NoError(t, err)
+		assert.Len(t, games, 2)
+		assert.Equal(t, first.ID, games[0].ID)
+		assert.Equal(t, "First", games[0].GameTitle)
+		assert.WithinDuration(t, now.Add(-time.Hour), games[0].LastPlayedAt, time.Second)
+		assert.Equal(t, second.ID, games[1].ID)
+	})
+
+	t.Run("limit should apply to distinct games", func(t *testing.T) {
+		games, err := playHistoryRepository.GetRecentlyPlayed(userID, 1)
+		assert.NoError(t, err)
+		assert.Len(t, games, 1)
+		assert.Equal(t, first.ID, games[0].ID)
+	})
+}
+
+func Test_PlaySessions(t *testing.T) {
+	db.Exec("DELETE FROM play_histories")
+	userID := uint(1)
+	now := time.Now()
+	startedAt, heartbeatAt := now.Add(-10*time.Minute), now.Add(-8*time.Minute)
+
+	stale := &entities.PlayHistory{UserID: &userID, GameID: 1, SessionID: "stale", DatePlayed: &startedAt, LastHeartbeatAt: &heartbeatAt, DurationSeconds: 120}
+	live := &entities.PlayHistory{UserID: &userID, GameID: 1, SessionID: "live", DatePlayed: &now}
+	ended := &entities.PlayHistory{UserID: &userID, GameID: 2, SessionID: "ended", DatePlayed: &startedAt, EndedAt: &heartbeatAt, DurationSeconds: 60}
+	legacy := &entities.PlayHistory{UserID: &userID, GameID: 2, DatePlayed: &startedAt}
+	for _, history := range []*entities.PlayHistory{stale, live, ended, legacy} {
+		assert.NoError(t, playHistoryRepository.Create(history), "failed to create play for test")
+	}
+
+	t.Run("session should be found by game and ID", func(t *testing.T) {
+		found, err := playHistoryRepository.GetSession(1, "live")
+		assert.NoError(t, err)
+		assert.Equal(t, live.ID, found.ID)
+
+		found, err = playHistoryRepository.GetSession(2, "live")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("stale sessions should end at their last heartbeat", func(t *testing.T) {
+		count, err := playHistoryRepository.EndStaleSessions(now.Add(-time.Minute))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		found, _ := playHistoryRepository.GetSession(1, "stale")
+		assert.WithinDuration(t, heartbeatAt, *found.EndedAt, time.Second)
+		found, _ = playHistoryRepository.GetSession(1, "live")
+		assert.Nil(t, found.EndedAt)
+	})
+
+	t.Run("playtime should only count ended sessions", func(t *testing.T) {
+		stats, err := playHistoryRepository.GetUserPlaytime(userID)
+		assert.NoError(t, err)
+		assert.Len(t, stats, 2)
+		assert.Equal(t, uint(1), stats[0].GameID, "most played game should be first")
+		assert.Equal(t, int64(1), stats[0].Sessions)
+		assert.Equal(t, int64(120), stats[0].TotalSeconds)
+		assert.Equal(t, float64(60), stats[1].AverageSeconds)
+	})
+	t.Run("ended sessions should add up on the game", func(t *testing.T) {
+		db.Exec("DELETE FROM games")
+		game := &entities.Game{GameTitle: "Played", GameURL: "http://played.com"}
+		db.Create(game)
+
+		first := &entities.PlayHistory{GameID: game.ID, SessionID: "first", DatePlayed: &startedAt, LastHeartbeatAt: &heartbeatAt, DurationSeconds: 120}
+		second := &entities.PlayHistory{GameID: game.ID, SessionID: "second", DatePlayed: &startedAt}
+		assert.NoError(t, playHistoryRepository.Create(first), "failed to create play for test")
+		assert.NoError(t, playHistoryRepository.Create(second), "failed to create play for test")
+
+		second.EndedAt, second.DurationSeconds = &now, 600
+		assert.NoError(t, playHistoryRepository.End(second))
+		assert.NoError(t, playHistoryRepository.End(second))
+		_, err := playHistoryRepository.EndStaleSessions(now.Add(-time.Minute))
+		assert.NoError(t, err)
+
+		var stored entities.Game
+		assert.NoError(t, db.First(&stored, game.ID).Error)
+		assert.Equal(t, int64(2), stored.SessionCount)
+		assert.Equal(t, int64(720), stored.TotalPlaySeconds)
+		assert.Equal(t, float64(360), stored.AverageSessionSeconds)
+	})
+}
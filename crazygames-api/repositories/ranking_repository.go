@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Games are ranked in Redis sorted sets of game IDs, kept up to date on every
+// play:
+//
+//	rankings:trending            log2 of the plays, each weighed by its age
+//	rankings:plays:<yyyymmddhh>  plays in the hour (UTC), kept for a week
+//	rankings:plays:all           plays of all time
+//	rankings:popular:<window>    plays in the hours of the window, cached
+type RankingRepository struct {
+	rdb *redis.Client
+}
+
+type RankingRepositoryInterface interface {
+	RecordPlay(ctx context.Context, gameID uint, at time.Time, trendingScore float64) error
+	GetTrending(ctx context.Context, limit int) ([]uint, error)
+	GetPopular(ctx context.Context, window string, at time.Time, limit int) ([]uint, error)
+	Replace(ctx context.Context, rankings *Rankings) error
+}
+
+const (
+	RankingWindowDay  = "day"
+	RankingWindowWeek = "week"
+	RankingWindowAll  = "all"
+)
+
+// rankingWindowHours is how many hours of plays each window adds up.
+var rankingWindowHours = map[string]int{
+	RankingWindowDay:  24,
+	RankingWindowWeek: 7 * 24,
+}
+
+// RankingHistory is how far back the rankings other than all time look.
+const RankingHistory = 7 * 24 * time.Hour
+
+const (
+	trendingKey      = "rankings:trending"
+	allTimePlaysKey  = "rankings:plays:all"
+	popularCacheTTL  = time.Minute
+	hourlyPlaysTTL   = RankingHistory + time.Hour
+	rankingChunkSize = 1000
+)
+
+// Rankings are all the scores by game ID, to replace the ones in Redis.
+type Rankings struct {
+	Trending     map[uint]float64
+	HourlyPlays  map[time.Time]map[uint]int64
+	AllTimePlays map[uint]int64
+	// Since is the first hour of HourlyPlays; the hours after it that have no
+	// plays are cleared.
+	Since time.Time
+}
+
+// recordPlayScript adds a play to the rankings. The trending score is the log2
+// of a sum of powers of two, so the play's own score is added to it in log
+// space: log2(2^a + 2^b) = max + log2(1 + 2^(min - max)).
+var recordPlayScript = redis.NewScript(`
+local score = tonumber(ARGV[2])
+local current = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if current then
+	current = tonumber(current)
+	local high, low = math.max(current, score), math.min(current, score)
+	score = high + math.log(1 + 2 ^ (low - high)) / math.log(2)
+end
+redis.call('ZADD', KEYS[1], score, ARGV[1])
+redis.call('ZINCRBY', KEYS[2], 1, ARGV[1])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('ZINCRBY', KEYS[3], 1, ARGV[1])
+return 1
+`)
+
+func NewRankingRepository(rdb *redis.Client) *RankingRepository {
+	return &RankingRepository{rdb: rdb}
+}
+
+func hourlyPlaysKey(hour time.Time) string {
+	return "rankings:plays:" + hour.UTC().Format("2006010215")
+}
+
+func popularKey(window string) string {
+	return "rankings:popular:" + window
+}
+
+func (r *RankingRepository) RecordPlay(ctx context.Context, gameID uint, at time.Time, trendingScore float64) error {
+	keys := []string{trendingKey, hourlyPlaysKey(at), allTimePlaysKey}
+	return recordPlayScript.Run(ctx, r.rdb, keys, gameID, trendingScore, int(hourlyPlaysTTL.Seconds())).Err()
+}
+
+func (r *RankingRepository) GetTrending(ctx context.Context, limit int) ([]uint, error) {
+	return r.top(ctx, trendingKey, limit)
+}
+
+// GetPopular returns the most played games of the window up to at. The plays
+// of the hours of the window are added up at most once per popularCacheTTL.
+func (r *RankingRepository) GetPopular(ctx context.Context, window string, at time.Time, limit int) ([]uint, error) {
+	if window == RankingWindowAll {
+		return r.top(ctx, allTimePlaysKey, limit)
+	}
+
+	key := popularKey(window)
+	cached, err := r.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	if cached == 0 {
+		hours := make([]string, rankingWindowHours[window])
+		for i := range hours {
+			hours[i] = hourlyPlaysKey(at.Add(-time.Duration(i) * time.Hour))
+		}
+		_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: hours})
+			pipe.Expire(ctx, key, popularCacheTTL)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.top(ctx, key, limit)
+}
+
+func (r *RankingRepository) top(ctx context.Context, key string, limit int) ([]uint, error) {
+	members, err := r.rdb.ZRevRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, len(members))
+	for i, member := range members {
+		id, err := strconv.ParseUint(member, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = uint(id)
+	}
+	return ids, nil
+}
+
+// Replace swaps all the rankings for the given ones at once.
+func (r *RankingRepository) Replace(ctx context.Context, rankings *Rankings) error {
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, trendingKey, allTimePlaysKey, popularKey(RankingWindowDay), popularKey(RankingWindowWeek))
+		for hour := rankings.Since.Truncate(time.Hour); !hour.After(time.Now()); hour = hour.Add(time.Hour) {
+			pipe.Del(ctx, hourlyPlaysKey(hour))
+		}
+
+		addScores(ctx, pipe, trendingKey, rankings.Trending)
+		addScores(ctx, pipe, allTimePlaysKey, rankings.AllTimePlays)
+		for hour, plays := range rankings.HourlyPlays {
+			addScores(ctx, pipe, hourlyPlaysKey(hour), plays)
+			pipe.ExpireAt(ctx, hourlyPlaysKey(hour), hour.Add(hourlyPlaysTTL))
+		}
+		return nil
+	})
+	return err
+}
+
+func addScores[S int64 | float64](ctx context.Context, pipe redis.Pipeliner, key string, scores map[uint]S) {
+	members := make([]redis.Z, 0, rankingChunkSize)
+	for gameID, score := range scores {
+		members = append(members, redis.Z{Score: float64(score), Member: gameID})
+		if len(members) == rankingChunkSize {
+			pipe.ZAdd(ctx, key, members...)
+			members = members[:0]
+		}
+	}
+	if len(members) > 0 {
+		pipe.ZAdd(ctx, key, members...)
+	}
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Rankings(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+	now := time.Now()
+	twoDaysAgo := now.Add(-48 * time.Hour)
+
+	// Game 1 was played twice two days ago, game 2 once now.
+	assert.NoError(t, rankingRepository.RecordPlay(ctx, 1, twoDaysAgo, 10))
+	assert.NoError(t, rankingRepository.RecordPlay(ctx, 1, twoDaysAgo, 10))
+	assert.NoError(t, rankingRepository.RecordPlay(ctx, 2, now, 10.5))
+
+	t.Run("trending scores should add up in log space", func(t *testing.T) {
+		score, err := rdb.ZScore(ctx, trendingKey, "1").Result()
+		assert.NoError(t, err)
+		assert.InDelta(t, 11, score, 1e-9)
+
+		ids, err := rankingRepository.GetTrending(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, ids)
+	})
+
+	t.Run("popular should only count the plays of the window", func(t *testing.T) {
+		ids, err := rankingRepository.GetPopular(ctx, RankingWindowDay, now, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{2}, ids)
+
+		ids, err = rankingRepository.GetPopular(ctx, RankingWindowWeek, now, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, ids)
+
+		ids, err = rankingRepository.GetPopular(ctx, RankingWindowAll, now, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{1}, ids)
+	})
+
+	t.Run("replace should drop the previous rankings", func(t *testing.T) {
+		hour := now.UTC().Truncate(time.Hour)
+		err := rankingRepository.Replace(ctx, &Rankings{
+			Trending:     map[uint]float64{3: 1},
+			HourlyPlays:  map[time.Time]map[uint]int64{hour: {3: 4}},
+			AllTimePlays: map[uint]int64{3: 4},
+			Since:        now.Add(-RankingHistory),
+		})
+		assert.NoError(t, err)
+
+		ids, _ := rankingRepository.GetTrending(ctx, 10)
+		assert.Equal(t, []uint{3}, ids)
+		ids, _ = rankingRepository.GetPopular(ctx, RankingWindowWeek, now, 10)
+		assert.Equal(t, []uint{3}, ids, "cached popular games should be dropped")
+		ids, _ = rankingRepository.GetPopular(ctx, RankingWindowAll, now, 10)
+		assert.Equal(t, []uint{3}, ids)
+	})
+}
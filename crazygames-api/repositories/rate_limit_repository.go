@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"crazygames.io/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// Rate limits count requests in a sliding window, lockouts protect a single
+// account from password guessing:
+//
+//	rate_limit:<key>         sorted set of request IDs scored by time in ms
+//	login_failures:<account> failed logins since the last success
+//	login_lock:<account>     set while the account is locked, expires with the lock
+type RateLimitRepository struct {
+	rdb *redis.Client
+}
+
+type RateLimitRepositoryInterface interface {
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error)
+	RecordFailure(ctx context.Context, account string, ttl time.Duration) (int64, error)
+	Lock(ctx context.Context, account string, duration time.Duration) error
+	LockedFor(ctx context.Context, account string) (time.Duration, error)
+	Unlock(ctx context.Context, account string) error
+}
+
+// slidingWindowScript drops requests older than the window and records this
+// one if fewer than the limit remain. Returns {1, 0} when allowed and {0, ms}
+// with the time until the oldest request leaves the window otherwise.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) < tonumber(ARGV[3]) then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return {1, 0}
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return {0, tonumber(oldest[2]) + window - now}
+`)
+
+func NewRateLimitRepository(rdb *redis.Client) *RateLimitRepository {
+	return &RateLimitRepository{rdb: rdb}
+}
+
+func rateLimitKey(key string) string {
+	return "rate_limit:" + key
+}
+
+func loginFailuresKey(account string) string {
+	return "login_failures:" + strings.ToLower(account)
+}
+
+func loginLockKey(account string) string {
+	return "login_lock:" + strings.ToLower(account)
+}
+
+// Allow records a request for key and reports whether it is within limit
+// requests per window. When it is not, it also returns how long until the
+// next request would be allowed.
+func (r *RateLimitRepository) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	requestID, err := utils.RandomToken(8)
+	if err != nil {
+		return false, 0, err
+	}
+
+	now := time.Now().UnixMilli()
+	result, err := slidingWindowScript.Run(ctx, r.rdb, []string{rateLimitKey(key)}, now, window.Milliseconds(), limit, strconv.FormatInt(now, 10)+"-"+requestID).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+
+	return result[0] == 1, time.Duration(result[1]) * time.Millisecond, nil
+}
+
+// RecordFailure counts a failed login for account and returns the number of
+// failures. The count is forgotten ttl after the last failure.
+func (r *RateLimitRepository) RecordFailure(ctx context.Context, account string, ttl time.Duration) (int64, error) {
+	var failures *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.Incr(ctx, loginFailuresKey(account))
+		pipe.Expire(ctx, loginFailuresKey(account), ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return failures.Val(), nil
+}
+
+func (r *RateLimitRepository) Lock(ctx context.Context, account string, duration time.Duration) error {
+	return r.rdb.Set(ctx, loginLockKey(account), 1, duration).Err()
+}
+
+// LockedFor returns how long account stays locked, zero if it is not.
+func (r *RateLimitRepository) LockedFor(ctx context.Context, account string) (time.Duration, error) {
+	ttl, err := r.rdb.PTTL(ctx, loginLockKey(account)).Result()
+	if errors.Is(err, redis.Nil) || ttl < 0 {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// Unlock lifts the lock of account and forgets its failed logins.
+func (r *RateLimitRepository) Unlock(ctx context.Context, account string) error {
+	return r.rdb.Del(ctx, loginLockKey(account), loginFailuresKey(account)).Err()
+}
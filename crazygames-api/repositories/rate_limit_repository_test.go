@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimitAllow(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	t.Run("requests within the limit should be allowed", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			allowed, _, err := rateLimitRepository.Allow(ctx, "login:ip:127.0.0.1", 3, time.Minute)
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+		}
+	})
+
+	t.Run("request over the limit should be refused with a retry time", func(t *testing.T) {
+		allowed, retryAfter, err := rateLimitRepository.Allow(ctx, "login:ip:127.0.0.1", 3, time.Minute)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Greater(t, retryAfter, time.Duration(0))
+		assert.LessOrEqual(t, retryAfter, time.Minute)
+	})
+
+	t.Run("other keys should have their own limit", func(t *testing.T) {
+		allowed, _, _ := rateLimitRepository.Allow(ctx, "login:ip:127.0.0.2", 3, time.Minute)
+		assert.True(t, allowed)
+	})
+
+	t.Run("requests should leave the window", func(t *testing.T) {
+		rateLimitRepository.Allow(ctx, "short", 1, 100*time.Millisecond)
+		allowed, _, _ := rateLimitRepository.Allow(ctx, "short", 1, 100*time.Millisecond)
+		assert.False(t, allowed)
+
+		time.Sleep(150 * time.Millisecond)
+		allowed, _, _ = rateLimitRepository.Allow(ctx, "short", 1, 100*time.Millisecond)
+		assert.True(t, allowed)
+	})
+}
+
+func Test_LoginLockout(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	t.Run("failures should be counted per account", func(t *testing.T) {
+		failures, _ := rateLimitRepository.RecordFailure(ctx, "player@gmail.com", time.Hour)
+		assert.Equal(t, int64(1), failures)
+		failures, _ = rateLimitRepository.RecordFailure(ctx, "Player@gmail.com", time.Hour)
+		assert.Equal(t, int64(2), failures)
+	})
+
+	t.Run("locked account should report the remaining time", func(t *testing.T) {
+		lockedFor, _ := rateLimitRepository.LockedFor(ctx, "player@gmail.com")
+		assert.Zero(t, lockedFor)
+
+		err := rateLimitRepository.Lock(ctx, "player@gmail.com", time.Minute)
+		assert.NoError(t, err)
+
+		lockedFor, _ = rateLimitRepository.LockedFor(ctx, "player@gmail.com")
+		assert.Greater(t, lockedFor, time.Duration(0))
+	})
+
+	t.Run("unlock should lift the lock and forget the failures", func(t *testing.T) {
+		err := rateLimitRepository.Unlock(ctx, "player@gmail.com")
+		assert.NoError(t, err)
+
+		lockedFor, _ := rateLimitRepository.LockedFor(ctx, "player@gmail.com")
+		assert.Zero(t, lockedFor)
+		failures, _ := rateLimitRepository.RecordFailure(ctx, "player@gmail.com", time.Hour)
+		assert.Equal(t, int64(1), failures)
+	})
+}
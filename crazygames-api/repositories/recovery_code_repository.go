@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+type RecoveryCodeRepositoryInterface interface {
+	Replace(userID uint, codeHashes []string) error
+	Use(userID uint, codeHash string) (bool, error)
+	DeleteByUserID(userID uint) error
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+// Replace swaps all recovery codes of the user for codeHashes.
+func (r *RecoveryCodeRepository) Replace(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]entities.RecoveryCode, len(codeHashes))
+		for i, codeHash := range codeHashes {
+			codes[i] = entities.RecoveryCode{UserID: userID, CodeHash: codeHash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use marks the unused code of the user with codeHash as used and reports
+// whether there was one.
+func (r *RecoveryCodeRepository) Use(userID uint, codeHash string) (bool, error) {
+	result := r.db.Model(&entities.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByUserID(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&entities.RecoveryCode{}).Error
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"testing"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecoveryCodes(t *testing.T) {
+	db.Exec("DELETE FROM recovery_codes")
+	db.Exec("DELETE FROM users")
+
+	user, err := createUser("mfauser", "mfauser@gmail.com", "password", "admin")
+	assert.NoError(t, err, "failed to create user for test")
+
+	err = recoveryCodeRepository.Replace(user.ID, []string{"hash1", "hash2"})
+	assert.NoError(t, err, "failed to create recovery codes")
+
+	t.Run("use unused code should succeed once", func(t *testing.T) {
+		used, err := recoveryCodeRepository.Use(user.ID, "hash1")
+		assert.NoError(t, err)
+		assert.True(t, used)
+
+		used, _ = recoveryCodeRepository.Use(user.ID, "hash1")
+		assert.False(t, used)
+	})
+
+	t.Run("use code of another user should fail", func(t *testing.T) {
+		used, _ := recoveryCodeRepository.Use(user.ID+1, "hash2")
+		assert.False(t, used)
+	})
+
+	t.Run("replace should drop the old codes", func(t *testing.T) {
+		err := recoveryCodeRepository.Replace(user.ID, []string{"hash3"})
+		assert.NoError(t, err)
+
+		used, _ := recoveryCodeRepository.Use(user.ID, "hash2")
+		assert.False(t, used)
+
+		var count int64
+		db.Model(&entities.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+}
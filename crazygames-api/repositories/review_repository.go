@@ -0,0 +1,153 @@
+package repositories
+
+import (
+	"context"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GameReview is a review with the username of its author, empty once they
+// deleted their account.
+type GameReview struct {
+	entities.Review
+	Username string
+}
+
+// RatingPrior is what the game ratings start from: Weight reviews of Mean
+// stars, so that a few reviews only move the rating so far.
+type RatingPrior struct {
+	Mean   float64
+	Weight float64
+}
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+type ReviewRepositoryInterface interface {
+	GetByID(id uint) (*entities.Review, error)
+	GetByGameAndUser(gameID uint, userID uint) (*entities.Review, error)
+	GetByGame(gameID uint, query request.ReviewsRequestQuery) ([]GameReview, int64, error)
+	Create(ctx context.Context, review *entities.Review, prior RatingPrior) error
+	Update(ctx context.Context, review *entities.Review, prior RatingPrior) error
+	Delete(ctx context.Context, review *entities.Review, prior RatingPrior) error
+	AddVote(reviewID uint, userID uint) error
+	RemoveVote(reviewID uint, userID uint) error
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// GetByID returns the review, or nil if there is none.
+func (r *ReviewRepository) GetByID(id uint) (*entities.Review, error) {
+	var reviews []entities.Review
+	err := r.db.Where("id = ?", id).Limit(1).Find(&reviews).Error
+	if err != nil || len(reviews) == 0 {
+		return nil, err
+	}
+	return &reviews[0], nil
+}
+
+// GetByGameAndUser returns the review of the game by the user, or nil if they
+// did not review it.
+func (r *ReviewRepository) GetByGameAndUser(gameID uint, userID uint) (*entities.Review, error) {
+	var reviews []entities.Review
+	err := r.db.Where("game_id = ? AND user_id = ?", gameID, userID).Limit(1).Find(&reviews).Error
+	if err != nil || len(reviews) == 0 {
+		return nil, err
+	}
+	return &reviews[0], nil
+}
+
+func (r *ReviewRepository) GetByGame(gameID uint, queryParams request.ReviewsRequestQuery) ([]GameReview, int64, error) {
+	var reviews []GameReview
+	var total int64
+
+	query := r.db.Model(&entities.Review{}).Where("reviews.game_id = ?", gameID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "reviews.created_at DESC, reviews.id DESC"
+	if queryParams.Sort == request.ReviewSortHelpful {
+		order = "reviews.helpful_count DESC, " + order
+	}
+	err := query.Select("reviews.*, COALESCE(users.username, '') AS username").
+		Joins("LEFT JOIN users ON users.id = reviews.user_id").
+		Order(order).
+		Offset(queryParams.PageSize * (queryParams.PageNumber - 1)).
+		Limit(queryParams.PageSize).
+		Scan(&reviews).Error
+	return reviews, total, err
+}
+
+func (r *ReviewRepository) Create(ctx context.Context, review *entities.Review, prior RatingPrior) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		return updateGameRating(tx, review.GameID, prior)
+	})
+}
+
+// Update saves the rating and comment of the review, leaving its helpful
+// count to the votes.
+func (r *ReviewRepository) Update(ctx context.Context, review *entities.Review, prior RatingPrior) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(review).Select("rating", "comment", "updated_at").Updates(review).Error; err != nil {
+			return err
+		}
+		return updateGameRating(tx, review.GameID, prior)
+	})
+}
+
+// Delete deletes the review with its votes.
+func (r *ReviewRepository) Delete(ctx context.Context, review *entities.Review, prior RatingPrior) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("review_id = ?", review.ID).Delete(&entities.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(review).Error; err != nil {
+			return err
+		}
+		return updateGameRating(tx, review.GameID, prior)
+	})
+}
+
+// updateGameRating sets the rating of the game to the Bayesian average of its
+// reviews, and its rating count.
+func updateGameRating(tx *gorm.DB, gameID uint, prior RatingPrior) error {
+	return tx.Model(&entities.Game{}).Where("id = ?", gameID).UpdateColumns(map[string]interface{}{
+		"rating": gorm.Expr("(SELECT (? + COALESCE(SUM(rating), 0)) / (? + COUNT(*)) FROM reviews WHERE game_id = ?)",
+			prior.Mean*prior.Weight, prior.Weight, gameID),
+		"rating_count": gorm.Expr("(SELECT COUNT(*) FROM reviews WHERE game_id = ?)", gameID),
+	}).Error
+}
+
+// AddVote records the user finding the review helpful, once.
+func (r *ReviewRepository) AddVote(reviewID uint, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&entities.ReviewVote{ReviewID: reviewID, UserID: userID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&entities.Review{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count + 1")).Error
+	})
+}
+
+func (r *ReviewRepository) RemoveVote(reviewID uint, userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("review_id = ? AND user_id = ?", reviewID, userID).Delete(&entities.ReviewVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return tx.Model(&entities.Review{}).Where("id = ?", reviewID).
+			UpdateColumn("helpful_count", gorm.Expr("helpful_count - 1")).Error
+	})
+}
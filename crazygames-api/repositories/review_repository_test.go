@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Reviews(t *testing.T) {
+	ctx := context.Background()
+	db.Exec("DELETE FROM review_votes")
+	db.Exec("DELETE FROM reviews")
+	db.Exec("DELETE FROM games")
+	db.Exec("DELETE FROM users")
+
+	game := &entities.Game{GameTitle: "Reviewed", GameURL: "http://reviewed.com"}
+	db.Create(game)
+	author, err := createUser("author", "author@gmail.com", "password", "player")
+	assert.NoError(t, err, "failed to create user for test")
+	voter, err := createUser("voter", "voter@gmail.com", "password", "player")
+	assert.NoError(t, err, "failed to create user for test")
+	prior := RatingPrior{Mean: 3, Weight: 5}
+
+	first := &entities.Review{GameID: game.ID, UserID: &author.ID, Rating: 5, Comment: "Great"}
+	second := &entities.Review{GameID: game.ID, UserID: &voter.ID, Rating: 1, Comment: "Bad"}
+
+	t.Run("reviews should rate the game", func(t *testing.T) {
+		assert.NoError(t, reviewRepository.Create(ctx, first, prior))
+		var stored entities.Game
+		db.First(&stored, game.ID)
+		assert.InDelta(t, 20.0/6, stored.Rating, 1e-9)
+		assert.Equal(t, 1, stored.RatingCount)
+
+		assert.NoError(t, reviewRepository.Create(ctx, second, prior))
+		second.Rating = 3
+		assert.NoError(t, reviewRepository.Update(ctx, second, prior))
+		db.First(&stored, game.ID)
+		assert.InDelta(t, 23.0/7, stored.Rating, 1e-9)
+		assert.Equal(t, 2, stored.RatingCount)
+	})
+
+	t.Run("second review of a user should fail", func(t *testing.T) {
+		err := reviewRepository.Create(ctx, &entities.Review{GameID: game.ID, UserID: &author.ID, Rating: 4}, prior)
+		assert.Error(t, err)
+
+		review, err := reviewRepository.GetByGameAndUser(game.ID, author.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, review.ID)
+	})
+
+	t.Run("votes should count once", func(t *testing.T) {
+		assert.NoError(t, reviewRepository.AddVote(first.ID, voter.ID))
+		assert.NoError(t, reviewRepository.AddVote(first.ID, voter.ID))
+		review, _ := reviewRepository.GetByID(first.ID)
+		assert.Equal(t, 1, review.HelpfulCount)
+
+		assert.NoError(t, reviewRepository.RemoveVote(first.ID, voter.ID))
+		assert.NoError(t, reviewRepository.RemoveVote(first.ID, voter.ID))
+		review, _ = reviewRepository.GetByID(first.ID)
+		assert.Equal(t, 0, review.HelpfulCount)
+	})
+
+	t.Run("reviews should sort by newest or most helpful", func(t *testing.T) {
+		assert.NoError(t, reviewRepository.AddVote(first.ID, voter.ID))
+		query := request.ReviewsRequestQuery{PageNumber: 1, PageSize: 10, Sort: request.ReviewSortNewest}
+
+		reviews, total, err := reviewRepository.GetByGame(game.ID, query)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Equal(t, second.ID, reviews[0].ID)
+		assert.Equal(t, "voter", reviews[0].Username)
+
+		query.Sort = request.ReviewSortHelpful
+		reviews, _, err = reviewRepository.GetByGame(game.ID, query)
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, reviews[0].ID)
+	})
+
+	t.Run("editing a review should keep its votes", func(t *testing.T) {
+		first.Comment = "Still great"
+		assert.NoError(t, reviewRepository.Update(ctx, first, prior))
+		review, _ := reviewRepository.GetByID(first.ID)
+		assert.Equal(t, "Still great", review.Comment)
+		assert.Equal(t, 1, review.HelpfulCount)
+	})
+
+	t.Run("deleting a review should rate the game without it", func(t *testing.T) {
+		assert.NoError(t, reviewRepository.Delete(ctx, first, prior))
+		var stored entities.Game
+		db.First(&stored, game.ID)
+		assert.InDelta(t, 18.0/6, stored.Rating, 1e-9)
+		assert.Equal(t, 1, stored.RatingCount)
+
+		var votes int64
+		db.Model(&entities.ReviewVote{}).Where("review_id = ?", first.ID).Count(&votes)
+		assert.Zero(t, votes)
+	})
+}
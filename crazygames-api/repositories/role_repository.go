@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+type RoleRepositoryInterface interface {
+	GetAll() ([]entities.Role, error)
+	GetByName(name string) (*entities.Role, error)
+	HasPermission(role string, permission string) (bool, error)
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) GetAll() ([]entities.Role, error) {
+	var roles []entities.Role
+	err := r.db.Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+func (r *RoleRepository) GetByName(name string) (*entities.Role, error) {
+	var role entities.Role
+	err := r.db.Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// HasPermission reports whether the role named role grants permission.
+func (r *RoleRepository) HasPermission(role string, permission string) (bool, error) {
+	var count int64
+	err := r.db.Table("role_permissions").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("roles.name = ? AND permissions.name = ?", role, permission).
+		Count(&count).Error
+	return count > 0, err
+}
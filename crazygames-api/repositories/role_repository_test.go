@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"testing"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RolePermissions(t *testing.T) {
+	db.Exec("DELETE FROM role_permissions")
+	db.Exec("DELETE FROM roles")
+	db.Exec("DELETE FROM permissions")
+
+	editor := entities.Role{
+		Name: entities.RoleEditor,
+		Permissions: []entities.Permission{
+			{Name: entities.PermissionGameWrite},
+			{Name: entities.PermissionCategoryWrite},
+		},
+	}
+	assert.NoError(t, db.Create(&editor).Error, "failed to create role for test")
+	assert.NoError(t, db.Create(&entities.Role{Name: entities.RolePlayer}).Error, "failed to create role for test")
+
+	t.Run("role should have its permissions", func(t *testing.T) {
+		allowed, err := roleRepository.HasPermission(entities.RoleEditor, entities.PermissionGameWrite)
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("role should not have other permissions", func(t *testing.T) {
+		allowed, err := roleRepository.HasPermission(entities.RoleEditor, entities.PermissionUserWrite)
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+
+		allowed, _ = roleRepository.HasPermission(entities.RolePlayer, entities.PermissionGameWrite)
+		assert.False(t, allowed)
+	})
+
+	t.Run("get by name should load the permissions", func(t *testing.T) {
+		role, err := roleRepository.GetByName(entities.RoleEditor)
+		assert.NoError(t, err)
+		assert.Len(t, role.Permissions, 2)
+
+		_, err = roleRepository.GetByName("unknown")
+		assert.Error(t, err)
+	})
+
+	t.Run("get all should return every role", func(t *testing.T) {
+		roles, err := roleRepository.GetAll()
+		assert.NoError(t, err)
+		assert.Len(t, roles, 2)
+	})
+}
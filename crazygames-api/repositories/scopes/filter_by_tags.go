@@ -0,0 +1,34 @@
+package scopes
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// FilterByTags keeps the games that have every tag of the comma separated
+// slugs.
+func FilterByTags(tags string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		seen := map[string]bool{}
+		var slugs []string
+		for _, slug := range strings.Split(tags, ",") {
+			slug = strings.TrimSpace(slug)
+			if slug != "" && !seen[slug] {
+				seen[slug] = true
+				slugs = append(slugs, slug)
+			}
+		}
+		if len(slugs) == 0 {
+			return db
+		}
+
+		tagged := db.Session(&gorm.Session{NewDB: true}).Table("game_tags").
+			Select("game_tags.game_id").
+			Joins("JOIN tags ON tags.id = game_tags.tag_id").
+			Where("tags.slug IN ?", slugs).
+			Group("game_tags.game_id").
+			Having("COUNT(*) = ?", len(slugs))
+		return db.Where("games.id IN (?)", tagged)
+	}
+}
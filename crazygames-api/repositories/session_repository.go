@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// A session is one login "family": every refresh token rotated out of the
+// same login shares its family ID, and revoking the family kills all of them.
+//
+//	session:<family>       hash {user_id, current, created_at}  current = hash of the live refresh token, created_at = login time
+//	refresh:<token hash>   family ID, kept after rotation so replays can be detected
+//	user_sessions:<user>   set of the user's family IDs
+type SessionRepository struct {
+	rdb *redis.Client
+}
+
+type SessionRepositoryInterface interface {
+	Create(ctx context.Context, familyID string, userID uint, refreshTokenHash string, ttl time.Duration) error
+	Rotate(ctx context.Context, refreshTokenHash string, newRefreshTokenHash string, ttl time.Duration) (string, uint, error)
+	Exists(ctx context.Context, familyID string) (bool, error)
+	Revoke(ctx context.Context, familyID string) error
+	RevokeAllForUser(ctx context.Context, userID uint) error
+	RevokeOthersForUser(ctx context.Context, userID uint, keepFamilyID string) error
+	GetCreatedAt(ctx context.Context, familyID string) (time.Time, error)
+}
+
+// rotateScript swaps the family's current refresh token if the presented one is
+// still current. Returns {1, user_id} on success, {0} if the family is gone and
+// {-1} if an already rotated token was presented.
+var rotateScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], 'current')
+if not current then
+	return {0}
+end
+if current ~= ARGV[1] then
+	return {-1}
+end
+redis.call('HSET', KEYS[1], 'current', ARGV[2])
+redis.call('EXPIRE', KEYS[1], ARGV[3])
+redis.call('SET', KEYS[2], ARGV[4], 'EX', ARGV[3])
+return {1, redis.call('HGET', KEYS[1], 'user_id')}
+`)
+
+func NewSessionRepository(rdb *redis.Client) *SessionRepository {
+	return &SessionRepository{rdb: rdb}
+}
+
+func sessionKey(familyID string) string {
+	return "session:" + familyID
+}
+
+func refreshTokenKey(refreshTokenHash string) string {
+	return "refresh:" + refreshTokenHash
+}
+
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("user_sessions:%d", userID)
+}
+
+func (r *SessionRepository) Create(ctx context.Context, familyID string, userID uint, refreshTokenHash string, ttl time.Duration) error {
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionKey(familyID), "user_id", userID, "current", refreshTokenHash, "created_at", time.Now().Unix())
+		pipe.Expire(ctx, sessionKey(familyID), ttl)
+		pipe.Set(ctx, refreshTokenKey(refreshTokenHash), familyID, ttl)
+		pipe.SAdd(ctx, userSessionsKey(userID), familyID)
+		pipe.Expire(ctx, userSessionsKey(userID), ttl)
+		return nil
+	})
+	return err
+}
+
+// Rotate replaces refreshTokenHash with newRefreshTokenHash and returns the
+// family ID and user ID. Presenting a token that was already rotated revokes
+// the whole family.
+func (r *SessionRepository) Rotate(ctx context.Context, refreshTokenHash string, newRefreshTokenHash string, ttl time.Duration) (string, uint, error) {
+	familyID, err := r.rdb.Get(ctx, refreshTokenKey(refreshTokenHash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", 0, ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	keys := []string{sessionKey(familyID), refreshTokenKey(newRefreshTokenHash)}
+	result, err := rotateScript.Run(ctx, r.rdb, keys, refreshTokenHash, newRefreshTokenHash, int(ttl.Seconds()), familyID).Slice()
+	if err != nil {
+		return "", 0, err
+	}
+
+	switch result[0].(int64) {
+	case 0:
+		return "", 0, ErrRefreshTokenInvalid
+	case -1:
+		if err := r.Revoke(ctx, familyID); err != nil {
+			return "", 0, err
+		}
+		return "", 0, ErrRefreshTokenReused
+	}
+
+	userID, err := strconv.ParseUint(result[1].(string), 10, 32)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := r.rdb.Expire(ctx, userSessionsKey(uint(userID)), ttl).Err(); err != nil {
+		return "", 0, err
+	}
+
+	return familyID, uint(userID), nil
+}
+
+// GetCreatedAt returns when the user logged in to start the session, which
+// refreshing does not change. It is the zero time if the session is gone or
+// was started before the login time was kept.
+func (r *SessionRepository) GetCreatedAt(ctx context.Context, familyID string) (time.Time, error) {
+	createdAt, err := r.rdb.HGet(ctx, sessionKey(familyID), "created_at").Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(createdAt, 0), nil
+}
+
+func (r *SessionRepository) Exists(ctx context.Context, familyID string) (bool, error) {
+	count, err := r.rdb.Exists(ctx, sessionKey(familyID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, familyID string) error {
+	userID, err := r.rdb.HGet(ctx, sessionKey(familyID), "user_id").Uint64()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(familyID))
+		pipe.SRem(ctx, userSessionsKey(uint(userID)), familyID)
+		return nil
+	})
+	return err
+}
+
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	familyIDs, err := r.rdb.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(familyIDs)+1)
+	for _, familyID := range familyIDs {
+		keys = append(keys, sessionKey(familyID))
+	}
+	keys = append(keys, userSessionsKey(userID))
+
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+// RevokeOthersForUser revokes every session of the user but keepFamilyID, the
+// one the user is making the request with.
+func (r *SessionRepository) RevokeOthersForUser(ctx context.Context, userID uint, keepFamilyID string) error {
+	familyIDs, err := r.rdb.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, familyID := range familyIDs {
+			if familyID == keepFamilyID {
+				continue
+			}
+			pipe.Del(ctx, sessionKey(familyID))
+			pipe.SRem(ctx, userSessionsKey(userID), familyID)
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RotateSession(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	err := sessionRepository.Create(ctx, "family1", 1, "hash1", time.Hour)
+	assert.NoError(t, err, "failed to create session for test")
+
+	t.Run("rotate current refresh token should succeed", func(t *testing.T) {
+		familyID, userID, err := sessionRepository.Rotate(ctx, "hash1", "hash2", time.Hour)
+		assert.NoError(t, err, "failed to rotate refresh token")
+		assert.Equal(t, familyID, "family1")
+		assert.Equal(t, userID, uint(1))
+
+		createdAt, err := sessionRepository.GetCreatedAt(ctx, "family1")
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), createdAt, 5*time.Second, "rotation should keep the login time")
+	})
+
+	t.Run("rotate unknown refresh token should fail", func(t *testing.T) {
+		_, _, err := sessionRepository.Rotate(ctx, "unknown", "hash3", time.Hour)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+
+	t.Run("replay rotated refresh token should revoke the family", func(t *testing.T) {
+		_, _, err := sessionRepository.Rotate(ctx, "hash1", "hash3", time.Hour)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+		exists, err := sessionRepository.Exists(ctx, "family1")
+		assert.NoError(t, err)
+		assert.False(t, exists)
+
+		_, _, err = sessionRepository.Rotate(ctx, "hash2", "hash4", time.Hour)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+}
+
+func Test_RevokeSession(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	sessionRepository.Create(ctx, "family1", 1, "hash1", time.Hour)
+	sessionRepository.Create(ctx, "family2", 1, "hash2", time.Hour)
+	sessionRepository.Create(ctx, "family3", 2, "hash3", time.Hour)
+
+	t.Run("revoke single session should keep the others", func(t *testing.T) {
+		err := sessionRepository.Revoke(ctx, "family1")
+		assert.NoError(t, err, "failed to revoke session")
+
+		exists, _ := sessionRepository.Exists(ctx, "family1")
+		assert.False(t, exists)
+		exists, _ = sessionRepository.Exists(ctx, "family2")
+		assert.True(t, exists)
+	})
+
+	t.Run("revoke all sessions of a user should keep other users", func(t *testing.T) {
+		err := sessionRepository.RevokeAllForUser(ctx, 1)
+		assert.NoError(t, err, "failed to revoke sessions")
+
+		exists, _ := sessionRepository.Exists(ctx, "family2")
+		assert.False(t, exists)
+		exists, _ = sessionRepository.Exists(ctx, "family3")
+		assert.True(t, exists)
+	})
+
+	t.Run("revoke unknown session should succeed", func(t *testing.T) {
+		err := sessionRepository.Revoke(ctx, "unknown")
+		assert.NoError(t, err)
+	})
+}
+
+func Test_RevokeOtherSessions(t *testing.T) {
+	ctx := context.Background()
+	rdb.FlushDB(ctx)
+
+	sessionRepository.Create(ctx, "family1", 1, "hash1", time.Hour)
+	sessionRepository.Create(ctx, "family2", 1, "hash2", time.Hour)
+	sessionRepository.Create(ctx, "family3", 2, "hash3", time.Hour)
+
+	err := sessionRepository.RevokeOthersForUser(ctx, 1, "family1")
+	assert.NoError(t, err, "failed to revoke sessions")
+
+	exists, _ := sessionRepository.Exists(ctx, "family1")
+	assert.True(t, exists, "the kept session should stay")
+	exists, _ = sessionRepository.Exists(ctx, "family2")
+	assert.False(t, exists)
+	exists, _ = sessionRepository.Exists(ctx, "family3")
+	assert.True(t, exists, "other users should keep their sessions")
+}
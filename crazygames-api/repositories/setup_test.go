@@ -1,65 +1,120 @@
-package repositories
-
-import (
-	"log"
-	"os"
-	"testing"
-
-	"crazygames.io/entities"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
-)
-
-var (
-	db                           *gorm.DB
-	userRepository               *UserRepository
-	adsRepo                      *adsRepository
-	categoryRepository           *CategoryRepository
-	gameRepository               *GameRepository
-	passwordResetTokenRepository *PasswordResetTokenRepository
-)
-
-func TestMain(m *testing.M) {
-	var err error
-	dsn := "test_user:test_password@tcp(localhost:3307)/test_db?charset=utf8mb4&parseTime=True&loc=Local"
-
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent),
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	err = db.AutoMigrate(
-		&entities.User{},
-		&entities.Category{},
-		&entities.Game{},
-		&entities.Review{},
-		&entities.Favorite{},
-		&entities.Ads{},
-		&entities.PasswordResetToken{},
-	)
-	if err != nil {
-		log.Fatalf("failed to migrate database: %v", err)
-	}
-
-	// Initialize repositories
-	userRepository = NewUserRepository(db)
-	categoryRepository = NewCategoryRepository(db)
-	adsRepo = NewAdsRepository(db)
-	gameRepository = NewGameRepository(db)
-	passwordResetTokenRepository = NewPasswordResetTokenRepository(db)
-
-	// run the tests
-	code := m.Run()
-
-	sqlDB, err := db.DB()
-	if err != nil {
-		log.Printf("failed to get sql.DB: %v", err)
-	} else {
-		sqlDB.Close()
-	}
-
-	os.Exit(code)
-}
+package repositories
+
+import (
+	"context"
+	"log"
+	"os"
+	"testing"
+
+	"crazygames.io/entities"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var (
+	db                           *gorm.DB
+	rdb                          *redis.Client
+	userRepository               *UserRepository
+	adsRepo                      *adsRepository
+	categoryRepository           *CategoryRepository
+	gameRepository               *GameRepository
+	passwordResetTokenRepository *PasswordResetTokenRepository
+	sessionRepository            *SessionRepository
+	userIdentityRepository       *UserIdentityRepository
+	emailVerificationRepository  *EmailVerificationRepository
+	rateLimitRepository          *RateLimitRepository
+	recoveryCodeRepository       *RecoveryCodeRepository
+	roleRepository               *RoleRepository
+	apiKeyRepository             *APIKeyRepository
+	auditRepository              *AuditRepository
+	dataRequestRepository        *DataRequestRepository
+	playHistoryRepository        *PlayHistoryRepository
+	playCountRepository          *PlayCountRepository
+	rankingRepository            *RankingRepository
+	reviewRepository             *ReviewRepository
+	gameVoteRepository           *GameVoteRepository
+	deviceVoteRepository         *DeviceVoteRepository
+	favoriteRepository           *FavoriteRepository
+	tagRepository                *TagRepository
+)
+
+func TestMain(m *testing.M) {
+	var err error
+	dsn := "test_user:test_password@tcp(localhost:3307)/test_db?charset=utf8mb4&parseTime=True&loc=Local"
+
+	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = db.AutoMigrate(
+		&entities.User{},
+		&entities.Category{},
+		&entities.Tag{},
+		&entities.Game{},
+		&entities.Review{},
+		&entities.ReviewVote{},
+		&entities.GameVote{},
+		&entities.Favorite{},
+		&entities.PlayHistory{},
+		&entities.PlayCountFlush{},
+		&entities.Ads{},
+		&entities.PasswordResetToken{},
+		&entities.UserIdentity{},
+		&entities.RecoveryCode{},
+		&entities.Permission{},
+		&entities.Role{},
+		&entities.APIKey{},
+		&entities.AuditEvent{},
+		&entities.DataRequest{},
+	)
+	if err != nil {
+		log.Fatalf("failed to migrate database: %v", err)
+	}
+
+	rdb = redis.NewClient(&redis.Options{Addr: "localhost:6380"})
+	if err := rdb.Ping(context.Background()).Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Initialize repositories
+	userRepository = NewUserRepository(db)
+	categoryRepository = NewCategoryRepository(db)
+	adsRepo = NewAdsRepository(db)
+	gameRepository = NewGameRepository(db)
+	passwordResetTokenRepository = NewPasswordResetTokenRepository(db)
+	sessionRepository = NewSessionRepository(rdb)
+	userIdentityRepository = NewUserIdentityRepository(db)
+	emailVerificationRepository = NewEmailVerificationRepository(rdb)
+	rateLimitRepository = NewRateLimitRepository(rdb)
+	recoveryCodeRepository = NewRecoveryCodeRepository(db)
+	roleRepository = NewRoleRepository(db)
+	apiKeyRepository = NewAPIKeyRepository(db)
+	auditRepository = NewAuditRepository(db)
+	dataRequestRepository = NewDataRequestRepository(db)
+	playHistoryRepository = NewPlayHistoryRepository(db)
+	playCountRepository = NewPlayCountRepository(rdb)
+	rankingRepository = NewRankingRepository(rdb)
+	reviewRepository = NewReviewRepository(db)
+	gameVoteRepository = NewGameVoteRepository(db)
+	deviceVoteRepository = NewDeviceVoteRepository(rdb)
+	favoriteRepository = NewFavoriteRepository(db)
+	tagRepository = NewTagRepository(db)
+
+	// run the tests
+	code := m.Run()
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Printf("failed to get sql.DB: %v", err)
+	} else {
+		sqlDB.Close()
+	}
+	rdb.Close()
+
+	os.Exit(code)
+}
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+type TagRepositoryInterface interface {
+	Create(ctx context.Context, tag *entities.Tag) error
+	GetAll(search string) ([]entities.Tag, error)
+	GetByID(id uint) (*entities.Tag, error)
+	GetBySlug(slug string) (*entities.Tag, error)
+	GetByIDs(ids []uint) ([]entities.Tag, error)
+	Exists(name string, slug string, exceptID uint) (bool, error)
+	Update(ctx context.Context, tag *entities.Tag) (*entities.Tag, error)
+	Delete(ctx context.Context, id uint) error
+	Upsert(tags []entities.Tag) error
+	SetGameTags(ctx context.Context, gameID uint, tags []entities.Tag) error
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) Create(ctx context.Context, tag *entities.Tag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tag).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionCreate, entities.AuditEntityTag, tag.ID, nil, tag)
+	})
+}
+
+// GetAll returns the tags by name, only those whose name contains search if
+// it is set.
+func (r *TagRepository) GetAll(search string) ([]entities.Tag, error) {
+	var tags []entities.Tag
+	query := r.db.Order("tag_name")
+	if search != "" {
+		query = query.Where("tag_name LIKE ?", "%"+search+"%")
+	}
+	err := query.Find(&tags).Error
+	return tags, err
+}
+
+// GetByID returns nil, nil when the tag does not exist.
+func (r *TagRepository) GetByID(id uint) (*entities.Tag, error) {
+	var tag entities.Tag
+	result := r.db.Limit(1).Find(&tag, id)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &tag, nil
+}
+
+// GetBySlug returns nil, nil when the tag does not exist.
+func (r *TagRepository) GetBySlug(slug string) (*entities.Tag, error) {
+	var tag entities.Tag
+	result := r.db.Where("slug = ?", slug).Limit(1).Find(&tag)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &tag, nil
+}
+
+// GetByIDs returns the tags that exist among the IDs.
+func (r *TagRepository) GetByIDs(ids []uint) ([]entities.Tag, error) {
+	var tags []entities.Tag
+	if len(ids) == 0 {
+		return tags, nil
+	}
+	err := r.db.Find(&tags, ids).Error
+	return tags, err
+}
+
+// Exists tells whether a tag other than exceptID already has the name or the
+// slug.
+func (r *TagRepository) Exists(name string, slug string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&entities.Tag{}).
+		Where("(tag_name = ? OR slug = ?) AND id <> ?", name, slug, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TagRepository) Update(ctx context.Context, tag *entities.Tag) (*entities.Tag, error) {
+	var updatedTag entities.Tag
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingTag entities.Tag
+		if err := tx.First(&existingTag, tag.ID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&entities.Tag{}).Where("id = ?", tag.ID).Updates(map[string]interface{}{
+			"tag_name":   tag.TagName,
+			"slug":       tag.Slug,
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&updatedTag, tag.ID).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionUpdate, entities.AuditEntityTag, tag.ID, &existingTag, &updatedTag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updatedTag, nil
+}
+
+// Delete removes the tag from its games, then deletes it.
+func (r *TagRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag entities.Tag
+		result := tx.Limit(1).Find(&tag, id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Where("tag_id = ?", id).Delete(&entities.GameTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&tag).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionDelete, entities.AuditEntityTag, id, &tag, nil)
+	})
+}
+
+// Upsert creates the tags that are missing and renames those whose slug or
+// name already exists, which makes importing the same tags twice harmless.
+func (r *TagRepository) Upsert(tags []entities.Tag) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"tag_name", "updated_at"}),
+	}).CreateInBatches(tags, 500).Error
+}
+
+// SetGameTags replaces the tags of the game.
+func (r *TagRepository) SetGameTags(ctx context.Context, gameID uint, tags []entities.Tag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var game entities.Game
+		if err := tx.Preload("Tags").First(&game, gameID).Error; err != nil {
+			return err
+		}
+		existingGame := game
+		existingGame.Tags = append([]entities.Tag(nil), game.Tags...)
+
+		if err := tx.Where("game_id = ?", gameID).Delete(&entities.GameTag{}).Error; err != nil {
+			return err
+		}
+		gameTags := make([]entities.GameTag, 0, len(tags))
+		for _, tag := range tags {
+			gameTags = append(gameTags, entities.GameTag{GameID: gameID, TagID: tag.ID})
+		}
+		if len(gameTags) > 0 {
+			if err := tx.Create(&gameTags).Error; err != nil {
+				return err
+			}
+		}
+
+		game.Tags = tags
+		return recordAudit(ctx, tx, entities.AuditActionUpdate, entities.AuditEntityGame, gameID, &existingGame, &game)
+	})
+}
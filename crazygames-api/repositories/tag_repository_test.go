@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Tags(t *testing.T) {
+	ctx := context.Background()
+	db.Exec("DELETE FROM game_tags")
+	db.Exec("DELETE FROM tags")
+	db.Exec("DELETE FROM games")
+	t.Cleanup(func() { db.Exec("DELETE FROM game_tags") })
+
+	puzzle := &entities.Tag{TagName: "Puzzle", Slug: "puzzle"}
+	racing := &entities.Tag{TagName: "Racing", Slug: "racing"}
+	assert.NoError(t, tagRepository.Create(ctx, puzzle))
+	assert.NoError(t, tagRepository.Create(ctx, racing))
+
+	first := &entities.Game{GameTitle: "First", GameURL: "http://first.com"}
+	second := &entities.Game{GameTitle: "Second", GameURL: "http://second.com"}
+	db.Create(first)
+	db.Create(second)
+
+	t.Run("tags should be found by slug and name", func(t *testing.T) {
+		tag, err := tagRepository.GetBySlug("racing")
+		assert.NoError(t, err)
+		assert.Equal(t, racing.ID, tag.ID)
+
+		tag, err = tagRepository.GetBySlug("shooting")
+		assert.NoError(t, err)
+		assert.Nil(t, tag)
+
+		exists, err := tagRepository.Exists("Puzzle", "other", racing.ID)
+		assert.NoError(t, err)
+		assert.True(t, exists)
+		exists, err = tagRepository.Exists("Puzzle", "puzzle", puzzle.ID)
+		assert.NoError(t, err)
+		assert.False(t, exists, "a tag should not conflict with itself")
+	})
+
+	t.Run("upsert should create missing tags and rename the others", func(t *testing.T) {
+		err := tagRepository.Upsert([]entities.Tag{{TagName: "Puzzles", Slug: "puzzle"}, {TagName: "Shooting", Slug: "shooting"}})
+		assert.NoError(t, err)
+
+		tags, err := tagRepository.GetAll("")
+		assert.NoError(t, err)
+		assert.Len(t, tags, 3)
+		assert.Equal(t, "Puzzles", tags[0].TagName)
+		assert.Equal(t, puzzle.ID, tags[0].ID)
+
+		tags, err = tagRepository.GetAll("shoot")
+		assert.NoError(t, err)
+		assert.Len(t, tags, 1)
+	})
+
+	t.Run("games should be filtered by all their tags", func(t *testing.T) {
+		assert.NoError(t, tagRepository.SetGameTags(ctx, first.ID, []entities.Tag{*puzzle, *racing}))
+		assert.NoError(t, tagRepository.SetGameTags(ctx, second.ID, []entities.Tag{*racing}))
+
+		games, total, err := gameRepository.GetAll(request.GamesRequestQuery{PageNumber: 1, PageSize: 10, Tags: "racing"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.Len(t, games, 2)
+
+		games, total, err = gameRepository.GetAll(request.GamesRequestQuery{PageNumber: 1, PageSize: 10, Tags: "racing, puzzle,racing"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, first.ID, games[0].ID)
+		assert.Len(t, games[0].Tags, 2)
+
+		_, total, err = gameRepository.GetAll(request.GamesRequestQuery{PageNumber: 1, PageSize: 10, Tags: "shooting"})
+		assert.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("setting game tags should replace them", func(t *testing.T) {
+		assert.NoError(t, tagRepository.SetGameTags(ctx, first.ID, []entities.Tag{*puzzle}))
+		game, err := gameRepository.GetByID(first.ID)
+		assert.NoError(t, err)
+		assert.Len(t, game.Tags, 1)
+
+		assert.NoError(t, tagRepository.SetGameTags(ctx, first.ID, nil))
+		game, err = gameRepository.GetByID(first.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, game.Tags)
+	})
+
+	t.Run("deleted tags should be removed from their games", func(t *testing.T) {
+		assert.NoError(t, tagRepository.Delete(ctx, racing.ID))
+		tag, err := tagRepository.GetByID(racing.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, tag)
+
+		game, err := gameRepository.GetByID(second.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, game.Tags)
+	})
+}
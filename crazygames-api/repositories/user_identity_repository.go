@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"crazygames.io/entities"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+type UserIdentityRepositoryInterface interface {
+	Create(identity *entities.UserIdentity) error
+	CreateWithUser(user *entities.User, identity *entities.UserIdentity) error
+	GetByProviderSubject(provider, subject string) (*entities.UserIdentity, error)
+	GetByUserID(userID uint) ([]entities.UserIdentity, error)
+	Delete(userID uint, provider string) error
+}
+
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+func (r *UserIdentityRepository) Create(identity *entities.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// CreateWithUser creates user and its first identity together, so a failed
+// link does not leave behind a user without a way to log in.
+func (r *UserIdentityRepository) CreateWithUser(user *entities.User, identity *entities.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *UserIdentityRepository) GetByProviderSubject(provider, subject string) (*entities.UserIdentity, error) {
+	var identity entities.UserIdentity
+	err := r.db.Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *UserIdentityRepository) GetByUserID(userID uint) ([]entities.UserIdentity, error) {
+	var identities []entities.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at").Find(&identities).Error
+	return identities, err
+}
+
+func (r *UserIdentityRepository) Delete(userID uint, provider string) error {
+	return r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&entities.UserIdentity{}).Error
+}
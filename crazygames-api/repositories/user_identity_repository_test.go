@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CreateUserIdentity(t *testing.T) {
+	db.Exec("DELETE FROM user_identities")
+	db.Exec("DELETE FROM users")
+
+	user, err := createUser("identityuser", "identityuser@gmail.com", "password", "player")
+	assert.NoError(t, err, "failed to create user for test")
+
+	t.Run("link identity to user should succeed", func(t *testing.T) {
+		err := userIdentityRepository.Create(&entities.UserIdentity{UserID: user.ID, Provider: "google", Subject: "1001"})
+		assert.NoError(t, err, "failed to create identity")
+
+		identity, err := userIdentityRepository.GetByProviderSubject("google", "1001")
+		assert.NoError(t, err, "failed to fetch identity")
+		assert.Equal(t, user.ID, identity.UserID)
+	})
+
+	t.Run("link same provider account twice should fail", func(t *testing.T) {
+		other, _ := createUser("otheruser", "otheruser@gmail.com", "password", "player")
+		err := userIdentityRepository.Create(&entities.UserIdentity{UserID: other.ID, Provider: "google", Subject: "1001"})
+		assert.Error(t, err, "expected error when linking an identity that belongs to another user")
+	})
+
+	t.Run("link second account of the same provider should fail", func(t *testing.T) {
+		err := userIdentityRepository.Create(&entities.UserIdentity{UserID: user.ID, Provider: "google", Subject: "1002"})
+		assert.Error(t, err, "expected error when linking a second google account")
+	})
+
+	t.Run("create user without password together with identity should succeed", func(t *testing.T) {
+		socialUser := &entities.User{Username: "socialuser@gmail.com", Email: "socialuser@gmail.com", Role: entities.RolePlayer}
+		identity := &entities.UserIdentity{Provider: "github", Subject: "42"}
+
+		err := userIdentityRepository.CreateWithUser(socialUser, identity)
+		assert.NoError(t, err, "failed to create user with identity")
+		assert.Equal(t, socialUser.ID, identity.UserID)
+	})
+
+	t.Run("failed identity should roll back the user", func(t *testing.T) {
+		socialUser := &entities.User{Username: "rollback@gmail.com", Email: "rollback@gmail.com", Role: entities.RolePlayer}
+
+		err := userIdentityRepository.CreateWithUser(socialUser, &entities.UserIdentity{Provider: "github", Subject: "42"})
+		assert.Error(t, err)
+
+		_, err = userRepository.GetByEmail("rollback@gmail.com")
+		assert.Error(t, err, "expected user to be rolled back")
+	})
+}
+
+func Test_DeleteUserIdentity(t *testing.T) {
+	db.Exec("DELETE FROM user_identities")
+	db.Exec("DELETE FROM users")
+
+	user, _ := createUser("identityuser", "identityuser@gmail.com", "password", "player")
+	userIdentityRepository.Create(&entities.UserIdentity{UserID: user.ID, Provider: "google", Subject: "1001"})
+	userIdentityRepository.Create(&entities.UserIdentity{UserID: user.ID, Provider: "github", Subject: "42"})
+
+	t.Run("unlink provider should keep the other identities", func(t *testing.T) {
+		err := userIdentityRepository.Delete(user.ID, "google")
+		assert.NoError(t, err, "failed to delete identity")
+
+		identities, err := userIdentityRepository.GetByUserID(user.ID)
+		assert.NoError(t, err)
+		assert.Len(t, identities, 1)
+		assert.Equal(t, "github", identities[0].Provider)
+	})
+
+	t.Run("delete user should delete its identities", func(t *testing.T) {
+		err := userRepository.Delete(context.Background(), user.ID)
+		assert.NoError(t, err, "failed to delete user")
+
+		identities, _ := userIdentityRepository.GetByUserID(user.ID)
+		assert.Len(t, identities, 0)
+	})
+}
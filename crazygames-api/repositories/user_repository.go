@@ -1,86 +1,233 @@
-package repositories
-
-import (
-	"crazygames.io/entities"
-	"gorm.io/gorm"
-)
-
-type UserRepository struct {
-	db *gorm.DB
-}
-
-type UserRepositoryInterface interface {
-	OauthCreate(user *entities.User) error
-	Create(user *entities.User) error
-	GetAll(page, limit int) ([]entities.User, error)
-	GetByID(id uint) (*entities.User, error)
-	GetByUsername(username string) (*entities.User, error)
-	Update(user *entities.User) (*entities.User, error)
-	UpdatePassword(userEmail string, hashedPassword string) error
-	Delete(id uint) error
-	GetByEmail(email string) (*entities.User, error)
-}
-
-func NewUserRepository(db *gorm.DB) *UserRepository {
-	return &UserRepository{db: db}
-}
-
-func (r *UserRepository) OauthCreate(user *entities.User) error {
-	return r.db.Create(user).Error
-}
-
-func (r *UserRepository) Create(user *entities.User) error {
-	return r.db.Create(user).Error
-}
-
-func (r *UserRepository) GetAll(page, limit int) ([]entities.User, error) {
-	var users []entities.User
-	offset := (page - 1) * limit
-	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
-	return users, err
-}
-
-func (r *UserRepository) GetByID(id uint) (*entities.User, error) {
-	var user entities.User
-	err := r.db.First(&user, id).Error
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
-
-func (r *UserRepository) GetByUsername(username string) (*entities.User, error) {
-	var user entities.User
-	err := r.db.Where("username = ?", username).First(&user).Error
-	if err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
-
-func (r *UserRepository) Update(user *entities.User) (*entities.User, error) {
-	err := r.db.Save(user).Error
-	if err != nil {
-		return nil, err
-	}
-	return user, nil
-}
-
-func (r *UserRepository) UpdatePassword(userEmail string, hashedPassword string) error {
-	return r.db.Model(&entities.User{}).
-		Where("email = ?", userEmail).
-		Update("password", hashedPassword).
-		Error
-}
-
-func (r *UserRepository) Delete(id uint) error {
-	return r.db.Delete(&entities.User{}, id).Error
-}
-
-func (r *UserRepository) GetByEmail(email string) (*entities.User, error) {
-	var user entities.User
-	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
-		return nil, err
-	}
-	return &user, nil
-}
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+type UserRepositoryInterface interface {
+	OauthCreate(user *entities.User) error
+	Create(ctx context.Context, user *entities.User) error
+	GetAll(queryParams request.UsersRequestQuery) ([]entities.User, int64, error)
+	Each(queryParams request.UsersRequestQuery, fn func(user *entities.User) error) error
+	GetByID(id uint) (*entities.User, error)
+	GetByUsername(username string) (*entities.User, error)
+	Update(ctx context.Context, user *entities.User) (*entities.User, error)
+	UpdatePassword(userEmail string, hashedPassword string) error
+	MarkEmailVerified(id uint, email string) error
+	Delete(ctx context.Context, id uint) error
+	GetByEmail(email string) (*entities.User, error)
+	LiftExpiredSuspensions(now time.Time) (int64, error)
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) OauthCreate(user *entities.User) error {
+	return r.db.Create(user).Error
+}
+
+func (r *UserRepository) Create(ctx context.Context, user *entities.User) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionCreate, entities.AuditEntityUser, user.ID, nil, user)
+	})
+}
+
+// GetAll returns a page of the users matching queryParams and how many match
+// in total.
+func (r *UserRepository) GetAll(queryParams request.UsersRequestQuery) ([]entities.User, int64, error) {
+	var users []entities.User
+	var total int64
+
+	query := r.filter(queryParams)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order(userOrder(queryParams)).
+		Offset(queryParams.Limit * (queryParams.Page - 1)).
+		Limit(queryParams.Limit).Find(&users).Error
+
+	return users, total, err
+}
+
+// Each calls fn with every user matching queryParams in order, ignoring the
+// page. The users are read one at a time rather than all at once.
+func (r *UserRepository) Each(queryParams request.UsersRequestQuery, fn func(user *entities.User) error) error {
+	rows, err := r.filter(queryParams).Order(userOrder(queryParams)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user entities.User
+		if err := r.db.ScanRows(rows, &user); err != nil {
+			return err
+		}
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (r *UserRepository) filter(queryParams request.UsersRequestQuery) *gorm.DB {
+	query := r.db.Model(&entities.User{})
+	if queryParams.Search != "" {
+		query = query.Where("username LIKE ? OR email LIKE ?", "%"+queryParams.Search+"%", "%"+queryParams.Search+"%")
+	}
+	if queryParams.Role != "" {
+		query = query.Where("role = ?", queryParams.Role)
+	}
+	if queryParams.Status != "" {
+		query = query.Where("status = ?", queryParams.Status)
+	}
+	if queryParams.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", queryParams.CreatedFrom)
+	}
+	if queryParams.CreatedTo != nil {
+		query = query.Where("created_at < ?", queryParams.CreatedTo)
+	}
+	return query
+}
+
+// userOrder sorts by the column of queryParams, which the request binding
+// restricts to a known column, and then by ID so that pages are stable.
+func userOrder(queryParams request.UsersRequestQuery) clause.OrderBy {
+	columns := []clause.OrderByColumn{{Column: clause.Column{Name: "id"}, Desc: queryParams.Order == "desc"}}
+	if queryParams.Sort != "" && queryParams.Sort != "id" {
+		columns = append([]clause.OrderByColumn{{Column: clause.Column{Name: queryParams.Sort}, Desc: queryParams.Order == "desc"}}, columns...)
+	}
+	return clause.OrderBy{Columns: columns}
+}
+
+func (r *UserRepository) GetByID(id uint) (*entities.User, error) {
+	var user entities.User
+	err := r.db.First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetByUsername(username string) (*entities.User, error) {
+	var user entities.User
+	err := r.db.Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) Update(ctx context.Context, user *entities.User) (*entities.User, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existingUser entities.User
+		if err := tx.First(&existingUser, user.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionUpdate, entities.AuditEntityUser, user.ID, &existingUser, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *UserRepository) UpdatePassword(userEmail string, hashedPassword string) error {
+	return r.db.Model(&entities.User{}).
+		Where("email = ?", userEmail).
+		Update("password", hashedPassword).
+		Error
+}
+
+// MarkEmailVerified verifies the user's email, provided it is still email.
+func (r *UserRepository) MarkEmailVerified(id uint, email string) error {
+	result := r.db.Model(&entities.User{}).
+		Where("id = ? AND email = ?", id, email).
+		Update("email_verified", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete deletes the user with their favorites, game and review votes and
+// pending password resets. Their reviews and play history are kept for the
+// games' stats, without the user they belonged to, as are the vote counters
+// and helpful counts.
+func (r *UserRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user entities.User
+		result := tx.Limit(1).Find(&user, id)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		if err := tx.Model(&entities.Review{}).Where("user_id = ?", id).Update("user_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&entities.PlayHistory{}).Where("user_id = ?", id).Update("user_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&entities.Favorite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&entities.GameVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&entities.ReviewVote{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("email = ?", user.Email).Delete(&entities.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return recordAudit(ctx, tx, entities.AuditActionDelete, entities.AuditEntityUser, id, &user, nil)
+	})
+}
+
+func (r *UserRepository) GetByEmail(email string) (*entities.User, error) {
+	var user entities.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// LiftExpiredSuspensions makes the users whose suspension ended before now
+// active again and returns how many there were.
+func (r *UserRepository) LiftExpiredSuspensions(now time.Time) (int64, error) {
+	result := r.db.Model(&entities.User{}).
+		Where("status = ? AND suspended_until <= ?", entities.UserSuspended, now).
+		Updates(map[string]interface{}{
+			"status":            entities.UserActive,
+			"suspended_until":   nil,
+			"status_reason":     "",
+			"status_changed_by": nil,
+			"status_changed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
@@ -1,80 +1,209 @@
-package routes
-
-import (
-	docs "crazygames.io/docs"
-	"crazygames.io/handler"
-	"github.com/gin-gonic/gin"
-	swaggerfiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
-)
-
-type Router struct {
-	CategoryHandler *handler.CategoryHandler
-	UserHandler     *handler.UserHandler
-	AdsHander       *handler.AdsHandler
-	OAuthHandler    *handler.OAuthHandler
-	AuthHandler     *handler.AuthHandler
-	GameHander      *handler.GameHandler
-}
-
-func NewRouter(category *handler.CategoryHandler, user *handler.UserHandler, ads *handler.AdsHandler, game *handler.GameHandler, Oauth *handler.OAuthHandler, auth *handler.AuthHandler) *Router {
-	return &Router{
-		CategoryHandler: category,
-		UserHandler:     user,
-		AdsHander:       ads,
-		GameHander:      game,
-		OAuthHandler:    Oauth,
-		AuthHandler:     auth,
-	}
-}
-
-func (ro *Router) RegisterRoutes(r *gin.Engine) {
-	apiGroup := r.Group("/api")
-	{
-		categoryApi := apiGroup.Group("/category")
-		categoryApi.GET("/", ro.CategoryHandler.GetAll)
-		categoryApi.GET("/menu", ro.CategoryHandler.GetMenu)
-		categoryApi.GET("/:id", ro.CategoryHandler.GetByID)
-		categoryApi.POST("", ro.CategoryHandler.Create)
-		categoryApi.PUT("/:id", ro.CategoryHandler.Update)
-		categoryApi.DELETE("/:id", ro.CategoryHandler.Delete)
-
-		userApi := apiGroup.Group("/user")
-		userApi.GET("/", ro.UserHandler.GetAll)
-		userApi.GET("/:id", ro.UserHandler.GetByID)
-		userApi.POST("", ro.UserHandler.Create)
-		userApi.PUT("/:id", ro.UserHandler.Update)
-		userApi.DELETE("/:id", ro.UserHandler.Delete)
-		userApi.POST("/forgot-password", ro.UserHandler.ForgotPassword)
-		userApi.POST("/reset-password", ro.UserHandler.ResetPassword)
-
-		adsApi := apiGroup.Group("/ads")
-		adsApi.GET("/", ro.AdsHander.GetAll)
-		adsApi.GET("/:id", ro.AdsHander.GetByID)
-		adsApi.POST("/", ro.AdsHander.Create)
-		adsApi.PUT("/:id", ro.AdsHander.Update)
-		adsApi.DELETE("/:id", ro.AdsHander.Delete)
-
-		gameApi := apiGroup.Group("/game")
-		gameApi.GET("/", ro.GameHander.GetAll)
-		gameApi.GET("/:id", ro.GameHander.GetByID)
-		gameApi.GET("/category/:id", ro.GameHander.GetByCategoryID)
-		gameApi.POST("/", ro.GameHander.Create)
-		gameApi.PUT("/:id", ro.GameHander.Update)
-		gameApi.DELETE("/:id", ro.GameHander.Delete)
-
-		OAuthApi := apiGroup.Group("/Oauth")
-		OAuthApi.GET("/google/login", ro.OAuthHandler.GoogleLogin)
-		OAuthApi.GET("/google/callback", ro.OAuthHandler.GoogleCallback)
-
-		authApi := apiGroup.Group("/auth")
-		authApi.POST("/login", ro.AuthHandler.Login)
-		authApi.POST("/register", ro.AuthHandler.Register)
-		authApi.POST("/check-email", ro.AuthHandler.CheckEmail)
-	}
-
-	{
-		docs.SwaggerInfo.BasePath = "/api"
-		r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
-	}
-}
+package routes
+
+import (
+	"crazygames.io/config"
+	docs "crazygames.io/docs"
+	"crazygames.io/entities"
+	"crazygames.io/handler"
+	"crazygames.io/middlewares"
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+type Router struct {
+	CategoryHandler *handler.CategoryHandler
+	UserHandler     *handler.UserHandler
+	AdsHander       *handler.AdsHandler
+	OAuthHandler    *handler.OAuthHandler
+	AuthHandler     *handler.AuthHandler
+	GameHander      *handler.GameHandler
+	RoleHandler     *handler.RoleHandler
+	APIKeyHandler   *handler.APIKeyHandler
+	AuditHandler    *handler.AuditHandler
+	AccountHandler  *handler.AccountHandler
+	PlayHandler     *handler.PlayHandler
+	RankingHandler  *handler.RankingHandler
+	ReviewHandler   *handler.ReviewHandler
+	VoteHandler     *handler.VoteHandler
+	FavoriteHandler *handler.FavoriteHandler
+	TagHandler      *handler.TagHandler
+	// DataRequestHandler serves the personal data exports and erasures.
+	DataRequestHandler *handler.DataRequestHandler
+	AuthMiddleware     gin.HandlerFunc
+	// OptionalAuthMiddleware is AuthMiddleware that lets anonymous users through.
+	OptionalAuthMiddleware gin.HandlerFunc
+	// APIKeyMiddleware is AuthMiddleware that also accepts API keys. It is
+	// only used on routes guarded by a permission, which API keys are scoped to.
+	APIKeyMiddleware gin.HandlerFunc
+	RateLimiter      middlewares.RateLimiter
+	// PermissionChecker resolves the permissions of the role in the token.
+	PermissionChecker middlewares.PermissionChecker
+	// EnrollmentMiddleware is AuthMiddleware that also accepts the MFA token of
+	// a login that requires two-factor enrollment first.
+	EnrollmentMiddleware gin.HandlerFunc
+}
+
+func NewRouter(category *handler.CategoryHandler, user *handler.UserHandler, ads *handler.AdsHandler, game *handler.GameHandler, Oauth *handler.OAuthHandler, auth *handler.AuthHandler, role *handler.RoleHandler, apiKey *handler.APIKeyHandler, audit *handler.AuditHandler, account *handler.AccountHandler, dataRequest *handler.DataRequestHandler, play *handler.PlayHandler, ranking *handler.RankingHandler, review *handler.ReviewHandler, vote *handler.VoteHandler, favorite *handler.FavoriteHandler, tag *handler.TagHandler, authMiddleware gin.HandlerFunc, optionalAuthMiddleware gin.HandlerFunc, enrollmentMiddleware gin.HandlerFunc, apiKeyMiddleware gin.HandlerFunc, rateLimiter middlewares.RateLimiter, permissionChecker middlewares.PermissionChecker) *Router {
+	return &Router{
+		CategoryHandler: category,
+		UserHandler:     user,
+		AdsHander:       ads,
+		GameHander:      game,
+		OAuthHandler:    Oauth,
+		AuthHandler:     auth,
+		RoleHandler:     role,
+		APIKeyHandler:   apiKey,
+		AuditHandler:    audit,
+		AccountHandler:  account,
+		PlayHandler:     play,
+		RankingHandler:  ranking,
+		ReviewHandler:   review,
+		VoteHandler:     vote,
+		FavoriteHandler: favorite,
+		TagHandler:      tag,
+		AuthMiddleware:  authMiddleware,
+		RateLimiter:     rateLimiter,
+
+		DataRequestHandler:     dataRequest,
+		OptionalAuthMiddleware: optionalAuthMiddleware,
+		EnrollmentMiddleware:   enrollmentMiddleware,
+		PermissionChecker:      permissionChecker,
+		APIKeyMiddleware:       apiKeyMiddleware,
+	}
+}
+
+func (ro *Router) RegisterRoutes(r *gin.Engine) {
+	apiGroup := r.Group("/api")
+	requirePermission := func(permission string) []gin.HandlerFunc {
+		return []gin.HandlerFunc{ro.APIKeyMiddleware, middlewares.RequirePermission(ro.PermissionChecker, permission), middlewares.AuditActor()}
+	}
+	loginRateLimit := middlewares.RateLimit(ro.RateLimiter, "login", config.AppConfig.LoginLimitPerIP, config.AppConfig.RateLimitWindow)
+	forgotPasswordRateLimit := middlewares.RateLimit(ro.RateLimiter, "forgot_password", config.AppConfig.ForgotPasswordLimitPerIP, config.AppConfig.RateLimitWindow)
+	playRateLimit := middlewares.RateLimit(ro.RateLimiter, "play", config.AppConfig.PlayLimitPerIP, config.AppConfig.RateLimitWindow)
+	playSessionRateLimit := middlewares.RateLimit(ro.RateLimiter, "play_session", config.AppConfig.PlaySessionLimitPerIP, config.AppConfig.RateLimitWindow)
+	voteRateLimit := middlewares.RateLimit(ro.RateLimiter, "vote", config.AppConfig.VoteLimitPerIP, config.AppConfig.RateLimitWindow)
+	{
+		categoryApi := apiGroup.Group("/category")
+		categoryApi.GET("/", ro.CategoryHandler.GetAll)
+		categoryApi.GET("/menu", ro.CategoryHandler.GetMenu)
+		categoryApi.GET("/:id", ro.CategoryHandler.GetByID)
+		categoryAdminApi := categoryApi.Group("", requirePermission(entities.PermissionCategoryWrite)...)
+		categoryAdminApi.POST("", ro.CategoryHandler.Create)
+		categoryAdminApi.PUT("/:id", ro.CategoryHandler.Update)
+		categoryAdminApi.DELETE("/:id", ro.CategoryHandler.Delete)
+
+		userApi := apiGroup.Group("/user")
+		userApi.POST("/forgot-password", forgotPasswordRateLimit, ro.UserHandler.ForgotPassword)
+		userApi.POST("/reset-password", ro.UserHandler.ResetPassword)
+		userReadApi := userApi.Group("", requirePermission(entities.PermissionUserRead)...)
+		userReadApi.GET("/", ro.UserHandler.GetAll)
+		userReadApi.GET("/export", ro.UserHandler.Export)
+		userReadApi.GET("/:id", ro.UserHandler.GetByID)
+		userAdminApi := userApi.Group("", requirePermission(entities.PermissionUserWrite)...)
+		userAdminApi.POST("", ro.UserHandler.Create)
+		userAdminApi.PUT("/:id", ro.UserHandler.Update)
+		userAdminApi.DELETE("/:id", ro.UserHandler.Delete)
+		userAdminApi.POST("/:id/unlock", ro.UserHandler.UnlockAccount)
+		userAdminApi.PUT("/:id/status", ro.UserHandler.SetStatus)
+		userAdminApi.GET("/:id/api-key", ro.APIKeyHandler.GetAllForUser)
+		userAdminApi.POST("/:id/api-key", ro.APIKeyHandler.CreateForUser)
+		userAdminApi.DELETE("/:id/api-key/:key_id", ro.APIKeyHandler.RevokeForUser)
+		userAdminApi.POST("/:id/erasure", ro.DataRequestHandler.RequestErasure)
+		userRoleApi := userApi.Group("", requirePermission(entities.PermissionRoleAssign)...)
+		userRoleApi.PUT("/:id/role", ro.UserHandler.AssignRole)
+
+		meApi := apiGroup.Group("/me", ro.AuthMiddleware)
+		meApi.GET("", ro.AccountHandler.GetProfile)
+		meApi.PUT("", ro.AccountHandler.UpdateProfile)
+		meApi.DELETE("", ro.AccountHandler.DeleteAccount)
+		meApi.PUT("/avatar", ro.AccountHandler.UpdateAvatar)
+		meApi.PUT("/password", ro.AccountHandler.ChangePassword)
+		meApi.PUT("/email", ro.AccountHandler.ChangeEmail)
+		meApi.POST("/export", ro.DataRequestHandler.RequestExport)
+		meApi.GET("/export/:id", ro.DataRequestHandler.GetExport)
+		meApi.GET("/recently-played", ro.PlayHandler.GetRecentlyPlayed)
+		meApi.GET("/playtime", ro.PlayHandler.GetPlaytime)
+		meApi.GET("/favorites", ro.FavoriteHandler.GetAll)
+
+		roleApi := apiGroup.Group("/role", requirePermission(entities.PermissionRoleAssign)...)
+		roleApi.GET("", ro.RoleHandler.GetAll)
+
+		apiKeyApi := apiGroup.Group("/api-key", ro.AuthMiddleware)
+		apiKeyApi.GET("", ro.APIKeyHandler.GetAll)
+		apiKeyApi.POST("", ro.APIKeyHandler.Create)
+		apiKeyApi.DELETE("/:id", ro.APIKeyHandler.Revoke)
+
+		auditApi := apiGroup.Group("/admin/audit", requirePermission(entities.PermissionAuditRead)...)
+		auditApi.GET("", ro.AuditHandler.GetAll)
+
+		adsApi := apiGroup.Group("/ads")
+		adsApi.GET("/", ro.AdsHander.GetAll)
+		adsApi.GET("/:id", ro.AdsHander.GetByID)
+		adsAdminApi := adsApi.Group("", requirePermission(entities.PermissionAdsWrite)...)
+		adsAdminApi.POST("/", ro.AdsHander.Create)
+		adsAdminApi.PUT("/:id", ro.AdsHander.Update)
+		adsAdminApi.DELETE("/:id", ro.AdsHander.Delete)
+
+		gameApi := apiGroup.Group("/game")
+		gameApi.GET("/", ro.OptionalAuthMiddleware, ro.GameHander.GetAll)
+		gameApi.GET("/trending", ro.OptionalAuthMiddleware, ro.RankingHandler.GetTrending)
+		gameApi.GET("/popular", ro.OptionalAuthMiddleware, ro.RankingHandler.GetPopular)
+		gameApi.GET("/new", ro.OptionalAuthMiddleware, ro.RankingHandler.GetNew)
+		gameApi.GET("/:id", ro.OptionalAuthMiddleware, ro.GameHander.GetByID)
+		gameApi.GET("/category/:id", ro.OptionalAuthMiddleware, ro.GameHander.GetByCategoryID)
+		gameApi.POST("/:id/play", playRateLimit, ro.OptionalAuthMiddleware, ro.PlayHandler.Play)
+		gameApi.POST("/:id/play/:session_id/heartbeat", playSessionRateLimit, ro.PlayHandler.Heartbeat)
+		gameApi.POST("/:id/play/:session_id/end", playSessionRateLimit, ro.PlayHandler.EndSession)
+		gameApi.POST("/:id/vote", voteRateLimit, ro.OptionalAuthMiddleware, ro.VoteHandler.Vote)
+		gameApi.POST("/:id/favorite", ro.AuthMiddleware, ro.FavoriteHandler.Add)
+		gameApi.DELETE("/:id/favorite", ro.AuthMiddleware, ro.FavoriteHandler.Remove)
+		gameApi.GET("/:id/reviews", ro.ReviewHandler.GetByGame)
+		gameApi.POST("/:id/reviews", ro.AuthMiddleware, ro.ReviewHandler.Create)
+		gameAdminApi := gameApi.Group("", requirePermission(entities.PermissionGameWrite)...)
+		gameAdminApi.POST("/", ro.GameHander.Create)
+		gameAdminApi.PUT("/:id", ro.GameHander.Update)
+		gameAdminApi.DELETE("/:id", ro.GameHander.Delete)
+		gameAdminApi.PUT("/:id/tags", ro.TagHandler.SetGameTags)
+
+		tagApi := apiGroup.Group("/tag")
+		tagApi.GET("/", ro.TagHandler.GetAll)
+		tagApi.GET("/:slug/games", ro.OptionalAuthMiddleware, ro.TagHandler.GetGames)
+		tagAdminApi := tagApi.Group("", requirePermission(entities.PermissionTagWrite)...)
+		tagAdminApi.POST("", ro.TagHandler.Create)
+		tagAdminApi.PUT("/:id", ro.TagHandler.Update)
+		tagAdminApi.DELETE("/:id", ro.TagHandler.Delete)
+
+		reviewApi := apiGroup.Group("/review", ro.AuthMiddleware)
+		reviewApi.PUT("/:id", ro.ReviewHandler.Update)
+		reviewApi.DELETE("/:id", ro.ReviewHandler.Delete)
+		reviewApi.POST("/:id/helpful", ro.ReviewHandler.Vote)
+		reviewApi.DELETE("/:id/helpful", ro.ReviewHandler.Unvote)
+
+		OAuthApi := apiGroup.Group("/Oauth")
+		OAuthApi.GET("/:provider/login", ro.OAuthHandler.Login)
+		OAuthApi.GET("/:provider/callback", ro.OAuthHandler.Callback)
+		OAuthApi.POST("/exchange", ro.OAuthHandler.Exchange)
+		OAuthApi.GET("/identities", ro.AuthMiddleware, ro.OAuthHandler.GetIdentities)
+		OAuthApi.GET("/:provider/link", ro.AuthMiddleware, ro.OAuthHandler.Link)
+		OAuthApi.DELETE("/:provider/link", ro.AuthMiddleware, ro.OAuthHandler.Unlink)
+
+		authApi := apiGroup.Group("/auth")
+		authApi.POST("/login", loginRateLimit, ro.AuthHandler.Login)
+		authApi.POST("/register", ro.AuthHandler.Register)
+		authApi.POST("/refresh", ro.AuthHandler.Refresh)
+		authApi.POST("/verify-email", ro.AuthHandler.VerifyEmail)
+		authApi.POST("/resend-verification", ro.AuthHandler.ResendVerification)
+		authApi.POST("/logout", ro.AuthMiddleware, ro.AuthHandler.Logout)
+		authApi.POST("/logout-all", ro.AuthMiddleware, ro.AuthHandler.LogoutAll)
+		authApi.POST("/2fa/verify", ro.AuthHandler.VerifyMFA)
+		authApi.POST("/2fa/setup", ro.EnrollmentMiddleware, ro.AuthHandler.SetupTOTP)
+		authApi.POST("/2fa/confirm", ro.EnrollmentMiddleware, ro.AuthHandler.ConfirmTOTP)
+		authApi.POST("/2fa/disable", ro.AuthMiddleware, ro.AuthHandler.DisableTOTP)
+	}
+
+	{
+		docs.SwaggerInfo.BasePath = "/api"
+		r.GET("/docs/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	}
+}
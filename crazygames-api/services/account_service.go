@@ -0,0 +1,273 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+)
+
+var (
+	ErrWrongPassword = errors.New("current password is incorrect")
+	ErrEmailInUse    = errors.New("email already registered")
+	ErrInvalidAvatar = errors.New("avatar must be a PNG, JPEG, GIF or WebP image of at most 2 MB")
+	ErrLoginRequired = errors.New("please log in again to confirm this change")
+)
+
+const maxAvatarSize = 2 << 20
+
+// avatarExtensions are the image types accepted as avatars, by sniffed content type.
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// AccountService is what a logged in user can do with their own account.
+type AccountService struct {
+	userRepo       repositories.UserRepositoryInterface
+	sessionRepo    repositories.SessionRepositoryInterface
+	minioSvc       MinIOServiceInterface
+	authSvc        AuthServiceInterface
+	rateLimitSvc   RateLimitServiceInterface
+	dataRequestSvc DataRequestServiceInterface
+	// recentLogin is how recently users without a password must have logged
+	// in to the session to confirm a change
+	recentLogin time.Duration
+}
+
+// AccountServiceInterface takes the session the user makes the request with,
+// which confirms the changes of users without a password if it is recent.
+type AccountServiceInterface interface {
+	GetProfile(userID uint) (*entities.User, error)
+	UpdateProfile(userID uint, request *request.ProfileRequest) (*entities.User, error)
+	UpdateAvatar(userID uint, avatar *multipart.FileHeader) (*entities.User, error)
+	ChangePassword(userID uint, sessionID string, request *request.ChangePasswordRequest) error
+	ChangeEmail(userID uint, sessionID string, request *request.ChangeEmailRequest) (*entities.User, error)
+	DeleteAccount(userID uint, sessionID string, password string) error
+}
+
+func NewAccountService(userRepo repositories.UserRepositoryInterface, sessionRepo repositories.SessionRepositoryInterface, minioSvc MinIOServiceInterface, authSvc AuthServiceInterface, rateLimitSvc RateLimitServiceInterface, dataRequestSvc DataRequestServiceInterface) *AccountService {
+	return &AccountService{
+		userRepo:       userRepo,
+		sessionRepo:    sessionRepo,
+		minioSvc:       minioSvc,
+		authSvc:        authSvc,
+		rateLimitSvc:   rateLimitSvc,
+		dataRequestSvc: dataRequestSvc,
+		recentLogin:    config.AppConfig.RecentLoginWindow,
+	}
+}
+
+func (s *AccountService) GetProfile(userID uint) (*entities.User, error) {
+	return s.userRepo.GetByID(userID)
+}
+
+func (s *AccountService) UpdateProfile(userID uint, request *request.ProfileRequest) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.DisplayName = strings.TrimSpace(request.DisplayName)
+	user.Bio = strings.TrimSpace(request.Bio)
+	user.Country = strings.ToUpper(request.Country)
+
+	return s.userRepo.Update(context.Background(), user)
+}
+
+// UpdateAvatar stores the new avatar in MinIO and removes the previous one.
+func (s *AccountService) UpdateAvatar(userID uint, avatar *multipart.FileHeader) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if avatar.Size > maxAvatarSize {
+		return nil, ErrInvalidAvatar
+	}
+	file, err := avatar.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tempFile, err := os.CreateTemp("", "avatar-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tempFile.Name())
+
+	// Trust the content rather than the client's file name or content type.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, ErrInvalidAvatar
+	}
+	contentType := http.DetectContentType(head[:n])
+	extension, ok := avatarExtensions[contentType]
+	if !ok {
+		return nil, ErrInvalidAvatar
+	}
+
+	if _, err := tempFile.Write(head[:n]); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tempFile, file); err != nil {
+		return nil, err
+	}
+	tempFile.Close()
+
+	name, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	avatarPath := fmt.Sprintf("avatars/%d/%s%s", user.ID, name, extension)
+	avatarURL, err := s.minioSvc.UploadFile(tempFile.Name(), avatarPath, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	previousPath := user.AvatarPath
+	user.AvatarURL = avatarURL
+	user.AvatarPath = avatarPath
+	user, err = s.userRepo.Update(context.Background(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteAvatar(previousPath)
+	return user, nil
+}
+
+// ChangePassword sets a new password and logs the user out of their other
+// sessions, keeping sessionID.
+func (s *AccountService) ChangePassword(userID uint, sessionID string, request *request.ChangePasswordRequest) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.confirmIdentity(user, sessionID, request.CurrentPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.Password = string(hashedPassword)
+	if _, err := s.userRepo.Update(context.Background(), user); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeOthersForUser(context.Background(), user.ID, sessionID)
+}
+
+// ChangeEmail moves the account to a new email, which has to be verified
+// again. The verification link sent to the previous email stops working.
+func (s *AccountService) ChangeEmail(userID uint, sessionID string, request *request.ChangeEmailRequest) (*entities.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.confirmIdentity(user, sessionID, request.Password); err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(user.Email, request.Email) {
+		return user, nil
+	}
+
+	existingUser, _ := s.userRepo.GetByEmail(request.Email)
+	if existingUser != nil {
+		return nil, ErrEmailInUse
+	}
+
+	user.Email = request.Email
+	user.EmailVerified = false
+	user, err = s.userRepo.Update(context.Background(), user)
+	if err != nil {
+		return nil, err
+	}
+
+	// The user can ask for another email if this one fails.
+	if err := s.authSvc.SendVerificationEmail(user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+// DeleteAccount deletes the user and logs them out everywhere. Their reviews
+// and play history are kept without their name.
+func (s *AccountService) DeleteAccount(userID uint, sessionID string, password string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.confirmIdentity(user, sessionID, password); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Delete(context.Background(), user.ID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(context.Background(), user.ID); err != nil {
+		return err
+	}
+
+	s.deleteAvatar(user.AvatarPath)
+
+	// The erasure cleans up what is left, such as the user's exports
+	if _, err := s.dataRequestSvc.RequestErasure(user.ID); err != nil && !errors.Is(err, ErrDataRequestOpen) {
+		return err
+	}
+	return nil
+}
+
+// confirmIdentity checks the password of a user that has one. Users that only
+// log in through an OAuth provider must have logged in to the session
+// recently instead, so that a stolen access token is not enough.
+func (s *AccountService) confirmIdentity(user *entities.User, sessionID string, password string) error {
+	if user.Password == "" {
+		loggedInAt, err := s.sessionRepo.GetCreatedAt(context.Background(), sessionID)
+		if err != nil {
+			return err
+		}
+		if time.Since(loggedInAt) > s.recentLogin {
+			return ErrLoginRequired
+		}
+		return nil
+	}
+	if err := s.rateLimitSvc.LimitPasswordConfirmation(user.ID); err != nil {
+		return err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		return ErrWrongPassword
+	}
+	return nil
+}
+
+// deleteAvatar removes an avatar that is no longer used. A failure only
+// leaves an orphaned object behind, so it is logged.
+func (s *AccountService) deleteAvatar(avatarPath string) {
+	if avatarPath == "" {
+		return
+	}
+	if err := s.minioSvc.DeleteFile(avatarPath); err != nil {
+		log.Printf("failed to delete avatar %s: %v", avatarPath, err)
+	}
+}
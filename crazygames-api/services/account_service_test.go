@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fakeMinIOService records the uploaded and deleted objects.
+type fakeMinIOService struct {
+	MinIOServiceInterface
+	uploaded []string
+	deleted  []string
+	contents map[string][]byte
+}
+
+func (m *fakeMinIOService) UploadFile(filePath string, destinationPath string, contentType string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if m.contents == nil {
+		m.contents = map[string][]byte{}
+	}
+	m.contents[destinationPath] = content
+	m.uploaded = append(m.uploaded, destinationPath)
+	return "http://minio.local/bucket/" + destinationPath, nil
+}
+
+func (m *fakeMinIOService) GeneratePresignedURL(objectPath string, expiry time.Duration) (string, error) {
+	return "http://minio.local/bucket/" + objectPath + "?signed", nil
+}
+
+func (m *fakeMinIOService) DeleteFile(objectPath string) error {
+	m.deleted = append(m.deleted, objectPath)
+	return nil
+}
+
+func newTestAccountService() (*AccountService, *AuthService, *fakeEmailService, *revokingSessionRepo, *fakeMinIOService) {
+	authSvc, emailSvc := newTestAuthService()
+	sessionRepo := &revokingSessionRepo{}
+	minioSvc := &fakeMinIOService{}
+	dataRequestSvc := NewDataRequestService(newFakeDataRequestRepo(), authSvc.userRepo, sessionRepo, minioSvc)
+	svc := NewAccountService(authSvc.userRepo, sessionRepo, minioSvc, authSvc, authSvc.rateLimitSvc, dataRequestSvc)
+	svc.recentLogin = 5 * time.Minute
+	return svc, authSvc, emailSvc, sessionRepo, minioSvc
+}
+
+// fileHeader returns content as the file of a multipart form.
+func fileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("avatar", name)
+	assert.NoError(t, err)
+	part.Write(content)
+	writer.Close()
+
+	req := httptest.NewRequest("PUT", "/me/avatar", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	_, header, err := req.FormFile("avatar")
+	assert.NoError(t, err, "failed to build multipart file")
+	return header
+}
+
+func Test_AccountPassword(t *testing.T) {
+	svc, authSvc, _, sessionRepo, _ := newTestAccountService()
+	user, err := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	assert.NoError(t, err, "failed to register")
+
+	t.Run("wrong current password should fail", func(t *testing.T) {
+		err := svc.ChangePassword(user.ID, "session", &request.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "new-password"})
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+
+	t.Run("change password should keep only the current session", func(t *testing.T) {
+		err := svc.ChangePassword(user.ID, "session", &request.ChangePasswordRequest{CurrentPassword: "password", NewPassword: "new-password"})
+		assert.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")))
+		assert.Equal(t, []uint{user.ID}, sessionRepo.revoked)
+		assert.Equal(t, "session", sessionRepo.kept)
+	})
+
+	t.Run("password guesses should be rate limited", func(t *testing.T) {
+		var err error
+		for i := 0; i < 3; i++ {
+			err = svc.ChangePassword(user.ID, "session", &request.ChangePasswordRequest{CurrentPassword: "wrong", NewPassword: "other-password"})
+		}
+		var rateLimitErr *RateLimitError
+		assert.ErrorAs(t, err, &rateLimitErr)
+	})
+}
+
+func Test_AccountEmail(t *testing.T) {
+	svc, authSvc, emailSvc, sessionRepo, _ := newTestAccountService()
+	user, _ := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	authSvc.Register(&request.RegisterRequest{Username: "other", Email: "other@example.com", Password: "password"})
+	oldLink := emailSvc.token(t)
+	assert.NoError(t, authSvc.VerifyEmail(oldLink))
+
+	t.Run("email of another user should fail", func(t *testing.T) {
+		_, err := svc.ChangeEmail(user.ID, "session", &request.ChangeEmailRequest{Email: "other@example.com", Password: "password"})
+		assert.ErrorIs(t, err, ErrEmailInUse)
+	})
+
+	t.Run("new email should be verified again", func(t *testing.T) {
+		sent := emailSvc.sent
+		user, err := svc.ChangeEmail(user.ID, "session", &request.ChangeEmailRequest{Email: "new@example.com", Password: "password"})
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.False(t, user.EmailVerified)
+		assert.Equal(t, sent+1, emailSvc.sent, "a verification email should be sent")
+
+		assert.NoError(t, authSvc.VerifyEmail(emailSvc.token(t)))
+		assert.True(t, user.EmailVerified)
+	})
+
+	t.Run("user without password should have logged in recently", func(t *testing.T) {
+		oauthUser := &entities.User{Username: "oauth", Email: "oauth@example.com"}
+		authSvc.userRepo.Create(context.Background(), oauthUser)
+		sessionRepo.loggedInAt = map[string]time.Time{"old": time.Now().Add(-time.Hour), "fresh": time.Now()}
+
+		_, err := svc.ChangeEmail(oauthUser.ID, "old", &request.ChangeEmailRequest{Email: "stolen@example.com"})
+		assert.ErrorIs(t, err, ErrLoginRequired)
+		_, err = svc.ChangeEmail(oauthUser.ID, "unknown", &request.ChangeEmailRequest{Email: "stolen@example.com"})
+		assert.ErrorIs(t, err, ErrLoginRequired)
+		err = svc.ChangePassword(oauthUser.ID, "old", &request.ChangePasswordRequest{NewPassword: "new-password"})
+		assert.ErrorIs(t, err, ErrLoginRequired)
+
+		updated, err := svc.ChangeEmail(oauthUser.ID, "fresh", &request.ChangeEmailRequest{Email: "oauth-new@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, "oauth-new@example.com", updated.Email)
+	})
+}
+
+func Test_AccountAvatar(t *testing.T) {
+	svc, authSvc, _, _, minioSvc := newTestAccountService()
+	user, _ := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 64)...)
+
+	t.Run("file that is not an image should fail", func(t *testing.T) {
+		_, err := svc.UpdateAvatar(user.ID, fileHeader(t, "avatar.png", []byte("<html></html>")))
+		assert.ErrorIs(t, err, ErrInvalidAvatar)
+		assert.Empty(t, minioSvc.uploaded)
+	})
+
+	t.Run("new avatar should replace the previous one", func(t *testing.T) {
+		first, err := svc.UpdateAvatar(user.ID, fileHeader(t, "avatar", png))
+		assert.NoError(t, err)
+		firstPath := first.AvatarPath
+		assert.Regexp(t, `^avatars/1/[0-9a-f]{32}\.png$`, firstPath)
+		assert.Equal(t, "http://minio.local/bucket/"+firstPath, first.AvatarURL)
+
+		_, err = svc.UpdateAvatar(user.ID, fileHeader(t, "avatar", png))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{firstPath}, minioSvc.deleted)
+	})
+}
+
+func Test_DeleteAccount(t *testing.T) {
+	svc, authSvc, _, sessionRepo, minioSvc := newTestAccountService()
+	user, _ := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	user.AvatarPath = "avatars/1/avatar.png"
+
+	t.Run("wrong password should fail", func(t *testing.T) {
+		err := svc.DeleteAccount(user.ID, "session", "wrong")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	})
+
+	t.Run("delete should log out everywhere and remove the avatar", func(t *testing.T) {
+		err := svc.DeleteAccount(user.ID, "session", "password")
+		assert.NoError(t, err)
+
+		_, err = svc.GetProfile(user.ID)
+		assert.Error(t, err, "user should be deleted")
+		assert.Equal(t, []uint{user.ID}, sessionRepo.revoked)
+		assert.Equal(t, []string{"avatars/1/avatar.png"}, minioSvc.deleted)
+
+		open, _ := svc.dataRequestSvc.(*DataRequestService).dataRequestRepo.HasOpen(user.ID, entities.DataRequestErasure)
+		assert.True(t, open, "an erasure should clean up what is left")
+	})
+
+	t.Run("user without password should confirm with a recent login", func(t *testing.T) {
+		oauthUser := &entities.User{Username: "oauth", Email: "oauth@example.com"}
+		authSvc.userRepo.Create(context.Background(), oauthUser)
+		sessionRepo.loggedInAt = map[string]time.Time{"old": time.Now().Add(-time.Hour), "fresh": time.Now()}
+
+		assert.ErrorIs(t, svc.DeleteAccount(oauthUser.ID, "old", ""), ErrLoginRequired)
+		assert.NoError(t, svc.DeleteAccount(oauthUser.ID, "fresh", ""))
+	})
+}
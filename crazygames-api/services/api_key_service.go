@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	apiKeyPrefix = "cg_"
+	// lastUsedResolution is how stale LastUsedAt may get, so that a busy key
+	// does not write on every request.
+	lastUsedResolution = time.Minute
+)
+
+var (
+	ErrInvalidAPIKey  = errors.New("invalid or expired API key")
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	ErrInvalidScope   = errors.New("scope is not granted by the user's role")
+	ErrInvalidExpiry  = errors.New("expiry must be in the future")
+)
+
+type APIKeyService struct {
+	apiKeyRepo repositories.APIKeyRepositoryInterface
+	userRepo   repositories.UserRepositoryInterface
+	roleRepo   repositories.RoleRepositoryInterface
+}
+
+type APIKeyServiceInterface interface {
+	Create(userID uint, request *request.APIKeyRequest) (string, *entities.APIKey, error)
+	GetByUserID(userID uint) ([]entities.APIKey, error)
+	Revoke(userID uint, id uint) error
+	ValidateAPIKey(key string) (*entities.APIKey, error)
+}
+
+func NewAPIKeyService(apiKeyRepo repositories.APIKeyRepositoryInterface, userRepo repositories.UserRepositoryInterface, roleRepo repositories.RoleRepositoryInterface) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo, userRepo: userRepo, roleRepo: roleRepo}
+}
+
+// Create makes a key for userID and returns it with its record. The key is not
+// stored and cannot be shown again.
+func (s *APIKeyService) Create(userID uint, request *request.APIKeyRequest) (string, *entities.APIKey, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, scope := range request.Scopes {
+		granted, err := s.roleRepo.HasPermission(user.Role, scope)
+		if err != nil {
+			return "", nil, err
+		}
+		if !granted {
+			return "", nil, fmt.Errorf("%w: %s", ErrInvalidScope, scope)
+		}
+	}
+
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return "", nil, ErrInvalidExpiry
+	}
+
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return "", nil, errors.New("failed to generate API key")
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := &entities.APIKey{
+		UserID:    user.ID,
+		Name:      request.Name,
+		Prefix:    key[:len(apiKeyPrefix)+8],
+		KeyHash:   utils.HashToken(key),
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return "", nil, err
+	}
+
+	return key, apiKey, nil
+}
+
+func (s *APIKeyService) GetByUserID(userID uint) ([]entities.APIKey, error) {
+	return s.apiKeyRepo.GetByUserID(userID)
+}
+
+func (s *APIKeyService) Revoke(userID uint, id uint) error {
+	err := s.apiKeyRepo.Revoke(userID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	return err
+}
+
+// ValidateAPIKey returns the active key, with its user, and records its use.
+func (s *APIKeyService) ValidateAPIKey(key string) (*entities.APIKey, error) {
+	apiKey, err := s.apiKeyRepo.GetByHash(utils.HashToken(key))
+	if err != nil || apiKey.User == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(now)) {
+		return nil, ErrInvalidAPIKey
+	}
+	// The keys of suspended and banned users work again once they are lifted
+	if apiKey.User.Restricted(now) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > lastUsedResolution {
+		if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID, now); err != nil {
+			log.Printf("Failed to record use of API key %d: %v", apiKey.ID, err)
+		}
+		apiKey.LastUsedAt = &now
+	}
+
+	return apiKey, nil
+}
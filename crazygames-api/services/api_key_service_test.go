@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakeAPIKeyRepo struct {
+	keys     []*entities.APIKey
+	userRepo *fakeUserRepo
+	touched  int
+}
+
+func (r *fakeAPIKeyRepo) Create(key *entities.APIKey) error {
+	key.ID = uint(len(r.keys) + 1)
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) GetByHash(keyHash string) (*entities.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			key.User, _ = r.userRepo.GetByID(key.UserID)
+			return key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepo) GetByUserID(userID uint) ([]entities.APIKey, error) {
+	var keys []entities.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepo) Revoke(userID uint, id uint) error {
+	for _, key := range r.keys {
+		if key.ID == id && key.UserID == userID && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(id uint, usedAt time.Time) error {
+	r.touched++
+	return nil
+}
+
+// editorRoleRepo grants editors the game and category permissions.
+type editorRoleRepo struct {
+	fakeRoleRepo
+}
+
+func (editorRoleRepo) HasPermission(role string, permission string) (bool, error) {
+	return role == entities.RoleEditor && (permission == entities.PermissionGameWrite || permission == entities.PermissionCategoryWrite), nil
+}
+
+func Test_APIKeys(t *testing.T) {
+	userRepo := &fakeUserRepo{}
+	apiKeyRepo := &fakeAPIKeyRepo{userRepo: userRepo}
+	svc := NewAPIKeyService(apiKeyRepo, userRepo, editorRoleRepo{})
+	userRepo.Create(context.Background(), &entities.User{Username: "loader", Email: "loader@example.com", Role: entities.RoleEditor})
+
+	t.Run("scope the role does not grant should fail", func(t *testing.T) {
+		_, _, err := svc.Create(1, &request.APIKeyRequest{Name: "dataloader", Scopes: []string{entities.PermissionAdsWrite}})
+		assert.ErrorIs(t, err, ErrInvalidScope)
+	})
+
+	t.Run("expiry in the past should fail", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		_, _, err := svc.Create(1, &request.APIKeyRequest{Name: "dataloader", Scopes: []string{entities.PermissionGameWrite}, ExpiresAt: &expiresAt})
+		assert.ErrorIs(t, err, ErrInvalidExpiry)
+	})
+
+	key, apiKey, err := svc.Create(1, &request.APIKeyRequest{Name: "dataloader", Scopes: []string{entities.PermissionGameWrite}})
+	assert.NoError(t, err, "failed to create API key")
+
+	t.Run("only the hash and prefix of the key should be stored", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(key, apiKey.Prefix))
+		assert.NotContains(t, apiKey.KeyHash, key[len(apiKey.Prefix):])
+	})
+
+	t.Run("valid key should be accepted and its use recorded once per resolution", func(t *testing.T) {
+		validated, err := svc.ValidateAPIKey(key)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.RoleEditor, validated.User.Role)
+
+		_, err = svc.ValidateAPIKey(key)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, apiKeyRepo.touched)
+	})
+
+	t.Run("unknown key should fail", func(t *testing.T) {
+		_, err := svc.ValidateAPIKey("cg_unknown")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("expired key should fail", func(t *testing.T) {
+		expiredAt := time.Now().Add(-time.Minute)
+		apiKey.ExpiresAt = &expiredAt
+		defer func() { apiKey.ExpiresAt = nil }()
+
+		_, err := svc.ValidateAPIKey(key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("key of a suspended user should fail until the suspension ends", func(t *testing.T) {
+		user, _ := userRepo.GetByID(1)
+		until := time.Now().Add(time.Hour)
+		user.Status = entities.UserSuspended
+		user.SuspendedUntil = &until
+
+		_, err := svc.ValidateAPIKey(key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+
+		ended := time.Now().Add(-time.Minute)
+		user.SuspendedUntil = &ended
+		_, err = svc.ValidateAPIKey(key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("revoked key should fail", func(t *testing.T) {
+		assert.ErrorIs(t, svc.Revoke(2, apiKey.ID), ErrAPIKeyNotFound, "other users should not revoke the key")
+		assert.NoError(t, svc.Revoke(1, apiKey.ID))
+
+		_, err := svc.ValidateAPIKey(key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+}
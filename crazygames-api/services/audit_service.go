@@ -0,0 +1,23 @@
+package services
+
+import (
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+)
+
+type AuditService struct {
+	auditRepo repositories.AuditRepositoryInterface
+}
+
+type AuditServiceInterface interface {
+	GetAll(query request.AuditRequestQuery) ([]entities.AuditEvent, int64, error)
+}
+
+func NewAuditService(auditRepo repositories.AuditRepositoryInterface) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+func (s *AuditService) GetAll(query request.AuditRequestQuery) ([]entities.AuditEvent, int64, error) {
+	return s.auditRepo.GetAll(query)
+}
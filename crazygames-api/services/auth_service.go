@@ -1,84 +1,404 @@
-package services
-
-import (
-	"errors"
-	"log"
-	"time"
-
-	"crazygames.io/config"
-	"crazygames.io/entities"
-	"crazygames.io/handler/request"
-	"crazygames.io/repositories"
-	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
-)
-
-type AuthService struct {
-	userRepo repositories.UserRepositoryInterface
-	secret   string // JWT secret
-}
-
-type AuthServiceInterface interface {
-	Login(request *request.LoginRequest) (string, error)
-	Register(request *request.RegisterRequest) (*entities.User, error)
-}
-
-func NewAuthService(userRepo repositories.UserRepositoryInterface) *AuthService {
-	return &AuthService{
-		userRepo: userRepo,
-		secret:   config.AppConfig.JWTSecret,
-	}
-}
-
-func (s *AuthService) Login(request *request.LoginRequest) (string, error) {
-	user, err := s.userRepo.GetByEmail(request.Email)
-	log.Println("User===> ", user)
-	log.Println("err===> ", err)
-	if err != nil || user == nil {
-		return "", errors.New("invalid email or password")
-	}
-
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password))
-	if err != nil {
-		return "", errors.New("incorrect password")
-	}
-
-	// Generate JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"role":    user.Role,
-		"exp":     time.Now().Add(time.Hour * 72).Unix(), // Token expires in 72 hours
-	})
-
-	tokenString, err := token.SignedString([]byte(s.secret))
-	if err != nil {
-		return "", errors.New("failed to generate token")
-	}
-
-	return tokenString, nil
-}
-
-func (s *AuthService) Register(request *request.RegisterRequest) (*entities.User, error) {
-	existingUser, _ := s.userRepo.GetByEmail(request.Email)
-	if existingUser != nil {
-		return nil, errors.New("email already registered")
-	}
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, errors.New("failed to hash password")
-	}
-
-	user := &entities.User{
-		Username: request.Username,
-		Email:    request.Email,
-		Password: string(hashedPassword),
-		Role:     entities.RolePlayer, // Default role
-	}
-
-	if err := s.userRepo.Create(user); err != nil {
-		return nil, err
-	}
-
-	return user, nil
-}
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidCredentials   = errors.New("invalid email or password")
+	ErrEmailNotVerified     = errors.New("email address has not been verified")
+	ErrVerificationCooldown = errors.New("a verification email was sent recently, please wait before requesting another")
+	ErrInvalidVerification  = errors.New("invalid or expired verification link")
+)
+
+// How long a user has to enter their second factor after the password.
+const mfaTokenTTL = 5 * time.Minute
+
+// AccountRestrictedError is returned when a suspended or banned user tries to
+// log in. It tells them why and, for a suspension, until when.
+type AccountRestrictedError struct {
+	Status string
+	Reason string
+	Until  *time.Time
+}
+
+func (e *AccountRestrictedError) Error() string {
+	message := "account is banned"
+	if e.Status == entities.UserSuspended && e.Until != nil {
+		message = "account is suspended until " + e.Until.UTC().Format(time.RFC3339)
+	}
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	return message
+}
+
+// checkAccountStatus refuses users that are suspended or banned.
+func checkAccountStatus(user *entities.User) error {
+	if !user.Restricted(time.Now()) {
+		return nil
+	}
+	return &AccountRestrictedError{Status: user.Status, Reason: user.StatusReason, Until: user.SuspendedUntil}
+}
+
+type AuthService struct {
+	userRepo         repositories.UserRepositoryInterface
+	sessionRepo      repositories.SessionRepositoryInterface
+	verificationRepo repositories.EmailVerificationRepositoryInterface
+	emailSvc         EmailServiceInterface
+	rateLimitSvc     RateLimitServiceInterface
+	mfaSvc           MFAServiceInterface
+	secret           string // JWT secret
+	accessTokenTTL   time.Duration
+	refreshTokenTTL  time.Duration
+
+	requireEmailVerification bool
+	mfaRequiredForAdmins     bool
+	verificationTTL          time.Duration
+	verificationCooldown     time.Duration
+	verifyURL                string
+}
+
+type AuthServiceInterface interface {
+	Login(request *request.LoginRequest) (*LoginResult, error)
+	VerifyMFA(mfaToken string, code string) (*TokenPair, error)
+	IssueTokensForUser(userID uint) (*TokenPair, error)
+	ValidateEnrollmentToken(tokenString string) (*utils.Claims, error)
+	Register(request *request.RegisterRequest) (*entities.User, error)
+	Refresh(refreshToken string) (*TokenPair, error)
+	Logout(sessionID string) error
+	LogoutAll(userID uint) error
+	ValidateToken(tokenString string) (*utils.Claims, error)
+	VerifyEmail(token string) error
+	ResendVerification(email string) error
+	SendVerificationEmail(user *entities.User) error
+}
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// LoginResult holds the tokens of a finished login. When the user still has to
+// pass two-factor authentication, or enroll in it first, it holds the MFA
+// token for that step instead.
+type LoginResult struct {
+	*TokenPair
+	MFARequired           bool   `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool   `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string `json:"mfa_token,omitempty"`
+}
+
+func NewAuthService(userRepo repositories.UserRepositoryInterface, sessionRepo repositories.SessionRepositoryInterface, verificationRepo repositories.EmailVerificationRepositoryInterface, emailSvc EmailServiceInterface, rateLimitSvc RateLimitServiceInterface, mfaSvc MFAServiceInterface) *AuthService {
+	return &AuthService{
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		verificationRepo: verificationRepo,
+		emailSvc:         emailSvc,
+		rateLimitSvc:     rateLimitSvc,
+		mfaSvc:           mfaSvc,
+		secret:           config.AppConfig.JWTSecret,
+		accessTokenTTL:   config.AppConfig.AccessTokenTTL,
+		refreshTokenTTL:  config.AppConfig.RefreshTokenTTL,
+
+		requireEmailVerification: config.AppConfig.RequireEmailVerification,
+		mfaRequiredForAdmins:     config.AppConfig.MFARequiredForAdmins,
+		verificationTTL:          config.AppConfig.EmailVerificationTTL,
+		verificationCooldown:     config.AppConfig.EmailVerificationCooldown,
+		verifyURL:                config.SMTP.RedirectUrl + "/verify-email",
+	}
+}
+
+// dummyPasswordHash is checked against when the email is unknown, so that
+// unknown emails take as long as wrong passwords.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Login checks the credentials. Unknown emails and wrong passwords fail the
+// same way and count towards the lockout of the email alike.
+func (s *AuthService) Login(request *request.LoginRequest) (*LoginResult, error) {
+	if err := s.rateLimitSvc.CheckLockout(request.Email); err != nil {
+		return nil, err
+	}
+	if err := s.rateLimitSvc.LimitLogin(request.Email); err != nil {
+		return nil, err
+	}
+
+	user, _ := s.userRepo.GetByEmail(request.Email)
+	passwordHash := dummyPasswordHash
+	if user != nil && user.Password != "" {
+		passwordHash = []byte(user.Password)
+	}
+
+	err := bcrypt.CompareHashAndPassword(passwordHash, []byte(request.Password))
+	if err != nil || user == nil || user.Password == "" {
+		if err := s.rateLimitSvc.RecordLoginFailure(request.Email); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.rateLimitSvc.ResetLoginFailures(request.Email); err != nil {
+		return nil, err
+	}
+
+	return s.StartLogin(user)
+}
+
+// StartLogin finishes the login of an authenticated user, or asks for the
+// second factor when the user has one or must set one up.
+func (s *AuthService) StartLogin(user *entities.User) (*LoginResult, error) {
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+
+	mfaEnrollmentRequired := s.mfaRequiredForAdmins && user.Role == entities.RoleAdmin && !user.TOTPEnabled
+	if user.TOTPEnabled || mfaEnrollmentRequired {
+		mfaToken, err := utils.GenerateMFAToken(s.secret, user.ID, mfaTokenTTL)
+		if err != nil {
+			return nil, errors.New("failed to generate token")
+		}
+		return &LoginResult{
+			MFARequired:           user.TOTPEnabled,
+			MFAEnrollmentRequired: mfaEnrollmentRequired,
+			MFAToken:              mfaToken,
+		}, nil
+	}
+
+	tokens, err := s.IssueTokens(user)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{TokenPair: tokens}, nil
+}
+
+// VerifyMFA is the second step of a login: it checks code for the user of
+// mfaToken and issues the tokens.
+func (s *AuthService) VerifyMFA(mfaToken string, code string) (*TokenPair, error) {
+	userID, err := utils.ParseMFAToken(s.secret, mfaToken)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.rateLimitSvc.LimitMFA(userID); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.mfaSvc.CheckCode(user, code); err != nil {
+		return nil, err
+	}
+
+	return s.IssueTokens(user)
+}
+
+// IssueTokensForUser logs in userID once it has been authenticated, e.g. by
+// confirming two-factor enrollment with an MFA token.
+func (s *AuthService) IssueTokensForUser(userID uint) (*TokenPair, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.IssueTokens(user)
+}
+
+// ValidateEnrollmentToken accepts access tokens and MFA tokens, so that users
+// who must enroll in two-factor authentication before logging in can do so.
+func (s *AuthService) ValidateEnrollmentToken(tokenString string) (*utils.Claims, error) {
+	if claims, err := s.ValidateToken(tokenString); err == nil {
+		return claims, nil
+	}
+
+	userID, err := utils.ParseMFAToken(s.secret, tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &utils.Claims{UserID: userID}, nil
+}
+
+// IssueTokens starts a new session for user and returns its first access and
+// refresh tokens. Unverified users are refused when verification is required,
+// suspended and banned users always.
+func (s *AuthService) IssueTokens(user *entities.User) (*TokenPair, error) {
+	if err := checkAccountStatus(user); err != nil {
+		return nil, err
+	}
+	if s.requireEmailVerification && !user.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	familyID, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	refreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	err = s.sessionRepo.Create(context.Background(), familyID, user.ID, utils.HashToken(refreshToken), s.refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenPair(user, familyID, refreshToken)
+}
+
+// Refresh rotates refreshToken. Replaying a refresh token that was already
+// rotated revokes every token of its session.
+func (s *AuthService) Refresh(refreshToken string) (*TokenPair, error) {
+	newRefreshToken, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	familyID, userID, err := s.sessionRepo.Rotate(context.Background(), utils.HashToken(refreshToken), utils.HashToken(newRefreshToken), s.refreshTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		s.sessionRepo.Revoke(context.Background(), familyID)
+		return nil, repositories.ErrRefreshTokenInvalid
+	}
+	if err := checkAccountStatus(user); err != nil {
+		s.sessionRepo.Revoke(context.Background(), familyID)
+		return nil, err
+	}
+
+	return s.tokenPair(user, familyID, newRefreshToken)
+}
+
+func (s *AuthService) Logout(sessionID string) error {
+	return s.sessionRepo.Revoke(context.Background(), sessionID)
+}
+
+func (s *AuthService) LogoutAll(userID uint) error {
+	return s.sessionRepo.RevokeAllForUser(context.Background(), userID)
+}
+
+func (s *AuthService) tokenPair(user *entities.User, familyID string, refreshToken string) (*TokenPair, error) {
+	accessToken, err := utils.GenerateToken(s.secret, user.ID, user.Role, familyID, s.accessTokenTTL)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *AuthService) Register(request *request.RegisterRequest) (*entities.User, error) {
+	existingUser, _ := s.userRepo.GetByEmail(request.Email)
+	if existingUser != nil {
+		return nil, errors.New("email already registered")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	user := &entities.User{
+		Username: request.Username,
+		Email:    request.Email,
+		Password: string(hashedPassword),
+		Role:     entities.RolePlayer, // Default role
+	}
+
+	if err := s.userRepo.Create(context.Background(), user); err != nil {
+		return nil, err
+	}
+
+	// The user can ask for another email if this one fails.
+	if err := s.SendVerificationEmail(user); err != nil {
+		log.Printf("failed to send verification email to user %d: %v", user.ID, err)
+	}
+
+	return user, nil
+}
+
+// SendVerificationEmail sends user a link that verifies their current email.
+func (s *AuthService) SendVerificationEmail(user *entities.User) error {
+	token, err := utils.GenerateEmailVerificationToken(s.secret, user.ID, user.Email, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	verifyLink := s.verifyURL + "?token=" + url.QueryEscape(token)
+	return s.emailSvc.SendTemplateEmail(user.Email, "Verify your email", "verify_email_template.html", map[string]string{"VerifyLink": verifyLink})
+}
+
+// VerifyEmail marks the email in token as verified. The token no longer works
+// once the user has changed their email.
+func (s *AuthService) VerifyEmail(token string) error {
+	userID, email, err := utils.ParseEmailVerificationToken(s.secret, token)
+	if err != nil {
+		return ErrInvalidVerification
+	}
+
+	if err := s.userRepo.MarkEmailVerified(userID, email); err != nil {
+		return ErrInvalidVerification
+	}
+
+	return nil
+}
+
+// ResendVerification sends a new verification email. It succeeds without
+// sending anything for unknown or verified emails, so callers cannot use it to
+// find out which emails are registered.
+func (s *AuthService) ResendVerification(email string) error {
+	allowed, err := s.verificationRepo.StartResendCooldown(context.Background(), email, s.verificationCooldown)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrVerificationCooldown
+	}
+
+	user, _ := s.userRepo.GetByEmail(email)
+	if user == nil || user.EmailVerified {
+		return nil
+	}
+
+	return s.SendVerificationEmail(user)
+}
+
+// ValidateToken checks the access token signature and expiry and that its session has not been revoked.
+func (s *AuthService) ValidateToken(tokenString string) (*utils.Claims, error) {
+	claims, err := utils.ParseToken(s.secret, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := s.sessionRepo.Exists(context.Background(), claims.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, errors.New("session has been revoked")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSessionRepo struct {
+	repositories.SessionRepositoryInterface
+}
+
+func (fakeSessionRepo) Create(ctx context.Context, familyID string, userID uint, refreshTokenHash string, ttl time.Duration) error {
+	return nil
+}
+
+type fakeVerificationRepo struct {
+	cooldowns map[string]bool
+}
+
+func (r *fakeVerificationRepo) StartResendCooldown(ctx context.Context, email string, cooldown time.Duration) (bool, error) {
+	if r.cooldowns[email] {
+		return false, nil
+	}
+	r.cooldowns[email] = true
+	return true, nil
+}
+
+// fakeEmailService remembers the last verification link instead of sending it.
+type fakeEmailService struct {
+	EmailServiceInterface
+	sent       int
+	verifyLink string
+}
+
+func (s *fakeEmailService) SendTemplateEmail(to string, subject string, templateName string, data interface{}) error {
+	s.sent++
+	s.verifyLink = data.(map[string]string)["VerifyLink"]
+	return nil
+}
+
+func (s *fakeEmailService) token(t *testing.T) string {
+	link, err := url.Parse(s.verifyLink)
+	assert.NoError(t, err, "failed to parse verify link")
+	return link.Query().Get("token")
+}
+
+func newTestAuthService() (*AuthService, *fakeEmailService) {
+	emailSvc := &fakeEmailService{}
+	svc := NewAuthService(&fakeUserRepo{}, fakeSessionRepo{}, &fakeVerificationRepo{cooldowns: map[string]bool{}}, emailSvc, nil, nil)
+	svc.secret = "test-secret"
+	svc.accessTokenTTL = time.Minute
+	svc.verificationTTL = time.Hour
+	svc.verifyURL = "http://localhost:3000/verify-email"
+	svc.rateLimitSvc = newTestRateLimitService(newFakeRateLimitRepo())
+	svc.mfaSvc = newTestMFAService(svc.userRepo, svc.rateLimitSvc)
+	return svc, emailSvc
+}
+
+func Test_EmailVerification(t *testing.T) {
+	svc, emailSvc := newTestAuthService()
+	svc.requireEmailVerification = true
+
+	user, err := svc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	assert.NoError(t, err, "failed to register")
+	assert.False(t, user.EmailVerified)
+	assert.Equal(t, 1, emailSvc.sent, "register should send a verification email")
+	assert.True(t, strings.HasPrefix(emailSvc.verifyLink, "http://localhost:3000/verify-email?token="))
+
+	t.Run("unverified user should not log in when verification is required", func(t *testing.T) {
+		_, err := svc.Login(&request.LoginRequest{Email: "player@example.com", Password: "password"})
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+	})
+
+	t.Run("invalid token should fail", func(t *testing.T) {
+		err := svc.VerifyEmail("not-a-token")
+		assert.ErrorIs(t, err, ErrInvalidVerification)
+	})
+
+	t.Run("token for a changed email should fail", func(t *testing.T) {
+		token := emailSvc.token(t)
+		user.Email = "changed@example.com"
+		defer func() { user.Email = "player@example.com" }()
+
+		err := svc.VerifyEmail(token)
+		assert.ErrorIs(t, err, ErrInvalidVerification)
+	})
+
+	t.Run("valid token should verify the email", func(t *testing.T) {
+		err := svc.VerifyEmail(emailSvc.token(t))
+		assert.NoError(t, err)
+		assert.True(t, user.EmailVerified)
+
+		tokens, err := svc.Login(&request.LoginRequest{Email: "player@example.com", Password: "password"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+	})
+}
+
+func Test_ResendVerification(t *testing.T) {
+	svc, emailSvc := newTestAuthService()
+	svc.userRepo.Create(context.Background(), &entities.User{Username: "player", Email: "player@example.com"})
+	svc.userRepo.Create(context.Background(), &entities.User{Username: "verified", Email: "verified@example.com", EmailVerified: true})
+
+	t.Run("resend should send a new email", func(t *testing.T) {
+		err := svc.ResendVerification("player@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, emailSvc.sent)
+	})
+
+	t.Run("resend during cooldown should fail", func(t *testing.T) {
+		err := svc.ResendVerification("player@example.com")
+		assert.ErrorIs(t, err, ErrVerificationCooldown)
+		assert.Equal(t, 1, emailSvc.sent)
+	})
+
+	t.Run("unknown and verified emails should look the same without sending", func(t *testing.T) {
+		assert.NoError(t, svc.ResendVerification("unknown@example.com"))
+		assert.NoError(t, svc.ResendVerification("verified@example.com"))
+		assert.Equal(t, 1, emailSvc.sent)
+	})
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+)
+
+var (
+	ErrDataRequestOpen     = errors.New("a request of this kind is already in progress")
+	ErrDataRequestNotFound = errors.New("data request not found")
+)
+
+// A request still processing after this long lost its worker and is retried.
+const staleDataRequestAge = time.Hour
+
+// DataRequestService carries out the personal data exports and erasures that
+// users ask for, in the background.
+type DataRequestService struct {
+	dataRequestRepo repositories.DataRequestRepositoryInterface
+	userRepo        repositories.UserRepositoryInterface
+	sessionRepo     repositories.SessionRepositoryInterface
+	minioSvc        MinIOServiceInterface
+	exportTTL       time.Duration
+	linkTTL         time.Duration
+}
+
+type DataRequestServiceInterface interface {
+	RequestExport(userID uint) (*entities.DataRequest, error)
+	GetExport(userID uint, id uint) (*entities.DataRequest, error)
+	RequestErasure(userID uint) (*entities.DataRequest, error)
+	ProcessPending() error
+	PurgeExpiredExports() error
+}
+
+func NewDataRequestService(dataRequestRepo repositories.DataRequestRepositoryInterface, userRepo repositories.UserRepositoryInterface, sessionRepo repositories.SessionRepositoryInterface, minioSvc MinIOServiceInterface) *DataRequestService {
+	return &DataRequestService{
+		dataRequestRepo: dataRequestRepo,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		minioSvc:        minioSvc,
+		exportTTL:       config.AppConfig.DataExportTTL,
+		linkTTL:         config.AppConfig.DataExportLinkTTL,
+	}
+}
+
+func (s *DataRequestService) RequestExport(userID uint) (*entities.DataRequest, error) {
+	return s.queue(userID, entities.DataRequestExport)
+}
+
+// GetExport returns the export id of the user with, once it is ready, a
+// short-lived link to download it.
+func (s *DataRequestService) GetExport(userID uint, id uint) (*entities.DataRequest, error) {
+	request, _ := s.dataRequestRepo.GetByID(userID, id)
+	if request == nil || request.Type != entities.DataRequestExport {
+		return nil, ErrDataRequestNotFound
+	}
+
+	if request.Status == entities.DataRequestCompleted && request.ObjectPath != "" {
+		downloadURL, err := s.minioSvc.GeneratePresignedURL(request.ObjectPath, s.linkTTL)
+		if err != nil {
+			return nil, err
+		}
+		request.DownloadURL = downloadURL
+	}
+	return request, nil
+}
+
+// RequestErasure queues the erasure of everything tied to the user ID. It
+// also works for a user that is already deleted, to clean up what is left.
+func (s *DataRequestService) RequestErasure(userID uint) (*entities.DataRequest, error) {
+	return s.queue(userID, entities.DataRequestErasure)
+}
+
+func (s *DataRequestService) queue(userID uint, requestType string) (*entities.DataRequest, error) {
+	open, err := s.dataRequestRepo.HasOpen(userID, requestType)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, ErrDataRequestOpen
+	}
+
+	request := &entities.DataRequest{UserID: userID, Type: requestType, Status: entities.DataRequestPending}
+	if err := s.dataRequestRepo.Create(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// ProcessPending carries out the pending requests one after the other. A
+// request that fails is marked as failed with the error.
+func (s *DataRequestService) ProcessPending() error {
+	if err := s.dataRequestRepo.ReleaseStale(time.Now().Add(-staleDataRequestAge)); err != nil {
+		return err
+	}
+
+	for {
+		request, err := s.dataRequestRepo.ClaimNext()
+		if err != nil {
+			return err
+		}
+		if request == nil {
+			return nil
+		}
+
+		switch request.Type {
+		case entities.DataRequestExport:
+			err = s.export(request)
+		case entities.DataRequestErasure:
+			err = s.erase(request.UserID)
+		default:
+			err = fmt.Errorf("unknown data request type %q", request.Type)
+		}
+
+		now := time.Now()
+		request.Status = entities.DataRequestCompleted
+		request.CompletedAt = &now
+		if err != nil {
+			log.Printf("data request %d failed: %v", request.ID, err)
+			request.Status = entities.DataRequestFailed
+			request.Error = err.Error()
+		}
+		if err := s.dataRequestRepo.Update(request); err != nil {
+			return err
+		}
+	}
+}
+
+// export uploads a ZIP of the user's personal data and keeps it until it
+// expires.
+func (s *DataRequestService) export(request *entities.DataRequest) error {
+	data, err := s.dataRequestRepo.GetPersonalData(request.UserID)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp("", "data-export-*.zip")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	if err := writeDataExport(tempFile, data); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		return err
+	}
+
+	name, err := utils.RandomToken(16)
+	if err != nil {
+		return err
+	}
+	objectPath := fmt.Sprintf("exports/%d/%s.zip", request.UserID, name)
+	if _, err := s.minioSvc.UploadFile(tempFile.Name(), objectPath, "application/zip"); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.exportTTL)
+	request.ObjectPath = objectPath
+	request.ExpiresAt = &expiresAt
+	return nil
+}
+
+// writeDataExport writes data as a ZIP with one JSON file per kind of data.
+func writeDataExport(w io.Writer, data *repositories.PersonalData) error {
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"account.json", data.Account},
+		{"play_history.json", data.PlayHistory},
+		{"favorites.json", data.Favorites},
+		{"reviews.json", data.Reviews},
+		{"identities.json", data.Identities},
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.content, "", "  ")
+		if err != nil {
+			return err
+		}
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// erase deletes the user with their avatar and exports and logs them out.
+// Their reviews and play history are kept without their name.
+func (s *DataRequestService) erase(userID uint) error {
+	user, _ := s.userRepo.GetByID(userID)
+	if user != nil && user.AvatarPath != "" {
+		if err := s.minioSvc.DeleteFile(user.AvatarPath); err != nil {
+			return err
+		}
+	}
+
+	if err := s.userRepo.Delete(context.Background(), userID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(context.Background(), userID); err != nil {
+		return err
+	}
+
+	exports, err := s.dataRequestRepo.GetExportsWithFile(userID)
+	if err != nil {
+		return err
+	}
+	for i := range exports {
+		if err := s.deleteExportFile(&exports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeExpiredExports deletes the files of the exports past their expiry.
+func (s *DataRequestService) PurgeExpiredExports() error {
+	exports, err := s.dataRequestRepo.GetExpiredExports(time.Now())
+	if err != nil {
+		return err
+	}
+	for i := range exports {
+		if err := s.deleteExportFile(&exports[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DataRequestService) deleteExportFile(request *entities.DataRequest) error {
+	if err := s.minioSvc.DeleteFile(request.ObjectPath); err != nil {
+		return err
+	}
+	request.ObjectPath = ""
+	request.Status = entities.DataRequestExpired
+	return s.dataRequestRepo.Update(request)
+}
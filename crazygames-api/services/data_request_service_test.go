@@ -0,0 +1,231 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeDataRequestRepo struct {
+	repositories.DataRequestRepositoryInterface
+	requests []*entities.DataRequest
+	data     map[uint]*repositories.PersonalData
+}
+
+func newFakeDataRequestRepo() *fakeDataRequestRepo {
+	return &fakeDataRequestRepo{data: map[uint]*repositories.PersonalData{}}
+}
+
+func (r *fakeDataRequestRepo) Create(request *entities.DataRequest) error {
+	request.ID = uint(len(r.requests) + 1)
+	r.requests = append(r.requests, request)
+	return nil
+}
+
+func (r *fakeDataRequestRepo) GetByID(userID uint, id uint) (*entities.DataRequest, error) {
+	for _, request := range r.requests {
+		if request.ID == id && request.UserID == userID {
+			found := *request
+			return &found, nil
+		}
+	}
+	return nil, assert.AnError
+}
+
+func (r *fakeDataRequestRepo) HasOpen(userID uint, requestType string) (bool, error) {
+	for _, request := range r.requests {
+		if request.UserID == userID && request.Type == requestType &&
+			(request.Status == entities.DataRequestPending || request.Status == entities.DataRequestProcessing) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeDataRequestRepo) ClaimNext() (*entities.DataRequest, error) {
+	for _, request := range r.requests {
+		if request.Status == entities.DataRequestPending {
+			request.Status = entities.DataRequestProcessing
+			claimed := *request
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeDataRequestRepo) ReleaseStale(before time.Time) error {
+	return nil
+}
+
+func (r *fakeDataRequestRepo) Update(request *entities.DataRequest) error {
+	for i, stored := range r.requests {
+		if stored.ID == request.ID {
+			updated := *request
+			r.requests[i] = &updated
+		}
+	}
+	return nil
+}
+
+func (r *fakeDataRequestRepo) GetExportsWithFile(userID uint) ([]entities.DataRequest, error) {
+	var exports []entities.DataRequest
+	for _, request := range r.requests {
+		if request.UserID == userID && request.Type == entities.DataRequestExport && request.ObjectPath != "" {
+			exports = append(exports, *request)
+		}
+	}
+	return exports, nil
+}
+
+func (r *fakeDataRequestRepo) GetExpiredExports(before time.Time) ([]entities.DataRequest, error) {
+	var exports []entities.DataRequest
+	for _, request := range r.requests {
+		if request.Type == entities.DataRequestExport && request.ObjectPath != "" && request.ExpiresAt.Before(before) {
+			exports = append(exports, *request)
+		}
+	}
+	return exports, nil
+}
+
+func (r *fakeDataRequestRepo) GetPersonalData(userID uint) (*repositories.PersonalData, error) {
+	data, ok := r.data[userID]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return data, nil
+}
+
+func newTestDataRequestService() (*DataRequestService, *fakeDataRequestRepo, *AuthService, *revokingSessionRepo, *fakeMinIOService) {
+	authSvc, _ := newTestAuthService()
+	repo := newFakeDataRequestRepo()
+	sessionRepo := &revokingSessionRepo{}
+	minioSvc := &fakeMinIOService{}
+	svc := NewDataRequestService(repo, authSvc.userRepo, sessionRepo, minioSvc)
+	svc.exportTTL = time.Hour
+	svc.linkTTL = time.Minute
+	return svc, repo, authSvc, sessionRepo, minioSvc
+}
+
+// readZip returns the files of a ZIP by name.
+func readZip(t *testing.T, content []byte) map[string][]byte {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		f, err := file.Open()
+		assert.NoError(t, err)
+		files[file.Name], _ = io.ReadAll(f)
+		f.Close()
+	}
+	return files
+}
+
+func Test_DataExport(t *testing.T) {
+	svc, repo, authSvc, _, minioSvc := newTestDataRequestService()
+	user, _ := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	repo.data[user.ID] = &repositories.PersonalData{
+		Account:   user,
+		Favorites: []entities.Favorite{{UserID: user.ID, GameID: 7}},
+	}
+
+	var export *entities.DataRequest
+	t.Run("export should be queued once", func(t *testing.T) {
+		var err error
+		export, err = svc.RequestExport(user.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.DataRequestPending, export.Status)
+
+		_, err = svc.RequestExport(user.ID)
+		assert.ErrorIs(t, err, ErrDataRequestOpen)
+	})
+
+	t.Run("pending export should have no link", func(t *testing.T) {
+		pending, err := svc.GetExport(user.ID, export.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, pending.DownloadURL)
+	})
+
+	t.Run("processed export should be a zip of json files", func(t *testing.T) {
+		assert.NoError(t, svc.ProcessPending())
+
+		done, err := svc.GetExport(user.ID, export.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.DataRequestCompleted, done.Status)
+		assert.NotNil(t, done.ExpiresAt)
+		assert.Contains(t, done.DownloadURL, "?signed")
+
+		files := readZip(t, minioSvc.contents[done.ObjectPath])
+		assert.Len(t, files, 5)
+
+		var account map[string]interface{}
+		assert.NoError(t, json.Unmarshal(files["account.json"], &account))
+		assert.Equal(t, "player@example.com", account["email"])
+		assert.NotContains(t, string(files["account.json"]), "password")
+
+		var favorites []entities.Favorite
+		assert.NoError(t, json.Unmarshal(files["favorites.json"], &favorites))
+		assert.Len(t, favorites, 1)
+	})
+
+	t.Run("export of another user should not be found", func(t *testing.T) {
+		_, err := svc.GetExport(user.ID+1, export.ID)
+		assert.ErrorIs(t, err, ErrDataRequestNotFound)
+	})
+
+	t.Run("expired export should lose its file", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		repo.requests[0].ExpiresAt = &past
+		assert.NoError(t, svc.PurgeExpiredExports())
+
+		expired, _ := svc.GetExport(user.ID, export.ID)
+		assert.Equal(t, entities.DataRequestExpired, expired.Status)
+		assert.Empty(t, expired.DownloadURL)
+		assert.Len(t, minioSvc.deleted, 1)
+	})
+
+	t.Run("failed export should keep the error", func(t *testing.T) {
+		failed, _ := svc.RequestExport(user.ID + 1)
+		assert.NoError(t, svc.ProcessPending())
+
+		failed, _ = svc.GetExport(user.ID+1, failed.ID)
+		assert.Equal(t, entities.DataRequestFailed, failed.Status)
+		assert.NotEmpty(t, failed.Error)
+	})
+}
+
+func Test_DataErasure(t *testing.T) {
+	svc, repo, authSvc, sessionRepo, minioSvc := newTestDataRequestService()
+	user, _ := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	user.AvatarPath = "avatars/1/avatar.png"
+	repo.data[user.ID] = &repositories.PersonalData{Account: user}
+
+	svc.RequestExport(user.ID)
+	svc.ProcessPending()
+	exportPath := repo.requests[0].ObjectPath
+
+	_, err := svc.RequestErasure(user.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, svc.ProcessPending())
+
+	_, err = authSvc.userRepo.GetByID(user.ID)
+	assert.Error(t, err, "user should be deleted")
+	assert.Equal(t, []uint{user.ID}, sessionRepo.revoked)
+	assert.ElementsMatch(t, []string{"avatars/1/avatar.png", exportPath}, minioSvc.deleted)
+	assert.Equal(t, entities.DataRequestExpired, repo.requests[0].Status)
+	assert.Equal(t, entities.DataRequestCompleted, repo.requests[1].Status)
+
+	t.Run("erasure of a deleted user should succeed", func(t *testing.T) {
+		svc.RequestErasure(user.ID)
+		assert.NoError(t, svc.ProcessPending())
+		assert.Equal(t, entities.DataRequestCompleted, repo.requests[2].Status)
+	})
+}
@@ -1,28 +1,57 @@
-package services
-
-import (
-	"fmt"
-
-	"crazygames.io/config"
-	"github.com/go-gomail/gomail"
-)
-
-type EmailService struct{}
-
-func NewEmailService() *EmailService {
-	return &EmailService{}
-}
-
-func (s *EmailService) SendEmail(to string, subject string, body string) error {
-	mail := gomail.NewMessage()
-	mail.SetHeader("From", config.SMTP.Username)
-	mail.SetHeader("To", to)
-	mail.SetHeader("Subject", subject)
-	mail.SetBody("text/html", body)
-
-	dialer := gomail.NewDialer(config.SMTP.Host, config.SMTP.Port, config.SMTP.Username, config.SMTP.Password)
-	if err := dialer.DialAndSend(mail); err != nil {
-		return fmt.Errorf("failed to send email: %w", err)
-	}
-	return nil
-}
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+
+	"crazygames.io/config"
+	"github.com/go-gomail/gomail"
+)
+
+type EmailService struct{}
+
+type EmailServiceInterface interface {
+	SendEmail(to string, subject string, body string) error
+	SendTemplateEmail(to string, subject string, templateName string, data interface{}) error
+}
+
+func NewEmailService() *EmailService {
+	return &EmailService{}
+}
+
+func (s *EmailService) SendEmail(to string, subject string, body string) error {
+	mail := gomail.NewMessage()
+	mail.SetHeader("From", config.SMTP.Username)
+	mail.SetHeader("To", to)
+	mail.SetHeader("Subject", subject)
+	mail.SetBody("text/html", body)
+
+	dialer := gomail.NewDialer(config.SMTP.Host, config.SMTP.Port, config.SMTP.Username, config.SMTP.Password)
+	if err := dialer.DialAndSend(mail); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// SendTemplateEmail sends the HTML template templates/<templateName> filled in with data.
+func (s *EmailService) SendTemplateEmail(to string, subject string, templateName string, data interface{}) error {
+	htmlContent, err := os.ReadFile(filepath.Join("templates", templateName))
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("email").Parse(string(htmlContent))
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, data); err != nil {
+		return err
+	}
+
+	return s.SendEmail(to, subject, body.String())
+}
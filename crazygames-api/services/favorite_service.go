@@ -0,0 +1,72 @@
+package services
+
+import (
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+)
+
+type FavoriteService struct {
+	favoriteRepo repositories.FavoriteRepositoryInterface
+	gameRepo     repositories.GameRepositoryInterface
+}
+
+type FavoriteServiceInterface interface {
+	Add(userID uint, gameID uint) error
+	Remove(userID uint, gameID uint) error
+	GetByUser(userID uint, query request.FavoritesRequestQuery) ([]entities.Favorite, int64, error)
+}
+
+func NewFavoriteService(favoriteRepo repositories.FavoriteRepositoryInterface, gameRepo repositories.GameRepositoryInterface) *FavoriteService {
+	return &FavoriteService{favoriteRepo: favoriteRepo, gameRepo: gameRepo}
+}
+
+// Add makes the game a favorite of the user. Adding it twice keeps one.
+func (s *FavoriteService) Add(userID uint, gameID uint) error {
+	exists, err := s.gameRepo.Exists(gameID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrGameNotFound
+	}
+	return s.favoriteRepo.Add(userID, gameID)
+}
+
+func (s *FavoriteService) Remove(userID uint, gameID uint) error {
+	return s.favoriteRepo.Remove(userID, gameID)
+}
+
+func (s *FavoriteService) GetByUser(userID uint, query request.FavoritesRequestQuery) ([]entities.Favorite, int64, error) {
+	favorites, total, err := s.favoriteRepo.GetByUser(userID, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, favorite := range favorites {
+		if favorite.Game != nil {
+			favorite.Game.IsFavorite = true
+		}
+	}
+	return favorites, total, nil
+}
+
+// withFavorites flags the games that are favorites of the user, with one
+// query for all of them.
+func withFavorites(favoriteRepo repositories.FavoriteRepositoryInterface, userID uint, games []entities.Game) error {
+	if len(games) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(games))
+	for i, game := range games {
+		ids[i] = game.ID
+	}
+	favorites, err := favoriteRepo.GetFavoriteGameIDs(userID, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range games {
+		games[i].IsFavorite = favorites[games[i].ID]
+	}
+	return nil
+}
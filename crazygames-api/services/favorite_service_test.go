@@ -0,0 +1,95 @@
+package services
+
+import (
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeFavoriteRepo struct {
+	repositories.FavoriteRepositoryInterface
+	favorites map[uint]bool
+	lookups   int
+}
+
+func (r *fakeFavoriteRepo) Add(userID uint, gameID uint) error {
+	r.favorites[gameID] = true
+	return nil
+}
+
+func (r *fakeFavoriteRepo) GetByUser(userID uint, query request.FavoritesRequestQuery) ([]entities.Favorite, int64, error) {
+	var favorites []entities.Favorite
+	for gameID := range r.favorites {
+		favorites = append(favorites, entities.Favorite{UserID: userID, GameID: gameID, Game: &entities.Game{ID: gameID}})
+	}
+	return favorites, int64(len(favorites)), nil
+}
+
+func (r *fakeFavoriteRepo) GetFavoriteGameIDs(userID uint, gameIDs []uint) (map[uint]bool, error) {
+	r.lookups++
+	return r.favorites, nil
+}
+
+type fakeListGameRepo struct {
+	repositories.GameRepositoryInterface
+	games []entities.Game
+}
+
+func (r *fakeListGameRepo) GetAll(query request.GamesRequestQuery) ([]entities.Game, int64, error) {
+	return append([]entities.Game(nil), r.games...), int64(len(r.games)), nil
+}
+
+func (r *fakeListGameRepo) GetByID(id uint) (*entities.Game, error) {
+	game := r.games[id-1]
+	return &game, nil
+}
+
+func Test_Favorites(t *testing.T) {
+	favoriteRepo := &fakeFavoriteRepo{favorites: map[uint]bool{}}
+	svc := NewFavoriteService(favoriteRepo, &fakeGameRepo{playCounts: map[uint]int64{1: 0, 2: 0}})
+	userID := uint(1)
+
+	t.Run("favorite of an unknown game should fail", func(t *testing.T) {
+		assert.ErrorIs(t, svc.Add(userID, 3), ErrGameNotFound)
+		assert.Empty(t, favoriteRepo.favorites)
+	})
+
+	t.Run("listed favorites should be flagged", func(t *testing.T) {
+		assert.NoError(t, svc.Add(userID, 2))
+		favorites, total, err := svc.GetByUser(userID, request.FavoritesRequestQuery{PageNumber: 1, PageSize: 10})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.True(t, favorites[0].Game.IsFavorite)
+	})
+
+	gameSvc := NewGameService(
+		&fakeListGameRepo{games: []entities.Game{{ID: 1}, {ID: 2}, {ID: 3}}},
+		nil,
+		favoriteRepo,
+	)
+
+	t.Run("games should be flagged with one lookup", func(t *testing.T) {
+		favoriteRepo.lookups = 0
+		games, _, err := gameSvc.GetAll(request.GamesRequestQuery{PageNumber: 1, PageSize: 10}, &userID)
+		assert.NoError(t, err)
+		assert.False(t, games[0].IsFavorite)
+		assert.True(t, games[1].IsFavorite)
+		assert.False(t, games[2].IsFavorite)
+		assert.Equal(t, 1, favoriteRepo.lookups)
+
+		game, err := gameSvc.GetByID(2, &userID)
+		assert.NoError(t, err)
+		assert.True(t, game.IsFavorite)
+	})
+
+	t.Run("games should not be flagged for anonymous users", func(t *testing.T) {
+		favoriteRepo.lookups = 0
+		games, _, err := gameSvc.GetAll(request.GamesRequestQuery{PageNumber: 1, PageSize: 10}, nil)
+		assert.NoError(t, err)
+		assert.False(t, games[1].IsFavorite)
+		assert.Zero(t, favoriteRepo.lookups)
+	})
+}
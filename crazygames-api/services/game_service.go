@@ -1,154 +1,226 @@
-package services
-
-import (
-	"fmt"
-	"time"
-
-	"crazygames.io/entities"
-	"crazygames.io/handler/request"
-	"crazygames.io/repositories"
-
-	"os"
-
-	"crazygames.io/utils"
-	"github.com/minio/minio-go/v7"
-)
-
-type GameService struct {
-	gameRepo    repositories.GameRepositoryInterface
-	minioClient *minio.Client
-}
-
-type GameServiceInterface interface {
-	Create(request *request.GameRequestCreate) (*entities.Game, error)
-	GetAll(query request.GamesRequestQuery) ([]entities.Game, int64, error)
-	GetByID(id uint) (*entities.Game, error)
-	GetByCategoryID(id uint) ([]entities.Game, error)
-	Update(id uint, request *request.GameRequestUpdate) (*entities.Game, error)
-	Delete(id uint) error
-	ListByCategory(categoryId uint) ([]entities.Game, error)
-}
-
-func NewGameService(gameRepo repositories.GameRepositoryInterface, minioClient *minio.Client) *GameService {
-	return &GameService{gameRepo: gameRepo, minioClient: minioClient}
-}
-
-func (gs *GameService) Create(request *request.GameRequestCreate) (*entities.Game, error) {
-	// Upload thumbnail to MinIO
-	Thumbnail, err := utils.UploadFileToMinio(gs.minioClient, request.Thumbnail, os.Getenv("MINIO_BUCKET_NAME"))
-	if err != nil {
-		return nil, err
-	}
-
-	hoverVideoUrl, err := utils.UploadFileToMinio(gs.minioClient, request.HoverVideo, os.Getenv("MINIO_BUCKET_NAME"))
-	if err != nil {
-		return nil, err
-	}
-
-	layout := "2006-01-02"
-	date, err := time.Parse(layout, request.ReleaseDate)
-	if err != nil {
-		fmt.Println("Error parsing date:", err)
-		return nil, err
-	}
-
-	game := &entities.Game{
-		GameTitle:     request.GameTitle,
-		Description:   request.Description,
-		Developer:     request.Developer,
-		ReleaseDate:   &date,
-		ThumbnailURL:  Thumbnail,
-		Technology:    request.Technology,
-		Rating:        request.Rating,
-		HoverVideoUrl: hoverVideoUrl,
-		GameURL:       request.GameURL,
-		PlayCount:     request.PlayCount,
-	}
-
-	err = gs.gameRepo.Create(game, request.CategoryID)
-	if err != nil {
-		return nil, err
-	}
-	return game, nil
-}
-
-func (gs *GameService) GetAll(query request.GamesRequestQuery) ([]entities.Game, int64, error) {
-	return gs.gameRepo.GetAll(query)
-}
-
-func (gs *GameService) GetByID(id uint) (*entities.Game, error) {
-	return gs.gameRepo.GetByID(id)
-}
-
-func (gs *GameService) GetByCategoryID(id uint) ([]entities.Game, error) {
-	return gs.gameRepo.GetByCategoryID(id)
-}
-
-func (gs *GameService) Update(id uint, request *request.GameRequestUpdate) (*entities.Game, error) {
-	game, err := gs.GetByID(id)
-	if err != nil {
-		return nil, err
-	}
-
-	if request.Thumbnail != nil {
-		thumbnail, err := utils.UploadFileToMinio(gs.minioClient, request.Thumbnail, os.Getenv("MINIO_BUCKET_NAME"))
-		if err != nil {
-			return nil, err
-		}
-		game.ThumbnailURL = thumbnail
-	}
-
-	if request.HoverVideo != nil {
-		hoverVideoUrl, err := utils.UploadFileToMinio(gs.minioClient, request.HoverVideo, os.Getenv("MINIO_BUCKET_NAME"))
-		if err != nil {
-			return nil, err
-		}
-		game.HoverVideoUrl = hoverVideoUrl
-	}
-
-	if request.ReleaseDate == "" {
-		layout := "2006-01-02"
-		date, err := time.Parse(layout, request.ReleaseDate)
-		if err != nil {
-			fmt.Println("Error parsing date:", err)
-			return nil, err
-		}
-		game.ReleaseDate = &date
-	}
-	if request.GameTitle != "" {
-		game.GameTitle = request.GameTitle
-	}
-	if request.Description != "" {
-		game.Description = request.Description
-	}
-	if request.Developer != "" {
-		game.Developer = request.Developer
-	}
-	if request.Technology != "" {
-		game.Technology = request.Technology
-	}
-	if request.Rating != 0 {
-		game.Rating = request.Rating
-	}
-	if request.GameURL != "" {
-		game.GameURL = request.GameURL
-	}
-	if request.PlayCount != 0 {
-		game.PlayCount = request.PlayCount
-	}
-
-	categoryID := ""
-	if request.CategoryID != "" {
-		categoryID = request.CategoryID
-	}
-
-	return gs.gameRepo.Update(game, categoryID)
-}
-
-func (gs *GameService) Delete(id uint) error {
-	return gs.gameRepo.Delete(id)
-}
-
-func (gs *GameService) ListByCategory(categoryId uint) ([]entities.Game, error) {
-	return gs.gameRepo.ListByCategory(categoryId)
-}
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+
+	"os"
+
+	"crazygames.io/utils"
+	"github.com/minio/minio-go/v7"
+)
+
+var ErrPrimaryCategory = errors.New("the primary category must be one of the game's categories")
+
+type GameService struct {
+	gameRepo     repositories.GameRepositoryInterface
+	minioClient  *minio.Client
+	favoriteRepo repositories.FavoriteRepositoryInterface
+}
+
+// GameServiceInterface reads the games with the user that is logged in, if
+// any, to tell which are their favorites.
+type GameServiceInterface interface {
+	Create(ctx context.Context, request *request.GameRequestCreate) (*entities.Game, error)
+	GetAll(query request.GamesRequestQuery, userID *uint) ([]entities.Game, int64, error)
+	GetByID(id uint, userID *uint) (*entities.Game, error)
+	GetByCategoryID(id uint, userID *uint) ([]entities.Game, error)
+	Update(ctx context.Context, id uint, request *request.GameRequestUpdate) (*entities.Game, error)
+	Delete(ctx context.Context, id uint) error
+	ListByCategory(categoryId uint) ([]entities.Game, error)
+}
+
+func NewGameService(gameRepo repositories.GameRepositoryInterface, minioClient *minio.Client, favoriteRepo repositories.FavoriteRepositoryInterface) *GameService {
+	return &GameService{gameRepo: gameRepo, minioClient: minioClient, favoriteRepo: favoriteRepo}
+}
+
+func (gs *GameService) Create(ctx context.Context, request *request.GameRequestCreate) (*entities.Game, error) {
+	layout := "2006-01-02"
+	date, err := time.Parse(layout, request.ReleaseDate)
+	if err != nil {
+		fmt.Println("Error parsing date:", err)
+		return nil, err
+	}
+
+	primaryCategoryID, err := primaryCategoryID(request.CategoryIDs, request.PrimaryCategoryID, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Check the categories before uploading, so bad ones leave no files behind
+	if err := gs.gameRepo.CheckCategories(request.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	// Upload thumbnail to MinIO
+	Thumbnail, err := utils.UploadFileToMinio(gs.minioClient, request.Thumbnail, os.Getenv("MINIO_BUCKET_NAME"))
+	if err != nil {
+		return nil, err
+	}
+
+	hoverVideoUrl, err := utils.UploadFileToMinio(gs.minioClient, request.HoverVideo, os.Getenv("MINIO_BUCKET_NAME"))
+	if err != nil {
+		return nil, err
+	}
+
+	game := &entities.Game{
+		GameTitle:         request.GameTitle,
+		Description:       request.Description,
+		Developer:         request.Developer,
+		ReleaseDate:       &date,
+		ThumbnailURL:      Thumbnail,
+		Technology:        request.Technology,
+		HoverVideoUrl:     hoverVideoUrl,
+		GameURL:           request.GameURL,
+		PrimaryCategoryID: primaryCategoryID,
+	}
+
+	err = gs.gameRepo.Create(ctx, game, request.CategoryIDs)
+	if err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+func (gs *GameService) GetAll(query request.GamesRequestQuery, userID *uint) ([]entities.Game, int64, error) {
+	games, total, err := gs.gameRepo.GetAll(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return games, total, gs.withDetails(games, userID)
+}
+
+func (gs *GameService) GetByID(id uint, userID *uint) (*entities.Game, error) {
+	game, err := gs.gameRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	games := []entities.Game{*game}
+	if err := gs.withDetails(games, userID); err != nil {
+		return nil, err
+	}
+	return &games[0], nil
+}
+
+func (gs *GameService) GetByCategoryID(id uint, userID *uint) ([]entities.Game, error) {
+	games, err := gs.gameRepo.GetByCategoryID(id)
+	if err != nil {
+		return nil, err
+	}
+	return games, gs.withDetails(games, userID)
+}
+
+// withDetails fills in what is not stored on the games: whether they are
+// favorites of the user if any.
+func (gs *GameService) withDetails(games []entities.Game, userID *uint) error {
+	if userID == nil {
+		return nil
+	}
+	return withFavorites(gs.favoriteRepo, *userID, games)
+}
+
+func (gs *GameService) Update(ctx context.Context, id uint, request *request.GameRequestUpdate) (*entities.Game, error) {
+	game, err := gs.GetByID(id, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ReleaseDate != "" {
+		layout := "2006-01-02"
+		date, err := time.Parse(layout, request.ReleaseDate)
+		if err != nil {
+			fmt.Println("Error parsing date:", err)
+			return nil, err
+		}
+		game.ReleaseDate = &date
+	}
+	if request.GameTitle != "" {
+		game.GameTitle = request.GameTitle
+	}
+	if request.Description != "" {
+		game.Description = request.Description
+	}
+	if request.Developer != "" {
+		game.Developer = request.Developer
+	}
+	if request.Technology != "" {
+		game.Technology = request.Technology
+	}
+	if request.GameURL != "" {
+		game.GameURL = request.GameURL
+	}
+
+	categoryIDs := request.CategoryIDs
+	if categoryIDs == nil {
+		for _, category := range game.Category {
+			categoryIDs = append(categoryIDs, category.ID)
+		}
+	}
+	game.PrimaryCategoryID, err = primaryCategoryID(categoryIDs, request.PrimaryCategoryID, game.PrimaryCategoryID)
+	if err != nil {
+		return nil, err
+	}
+	// Check the categories before uploading, so bad ones leave no files behind
+	if err := gs.gameRepo.CheckCategories(request.CategoryIDs); err != nil {
+		return nil, err
+	}
+
+	if request.Thumbnail != nil {
+		thumbnail, err := utils.UploadFileToMinio(gs.minioClient, request.Thumbnail, os.Getenv("MINIO_BUCKET_NAME"))
+		if err != nil {
+			return nil, err
+		}
+		game.ThumbnailURL = thumbnail
+	}
+
+	if request.HoverVideo != nil {
+		hoverVideoUrl, err := utils.UploadFileToMinio(gs.minioClient, request.HoverVideo, os.Getenv("MINIO_BUCKET_NAME"))
+		if err != nil {
+			return nil, err
+		}
+		game.HoverVideoUrl = hoverVideoUrl
+	}
+
+	return gs.gameRepo.Update(ctx, game, request.CategoryIDs)
+}
+
+// primaryCategoryID picks the primary category among the game's categories:
+// the requested one, else the current one if the game keeps it, else the
+// first.
+func primaryCategoryID(categoryIDs []uint, requested *uint, current *uint) (*uint, error) {
+	has := func(id *uint) bool {
+		for _, categoryID := range categoryIDs {
+			if id != nil && *id == categoryID {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case requested != nil:
+		if !has(requested) {
+			return nil, ErrPrimaryCategory
+		}
+		return requested, nil
+	case has(current):
+		return current, nil
+	case len(categoryIDs) > 0:
+		first := categoryIDs[0]
+		return &first, nil
+	}
+	return nil, nil
+}
+
+func (gs *GameService) Delete(ctx context.Context, id uint) error {
+	return gs.gameRepo.Delete(ctx, id)
+}
+
+func (gs *GameService) ListByCategory(categoryId uint) ([]entities.Game, error) {
+	return gs.gameRepo.ListByCategory(categoryId)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeUpdateGameRepo struct {
+	fakeListGameRepo
+	categoryIDs []uint
+	existing    map[uint]bool
+}
+
+func (r *fakeUpdateGameRepo) CheckCategories(ids []uint) error {
+	for _, id := range ids {
+		if !r.existing[id] {
+			return repositories.ErrCategoryNotFound
+		}
+	}
+	return nil
+}
+
+func (r *fakeUpdateGameRepo) Update(ctx context.Context, game *entities.Game, categoryIDs []uint) (*entities.Game, error) {
+	r.categoryIDs = categoryIDs
+	r.games[game.ID-1] = *game
+	return game, nil
+}
+
+func Test_PrimaryCategory(t *testing.T) {
+	id := func(id uint) *uint { return &id }
+
+	tests := []struct {
+		name        string
+		categoryIDs []uint
+		requested   *uint
+		current     *uint
+		want        *uint
+		err         error
+	}{
+		{"first category by default", []uint{3, 1}, nil, nil, id(3), nil},
+		{"requested category", []uint{3, 1}, id(1), id(3), id(1), nil},
+		{"requested category not listed", []uint{3, 1}, id(2), nil, nil, ErrPrimaryCategory},
+		{"current category kept", []uint{3, 1}, nil, id(1), id(1), nil},
+		{"current category dropped", []uint{3}, nil, id(1), id(3), nil},
+		{"no categories", nil, nil, id(1), nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := primaryCategoryID(tt.categoryIDs, tt.requested, tt.current)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_UpdateGame(t *testing.T) {
+	ctx := context.Background()
+	categories := []entities.Category{{ID: 1}, {ID: 2}}
+	gameRepo := &fakeUpdateGameRepo{fakeListGameRepo: fakeListGameRepo{
+		games: []entities.Game{{ID: 1, GameTitle: "Game", Category: categories}},
+	}, existing: map[uint]bool{1: true, 2: true, 3: true}}
+	svc := NewGameService(gameRepo, nil, nil)
+
+	t.Run("omitted categories should be kept", func(t *testing.T) {
+		game, err := svc.Update(ctx, 1, &request.GameRequestUpdate{GameTitle: "Renamed", ReleaseDate: "2026-10-18"})
+		assert.NoError(t, err)
+		assert.Nil(t, gameRepo.categoryIDs)
+		assert.Equal(t, "Renamed", game.GameTitle)
+		assert.Equal(t, "2026-10-18", game.ReleaseDate.Format("2006-01-02"))
+		assert.Equal(t, uint(1), *game.PrimaryCategoryID)
+	})
+
+	t.Run("primary category should be one of the kept categories", func(t *testing.T) {
+		_, err := svc.Update(ctx, 1, &request.GameRequestUpdate{GameTitle: "Renamed", PrimaryCategoryID: &categories[1].ID})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), *gameRepo.games[0].PrimaryCategoryID)
+
+		other := uint(3)
+		_, err = svc.Update(ctx, 1, &request.GameRequestUpdate{GameTitle: "Renamed", PrimaryCategoryID: &other})
+		assert.ErrorIs(t, err, ErrPrimaryCategory)
+	})
+
+	t.Run("categories should be replaced", func(t *testing.T) {
+		game, err := svc.Update(ctx, 1, &request.GameRequestUpdate{GameTitle: "Renamed", CategoryIDs: []uint{3, 1}})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{3, 1}, gameRepo.categoryIDs)
+		assert.Equal(t, uint(3), *game.PrimaryCategoryID)
+	})
+
+	t.Run("unknown categories should be rejected before uploading", func(t *testing.T) {
+		// No MinIO client: uploading would fail with another error
+		_, err := svc.Update(ctx, 1, &request.GameRequestUpdate{
+			CategoryIDs: []uint{4},
+			Thumbnail:   fileHeader(t, "thumbnail.png", []byte("png")),
+		})
+		assert.ErrorIs(t, err, repositories.ErrCategoryNotFound)
+		assert.Equal(t, []uint{3, 1}, gameRepo.categoryIDs)
+	})
+}
+
+func Test_CreateGame(t *testing.T) {
+	ctx := context.Background()
+	gameRepo := &fakeUpdateGameRepo{existing: map[uint]bool{1: true, 2: true}}
+	svc := NewGameService(gameRepo, nil, nil)
+	create := func(categoryIDs []uint, primaryCategoryID *uint) error {
+		// No MinIO client: uploading would fail with another error
+		_, err := svc.Create(ctx, &request.GameRequestCreate{
+			GameTitle:         "Game",
+			ReleaseDate:       "2026-10-18",
+			CategoryIDs:       categoryIDs,
+			PrimaryCategoryID: primaryCategoryID,
+			Thumbnail:         fileHeader(t, "thumbnail.png", []byte("png")),
+			HoverVideo:        fileHeader(t, "hover.mp4", []byte("mp4")),
+		})
+		return err
+	}
+
+	t.Run("primary category should be checked before uploading", func(t *testing.T) {
+		other := uint(2)
+		assert.ErrorIs(t, create([]uint{1}, &other), ErrPrimaryCategory)
+	})
+
+	t.Run("unknown categories should be rejected before uploading", func(t *testing.T) {
+		assert.ErrorIs(t, create([]uint{1, 4}, nil), repositories.ErrCategoryNotFound)
+	})
+}
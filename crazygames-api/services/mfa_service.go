@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTOTPNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTOTPNotSetUp       = errors.New("two-factor authentication has not been set up")
+	ErrInvalidMFACode     = errors.New("invalid authentication code")
+	ErrMFARequired        = errors.New("two-factor authentication is required for admins")
+)
+
+// TOTPSetup is what the user needs to add the account to an authenticator app.
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAService struct {
+	userRepo         repositories.UserRepositoryInterface
+	recoveryCodeRepo repositories.RecoveryCodeRepositoryInterface
+	rateLimitSvc     RateLimitServiceInterface
+	issuer           string
+	requiredForAdmin bool
+}
+
+type MFAServiceInterface interface {
+	Setup(userID uint) (*TOTPSetup, error)
+	Confirm(userID uint, code string) ([]string, error)
+	Disable(userID uint, code string) error
+	CheckCode(user *entities.User, code string) error
+}
+
+func NewMFAService(userRepo repositories.UserRepositoryInterface, recoveryCodeRepo repositories.RecoveryCodeRepositoryInterface, rateLimitSvc RateLimitServiceInterface) *MFAService {
+	return &MFAService{
+		userRepo:         userRepo,
+		recoveryCodeRepo: recoveryCodeRepo,
+		rateLimitSvc:     rateLimitSvc,
+		issuer:           config.AppConfig.MFAIssuer,
+		requiredForAdmin: config.AppConfig.MFARequiredForAdmins,
+	}
+}
+
+// Setup starts enrollment with a new secret. It is not used for logins until
+// the user confirms it, and cannot replace the secret of an enabled user.
+func (s *MFAService) Setup(userID uint) (*TOTPSetup, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if _, err := s.userRepo.Update(context.Background(), user); err != nil {
+		return nil, err
+	}
+
+	return &TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Confirm enables two-factor authentication once code shows the authenticator
+// was set up, and returns the recovery codes. They are only shown here.
+func (s *MFAService) Confirm(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotSetUp
+	}
+	if err := s.rateLimitSvc.LimitMFA(user.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTOTP(user, code); err != nil {
+		return nil, err
+	}
+
+	codes, codeHashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recoveryCodeRepo.Replace(user.ID, codeHashes); err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabled = true
+	if _, err := s.userRepo.Update(context.Background(), user); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off after checking code, unless the
+// policy makes it mandatory for the user.
+func (s *MFAService) Disable(userID uint, code string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if s.requiredForAdmin && user.Role == entities.RoleAdmin {
+		return ErrMFARequired
+	}
+	if err := s.rateLimitSvc.LimitMFA(user.ID); err != nil {
+		return err
+	}
+
+	if err := s.CheckCode(user, code); err != nil {
+		return err
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByUserID(user.ID); err != nil {
+		return err
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	_, err = s.userRepo.Update(context.Background(), user)
+	return err
+}
+
+// CheckCode accepts a code from the authenticator app or an unused recovery code.
+func (s *MFAService) CheckCode(user *entities.User, code string) error {
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnabled
+	}
+
+	if err := s.checkTOTP(user, code); err == nil {
+		return nil
+	}
+
+	used, err := s.recoveryCodeRepo.Use(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (s *MFAService) checkTOTP(user *entities.User, code string) error {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now(), user.TOTPLastStep)
+	if !ok {
+		return ErrInvalidMFACode
+	}
+
+	user.TOTPLastStep = step
+	_, err := s.userRepo.Update(context.Background(), user)
+	return err
+}
+
+// generateRecoveryCodes returns new codes formatted as xxxxx-xxxxx together with their hashes.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	codeHashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := utils.RandomToken(5)
+		if err != nil {
+			return nil, nil, errors.New("failed to generate recovery codes")
+		}
+		codes[i] = code[:5] + "-" + code[5:]
+		codeHashes[i] = utils.HashToken(code)
+	}
+	return codes, codeHashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
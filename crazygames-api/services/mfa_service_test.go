@@ -0,0 +1,155 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRecoveryCodeRepo struct {
+	codeHashes map[uint]map[string]bool // hash -> used
+}
+
+func (r *fakeRecoveryCodeRepo) Replace(userID uint, codeHashes []string) error {
+	r.codeHashes[userID] = map[string]bool{}
+	for _, codeHash := range codeHashes {
+		r.codeHashes[userID][codeHash] = false
+	}
+	return nil
+}
+
+func (r *fakeRecoveryCodeRepo) Use(userID uint, codeHash string) (bool, error) {
+	used, ok := r.codeHashes[userID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codeHashes[userID][codeHash] = true
+	return true, nil
+}
+
+func (r *fakeRecoveryCodeRepo) DeleteByUserID(userID uint) error {
+	delete(r.codeHashes, userID)
+	return nil
+}
+
+func newTestMFAService(userRepo repositories.UserRepositoryInterface, rateLimitSvc RateLimitServiceInterface) *MFAService {
+	svc := NewMFAService(userRepo, &fakeRecoveryCodeRepo{codeHashes: map[uint]map[string]bool{}}, rateLimitSvc)
+	svc.issuer = "CrazyGames"
+	return svc
+}
+
+// currentCode returns the code the authenticator app of user shows now.
+func currentCode(t *testing.T, user *entities.User) string {
+	code, err := utils.TOTPCode(user.TOTPSecret, time.Now())
+	assert.NoError(t, err, "failed to generate code")
+	return code
+}
+
+func Test_TOTPEnrollment(t *testing.T) {
+	authSvc, _ := newTestAuthService()
+	authSvc.rateLimitSvc.(*RateLimitService).lockoutThreshold = 10
+	svc := authSvc.mfaSvc.(*MFAService)
+	user, _ := authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+
+	t.Run("confirm before setup should fail", func(t *testing.T) {
+		_, err := svc.Confirm(user.ID, "123456")
+		assert.ErrorIs(t, err, ErrTOTPNotSetUp)
+	})
+
+	setup, err := svc.Setup(user.ID)
+	assert.NoError(t, err, "failed to set up")
+	assert.True(t, strings.HasPrefix(setup.ProvisioningURI, "otpauth://totp/CrazyGames:player@example.com?"))
+
+	t.Run("wrong code should not enable", func(t *testing.T) {
+		_, err := svc.Confirm(user.ID, "000000")
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+		assert.False(t, user.TOTPEnabled)
+	})
+
+	var recoveryCodes []string
+	t.Run("valid code should enable and return recovery codes", func(t *testing.T) {
+		recoveryCodes, err = svc.Confirm(user.ID, currentCode(t, user))
+		assert.NoError(t, err)
+		assert.True(t, user.TOTPEnabled)
+		assert.Len(t, recoveryCodes, recoveryCodeCount)
+	})
+
+	t.Run("setup should not replace an enabled secret", func(t *testing.T) {
+		_, err := svc.Setup(user.ID)
+		assert.ErrorIs(t, err, ErrTOTPAlreadyEnabled)
+	})
+
+	t.Run("login should ask for the second factor", func(t *testing.T) {
+		result, err := authSvc.Login(&request.LoginRequest{Email: "player@example.com", Password: "password"})
+		assert.NoError(t, err)
+		assert.True(t, result.MFARequired)
+		assert.Nil(t, result.TokenPair)
+
+		_, err = authSvc.VerifyMFA(result.MFAToken, "000000")
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+
+		tokens, err := authSvc.VerifyMFA(result.MFAToken, strings.ToUpper(recoveryCodes[0]))
+		assert.NoError(t, err, "recovery code should be accepted")
+		assert.NotEmpty(t, tokens.AccessToken)
+	})
+
+	t.Run("recovery code should only work once", func(t *testing.T) {
+		err := svc.CheckCode(user, recoveryCodes[0])
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+	})
+
+	t.Run("used code should not be replayed", func(t *testing.T) {
+		user.TOTPLastStep = time.Now().Unix() / 30
+		err := svc.CheckCode(user, currentCode(t, user))
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+	})
+
+	t.Run("access token should not be accepted as MFA token", func(t *testing.T) {
+		tokens, _ := authSvc.IssueTokensForUser(user.ID)
+		_, err := authSvc.VerifyMFA(tokens.AccessToken, recoveryCodes[1])
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("disable should need a valid code", func(t *testing.T) {
+		assert.ErrorIs(t, svc.Disable(user.ID, "000000"), ErrInvalidMFACode)
+		assert.NoError(t, svc.Disable(user.ID, recoveryCodes[1]))
+		assert.False(t, user.TOTPEnabled)
+		assert.Empty(t, user.TOTPSecret)
+	})
+}
+
+func Test_MFARequiredForAdmins(t *testing.T) {
+	authSvc, _ := newTestAuthService()
+	authSvc.mfaRequiredForAdmins = true
+	svc := authSvc.mfaSvc.(*MFAService)
+	svc.requiredForAdmin = true
+	admin, _ := authSvc.Register(&request.RegisterRequest{Username: "admin", Email: "admin@example.com", Password: "password"})
+	admin.Role = entities.RoleAdmin
+
+	result, err := authSvc.Login(&request.LoginRequest{Email: "admin@example.com", Password: "password"})
+	assert.NoError(t, err)
+	assert.True(t, result.MFAEnrollmentRequired, "admin without 2FA should have to enroll")
+	assert.Nil(t, result.TokenPair)
+
+	claims, err := authSvc.ValidateEnrollmentToken(result.MFAToken)
+	assert.NoError(t, err, "MFA token should allow enrollment")
+	assert.Equal(t, admin.ID, claims.UserID)
+
+	_, err = authSvc.ValidateToken(result.MFAToken)
+	assert.Error(t, err, "MFA token should not be an access token")
+
+	_, err = svc.Setup(admin.ID)
+	assert.NoError(t, err)
+	recoveryCodes, err := svc.Confirm(admin.ID, currentCode(t, admin))
+	assert.NoError(t, err)
+
+	err = svc.Disable(admin.ID, recoveryCodes[0])
+	assert.ErrorIs(t, err, ErrMFARequired)
+	assert.True(t, admin.TOTPEnabled)
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/utils"
+	"golang.org/x/oauth2"
+)
+
+var ErrUnknownProvider = errors.New("unknown oauth provider")
+
+// OAuthUserInfo is the profile every provider maps its userinfo response to.
+type OAuthUserInfo struct {
+	Provider      string
+	Subject       string // the provider's stable user ID
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+}
+
+type OAuthProvider interface {
+	OAuthConfig() *oauth2.Config
+	FetchUserInfo(client *http.Client) (*OAuthUserInfo, error)
+}
+
+// NewOAuthProviders builds the login providers from the configuration. A
+// provider that cannot be set up is logged and left out.
+func NewOAuthProviders(configs map[string]config.Oauth2Config) map[string]OAuthProvider {
+	providers := make(map[string]OAuthProvider, len(configs))
+	for name, conf := range configs {
+		provider, err := NewOAuthProvider(name, conf)
+		if err != nil {
+			log.Printf("OAuth provider %s disabled: %v", name, err)
+			continue
+		}
+		providers[name] = provider
+	}
+	return providers
+}
+
+func NewOAuthProvider(name string, conf config.Oauth2Config) (OAuthProvider, error) {
+	oauthConfig := &oauth2.Config{
+		ClientID:     conf.OauthClientID,
+		ClientSecret: conf.OauthClientSecret,
+		RedirectURL:  conf.RedirectURL,
+		Scopes:       conf.Scopes,
+		Endpoint:     conf.Endpoint,
+	}
+
+	if conf.IssuerURL != "" {
+		return newOIDCProvider(oauthConfig, conf.IssuerURL)
+	}
+
+	switch name {
+	case "google":
+		return &googleProvider{oauthConfig: oauthConfig, userInfoURL: conf.UserInfoURL}, nil
+	case "github":
+		return &githubProvider{oauthConfig: oauthConfig, userURL: conf.UserInfoURL}, nil
+	case "discord":
+		return &discordProvider{oauthConfig: oauthConfig, userInfoURL: conf.UserInfoURL}, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+}
+
+type googleProvider struct {
+	oauthConfig *oauth2.Config
+	userInfoURL string
+}
+
+func (p *googleProvider) OAuthConfig() *oauth2.Config {
+	return p.oauthConfig
+}
+
+func (p *googleProvider) FetchUserInfo(client *http.Client) (*OAuthUserInfo, error) {
+	var userInfo struct {
+		ID            string `json:"id"`
+		Email         string `json:"email"`
+		VerifiedEmail bool   `json:"verified_email"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := utils.FetchUserInfo(client, p.userInfoURL, &userInfo); err != nil {
+		return nil, err
+	}
+
+	return &OAuthUserInfo{
+		Subject:       userInfo.ID,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.VerifiedEmail,
+		Name:          userInfo.Name,
+		AvatarURL:     userInfo.Picture,
+	}, nil
+}
+
+type githubProvider struct {
+	oauthConfig *oauth2.Config
+	userURL     string
+}
+
+func (p *githubProvider) OAuthConfig() *oauth2.Config {
+	return p.oauthConfig
+}
+
+// FetchUserInfo reads the profile and, since the profile only shows a public
+// email, takes the primary verified address from the emails endpoint.
+func (p *githubProvider) FetchUserInfo(client *http.Client) (*OAuthUserInfo, error) {
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := utils.FetchUserInfo(client, p.userURL, &user); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := utils.FetchUserInfo(client, p.userURL+"/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	userInfo := &OAuthUserInfo{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+	if userInfo.Name == "" {
+		userInfo.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			userInfo.Email = email.Email
+			userInfo.EmailVerified = email.Verified
+		}
+	}
+
+	return userInfo, nil
+}
+
+type discordProvider struct {
+	oauthConfig *oauth2.Config
+	userInfoURL string
+}
+
+func (p *discordProvider) OAuthConfig() *oauth2.Config {
+	return p.oauthConfig
+}
+
+func (p *discordProvider) FetchUserInfo(client *http.Client) (*OAuthUserInfo, error) {
+	var user struct {
+		ID         string `json:"id"`
+		Username   string `json:"username"`
+		GlobalName string `json:"global_name"`
+		Avatar     string `json:"avatar"`
+		Email      string `json:"email"`
+		Verified   bool   `json:"verified"`
+	}
+	if err := utils.FetchUserInfo(client, p.userInfoURL, &user); err != nil {
+		return nil, err
+	}
+
+	userInfo := &OAuthUserInfo{
+		Subject:       user.ID,
+		Email:         user.Email,
+		EmailVerified: user.Verified,
+		Name:          user.GlobalName,
+	}
+	if userInfo.Name == "" {
+		userInfo.Name = user.Username
+	}
+	if user.Avatar != "" {
+		userInfo.AvatarURL = fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", user.ID, user.Avatar)
+	}
+
+	return userInfo, nil
+}
+
+type oidcProvider struct {
+	oauthConfig *oauth2.Config
+	userInfoURL string
+}
+
+// newOIDCProvider reads the issuer's discovery document to find its endpoints.
+func newOIDCProvider(oauthConfig *oauth2.Config, issuerURL string) (*oidcProvider, error) {
+	var discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	discoveryURL := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	if err := utils.FetchUserInfo(&http.Client{Timeout: 10 * time.Second}, discoveryURL, &discovery); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(issuerURL, "/") {
+		return nil, fmt.Errorf("issuer mismatch: %s", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserInfoEndpoint == "" {
+		return nil, errors.New("incomplete discovery document")
+	}
+
+	oauthConfig.Endpoint = oauth2.Endpoint{
+		AuthURL:  discovery.AuthorizationEndpoint,
+		TokenURL: discovery.TokenEndpoint,
+	}
+
+	return &oidcProvider{oauthConfig: oauthConfig, userInfoURL: discovery.UserInfoEndpoint}, nil
+}
+
+func (p *oidcProvider) OAuthConfig() *oauth2.Config {
+	return p.oauthConfig
+}
+
+func (p *oidcProvider) FetchUserInfo(client *http.Client) (*OAuthUserInfo, error) {
+	var userInfo struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+		Picture       string `json:"picture"`
+	}
+	if err := utils.FetchUserInfo(client, p.userInfoURL, &userInfo); err != nil {
+		return nil, err
+	}
+
+	return &OAuthUserInfo{
+		Subject:       userInfo.Subject,
+		Email:         userInfo.Email,
+		EmailVerified: userInfo.EmailVerified,
+		Name:          userInfo.Name,
+		AvatarURL:     userInfo.Picture,
+	}, nil
+}
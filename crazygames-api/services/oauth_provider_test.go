@@ -0,0 +1,102 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"crazygames.io/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveJSON answers each path of routes with its JSON body.
+func serveJSON(t *testing.T, routes map[string]interface{}) *httptest.Server {
+	mux := http.NewServeMux()
+	for path, body := range routes {
+		body := body
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(body)
+		})
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func Test_GithubProvider(t *testing.T) {
+	server := serveJSON(t, map[string]interface{}{
+		"/user": map[string]interface{}{"id": 42, "login": "octocat", "avatar_url": "https://avatars.example.com/42"},
+		"/user/emails": []map[string]interface{}{
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		},
+	})
+
+	provider, err := NewOAuthProvider("github", config.Oauth2Config{UserInfoURL: server.URL + "/user"})
+	assert.NoError(t, err)
+
+	userInfo, err := provider.FetchUserInfo(server.Client())
+	assert.NoError(t, err, "failed to fetch user info")
+	assert.Equal(t, "42", userInfo.Subject)
+	assert.Equal(t, "octocat", userInfo.Name)
+	assert.Equal(t, "octocat@example.com", userInfo.Email)
+	assert.True(t, userInfo.EmailVerified)
+}
+
+func Test_DiscordProvider(t *testing.T) {
+	server := serveJSON(t, map[string]interface{}{
+		"/users/@me": map[string]interface{}{"id": "80351110224678912", "username": "nelly", "avatar": "8342729096ea3675442027381ff50dfe", "email": "nelly@example.com", "verified": true},
+	})
+
+	provider, err := NewOAuthProvider("discord", config.Oauth2Config{UserInfoURL: server.URL + "/users/@me"})
+	assert.NoError(t, err)
+
+	userInfo, err := provider.FetchUserInfo(server.Client())
+	assert.NoError(t, err, "failed to fetch user info")
+	assert.Equal(t, "80351110224678912", userInfo.Subject)
+	assert.Equal(t, "nelly", userInfo.Name)
+	assert.Equal(t, "https://cdn.discordapp.com/avatars/80351110224678912/8342729096ea3675442027381ff50dfe.png", userInfo.AvatarURL)
+}
+
+func Test_OIDCProvider(t *testing.T) {
+	var issuer string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": issuer + "/authorize",
+			"token_endpoint":         issuer + "/token",
+			"userinfo_endpoint":      issuer + "/userinfo",
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"sub": "abc", "email": "player@example.com", "email_verified": true})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	issuer = server.URL
+
+	t.Run("discovery should configure the endpoints", func(t *testing.T) {
+		provider, err := NewOAuthProvider("keycloak", config.Oauth2Config{IssuerURL: issuer})
+		assert.NoError(t, err, "failed to discover provider")
+		assert.Equal(t, issuer+"/authorize", provider.OAuthConfig().Endpoint.AuthURL)
+		assert.Equal(t, issuer+"/token", provider.OAuthConfig().Endpoint.TokenURL)
+
+		userInfo, err := provider.FetchUserInfo(server.Client())
+		assert.NoError(t, err, "failed to fetch user info")
+		assert.Equal(t, "abc", userInfo.Subject)
+		assert.Equal(t, "player@example.com", userInfo.Email)
+	})
+
+	t.Run("issuer mismatch should fail", func(t *testing.T) {
+		_, err := NewOAuthProvider("keycloak", config.Oauth2Config{IssuerURL: issuer + "/realms/other"})
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown provider without issuer should fail", func(t *testing.T) {
+		_, err := NewOAuthProvider("myspace", config.Oauth2Config{})
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+}
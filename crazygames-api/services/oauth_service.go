@@ -1,63 +1,375 @@
-package services
-
-import (
-	"context"
-	"errors"
-
-	"crazygames.io/config"
-	"crazygames.io/entities"
-	"crazygames.io/repositories"
-	"golang.org/x/oauth2"
-)
-
-type OAuthServiceInterface interface {
-	GetGoogleAuthURL(state string) string
-	ExchangeCodeForToken(ctx context.Context, code string) (*oauth2.Token, error)
-	HandleGoogleUser(userInfo map[string]interface{}) error
-}
-
-type OAuthService struct {
-	userRepo repositories.UserRepositoryInterface
-}
-
-func NewOAuthService(userRepo repositories.UserRepositoryInterface) *OAuthService {
-	return &OAuthService{
-		userRepo: userRepo,
-	}
-}
-
-func (s *OAuthService) GetGoogleAuthURL(state string) string {
-	authURL := config.GoogleOauthConfig.AuthCodeURL(state, oauth2.AccessTypeOffline)
-	return authURL
-}
-
-func (s *OAuthService) ExchangeCodeForToken(ctx context.Context, code string) (*oauth2.Token, error) {
-	token, err := config.GoogleOauthConfig.Exchange(ctx, code)
-	if err != nil {
-		return nil, err
-	}
-	return token, nil
-}
-
-func (s *OAuthService) HandleGoogleUser(userInfo map[string]interface{}) error {
-	email, ok := userInfo["email"].(string)
-	if !ok || email == "" {
-		return errors.New("invalid user info: email not found")
-	}
-
-	// Check if the user already exists
-	existingUser, _ := s.userRepo.GetByUsername(email)
-	if existingUser != nil {
-		return nil // User already exists, no further action required
-	}
-
-	// Create a new user
-	user := &entities.User{
-		Username: email,
-		Email:    email,
-		Role:     entities.RolePlayer,
-		Password: "",
-	}
-
-	return s.userRepo.Create(user)
-}
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// How long the frontend has to trade the code from the OAuth redirect for tokens.
+	loginCodeTTL = time.Minute
+	// How long a user has to finish logging in with the provider.
+	OAuthStateTTL = 10 * time.Minute
+)
+
+var (
+	ErrInvalidReturnURL      = errors.New("invalid return url")
+	ErrOAuthEmailNotVerified = errors.New("an account with this email already exists, log in and link this provider from your account")
+	ErrIdentityInUse         = errors.New("this provider account is already linked to another user")
+	ErrProviderAlreadyLinked = errors.New("a different account of this provider is already linked")
+	ErrIdentityNotFound      = errors.New("provider is not linked")
+	ErrLastLoginMethod       = errors.New("cannot unlink the only way to log in, set a password first")
+)
+
+type OAuthServiceInterface interface {
+	GetAuthURL(provider string, returnTo string) (string, string, error)
+	GetLinkURL(provider string, userID uint, returnTo string) (string, string, error)
+	ConsumeState(state string) (*repositories.OAuthState, error)
+	ExchangeCodeForToken(ctx context.Context, provider string, code string, codeVerifier string) (*oauth2.Token, error)
+	FetchUserInfo(ctx context.Context, provider string, token *oauth2.Token) (*OAuthUserInfo, error)
+	HandleOAuthUser(userInfo *OAuthUserInfo) (*entities.User, error)
+	CreateLoginCode(user *entities.User) (string, error)
+	ExchangeLoginCode(code string) (*LoginResult, error)
+	LoginRedirectURL(returnTo string, loginCode string) string
+	LinkIdentity(userID uint, userInfo *OAuthUserInfo) error
+	UnlinkIdentity(userID uint, provider string) error
+	GetIdentities(userID uint) ([]entities.UserIdentity, error)
+	LinkRedirectURL(returnTo string, provider string) string
+}
+
+// TokenIssuer logs in a user that has been authenticated elsewhere.
+type TokenIssuer interface {
+	StartLogin(user *entities.User) (*LoginResult, error)
+}
+
+type OAuthService struct {
+	userRepo     repositories.UserRepositoryInterface
+	identityRepo repositories.UserIdentityRepositoryInterface
+	oauthRepo    repositories.OAuthRepositoryInterface
+	tokenIssuer  TokenIssuer
+	providers    map[string]OAuthProvider
+
+	frontendURL    string
+	allowedOrigins []string
+}
+
+func NewOAuthService(userRepo repositories.UserRepositoryInterface, identityRepo repositories.UserIdentityRepositoryInterface, oauthRepo repositories.OAuthRepositoryInterface, tokenIssuer TokenIssuer, providers map[string]OAuthProvider) *OAuthService {
+	return &OAuthService{
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		oauthRepo:    oauthRepo,
+		tokenIssuer:  tokenIssuer,
+		providers:    providers,
+
+		frontendURL:    config.AppConfig.FRONTEND_URL,
+		allowedOrigins: config.AppConfig.ALLOW_ORIGINS,
+	}
+}
+
+func (s *OAuthService) provider(name string) (OAuthProvider, error) {
+	provider, ok := s.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// GetAuthURL starts a login with provider and returns its URL together with
+// the state, which the caller must bind to the browser. returnTo is where the
+// user lands after logging in.
+func (s *OAuthService) GetAuthURL(providerName string, returnTo string) (string, string, error) {
+	return s.authURL(providerName, returnTo, 0)
+}
+
+// GetLinkURL is GetAuthURL for a logged-in user: the callback links the
+// provider account to userID instead of logging in.
+func (s *OAuthService) GetLinkURL(providerName string, userID uint, returnTo string) (string, string, error) {
+	return s.authURL(providerName, returnTo, userID)
+}
+
+func (s *OAuthService) authURL(providerName string, returnTo string, linkUserID uint) (string, string, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.validateReturnURL(returnTo); err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.RandomToken(32)
+	if err != nil {
+		return "", "", errors.New("failed to generate state")
+	}
+
+	verifier := oauth2.GenerateVerifier()
+	err = s.oauthRepo.SaveState(context.Background(), state, &repositories.OAuthState{
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		ReturnTo:     returnTo,
+		LinkUserID:   linkUserID,
+	}, OAuthStateTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL := provider.OAuthConfig().AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+	return authURL, state, nil
+}
+
+func (s *OAuthService) ConsumeState(state string) (*repositories.OAuthState, error) {
+	return s.oauthRepo.ConsumeState(context.Background(), state)
+}
+
+func (s *OAuthService) ExchangeCodeForToken(ctx context.Context, providerName string, code string, codeVerifier string) (*oauth2.Token, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := provider.OAuthConfig().Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (s *OAuthService) FetchUserInfo(ctx context.Context, providerName string, token *oauth2.Token) (*OAuthUserInfo, error) {
+	provider, err := s.provider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfo, err := provider.FetchUserInfo(provider.OAuthConfig().Client(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+	userInfo.Provider = providerName
+	return userInfo, nil
+}
+
+// HandleOAuthUser returns the user to log in for a provider account. An
+// unknown account is linked to the user with the same email when the provider
+// has verified that email, and gets a new user otherwise.
+func (s *OAuthService) HandleOAuthUser(userInfo *OAuthUserInfo) (*entities.User, error) {
+	if userInfo.Subject == "" {
+		return nil, errors.New("invalid user info: subject not found")
+	}
+
+	identity, _ := s.identityRepo.GetByProviderSubject(userInfo.Provider, userInfo.Subject)
+	if identity != nil {
+		return s.userRepo.GetByID(identity.UserID)
+	}
+
+	email := userInfo.Email
+	if email == "" {
+		return nil, errors.New("invalid user info: email not found")
+	}
+
+	existingUser, _ := s.userRepo.GetByEmail(email)
+	if existingUser != nil {
+		// Without a verified email anyone could claim the address at the
+		// provider and take over the account.
+		if !userInfo.EmailVerified {
+			return nil, ErrOAuthEmailNotVerified
+		}
+		if err := s.LinkIdentity(existingUser.ID, userInfo); err != nil {
+			return nil, err
+		}
+		// The provider has now proved who owns the email. A password set
+		// before that may belong to someone who registered the address
+		// first, so it has to be set again through password reset. Accounts
+		// from before email verification were marked verified when it was
+		// added, so this only applies to accounts registered unverified.
+		if !existingUser.EmailVerified {
+			existingUser.EmailVerified = true
+			existingUser.Password = ""
+			return s.userRepo.Update(context.Background(), existingUser)
+		}
+		return existingUser, nil
+	}
+
+	// The user has no password until they set one through password reset.
+	user := &entities.User{
+		Username:      email,
+		Email:         email,
+		EmailVerified: userInfo.EmailVerified,
+		Role:          entities.RolePlayer,
+	}
+
+	if err := s.identityRepo.CreateWithUser(user, newUserIdentity(0, userInfo)); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func newUserIdentity(userID uint, userInfo *OAuthUserInfo) *entities.UserIdentity {
+	return &entities.UserIdentity{
+		UserID:   userID,
+		Provider: userInfo.Provider,
+		Subject:  userInfo.Subject,
+		Email:    userInfo.Email,
+	}
+}
+
+// LinkIdentity links a provider account to userID. Linking an account that is
+// already linked to userID does nothing.
+func (s *OAuthService) LinkIdentity(userID uint, userInfo *OAuthUserInfo) error {
+	if userInfo.Subject == "" {
+		return errors.New("invalid user info: subject not found")
+	}
+
+	identity, _ := s.identityRepo.GetByProviderSubject(userInfo.Provider, userInfo.Subject)
+	if identity != nil {
+		if identity.UserID != userID {
+			return ErrIdentityInUse
+		}
+		return nil
+	}
+
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+	for _, linked := range identities {
+		if linked.Provider == userInfo.Provider {
+			return ErrProviderAlreadyLinked
+		}
+	}
+
+	return s.identityRepo.Create(newUserIdentity(userID, userInfo))
+}
+
+// UnlinkIdentity removes a provider from userID, unless the user would be left
+// without a password or another provider to log in with.
+func (s *OAuthService) UnlinkIdentity(userID uint, provider string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	identities, err := s.identityRepo.GetByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	linked := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			linked = true
+		}
+	}
+	if !linked {
+		return ErrIdentityNotFound
+	}
+
+	if user.Password == "" && len(identities) == 1 {
+		return ErrLastLoginMethod
+	}
+
+	return s.identityRepo.Delete(userID, provider)
+}
+
+func (s *OAuthService) GetIdentities(userID uint) ([]entities.UserIdentity, error) {
+	return s.identityRepo.GetByUserID(userID)
+}
+
+// CreateLoginCode returns a short-lived, single use code the frontend trades for
+// our tokens, so no token ever appears in a redirect URL.
+func (s *OAuthService) CreateLoginCode(user *entities.User) (string, error) {
+	code, err := utils.RandomToken(32)
+	if err != nil {
+		return "", errors.New("failed to generate login code")
+	}
+
+	if err := s.oauthRepo.SaveLoginCode(context.Background(), utils.HashToken(code), user.ID, loginCodeTTL); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (s *OAuthService) ExchangeLoginCode(code string) (*LoginResult, error) {
+	userID, err := s.oauthRepo.ConsumeLoginCode(context.Background(), utils.HashToken(code))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, repositories.ErrLoginCodeInvalid
+	}
+
+	return s.tokenIssuer.StartLogin(user)
+}
+
+// LoginRedirectURL is the frontend page that receives loginCode: returnTo when
+// given, the frontend home page otherwise.
+func (s *OAuthService) LoginRedirectURL(returnTo string, loginCode string) string {
+	return s.redirectURL(returnTo, "code", loginCode)
+}
+
+// LinkRedirectURL is the frontend page the user returns to after linking provider.
+func (s *OAuthService) LinkRedirectURL(returnTo string, provider string) string {
+	return s.redirectURL(returnTo, "linked", provider)
+}
+
+func (s *OAuthService) redirectURL(returnTo string, key string, value string) string {
+	target, _ := url.Parse(s.frontendURL)
+	if returnTo != "" {
+		returnURL, _ := url.Parse(returnTo)
+		target = target.ResolveReference(returnURL)
+	}
+
+	query := target.Query()
+	query.Set(key, value)
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// validateReturnURL accepts paths on the frontend and absolute URLs on one of
+// our allowed origins, so the login cannot be used as an open redirect.
+func (s *OAuthService) validateReturnURL(returnTo string) error {
+	if returnTo == "" {
+		return nil
+	}
+
+	if strings.Contains(returnTo, "\\") {
+		return ErrInvalidReturnURL
+	}
+
+	returnURL, err := url.Parse(returnTo)
+	if err != nil {
+		return ErrInvalidReturnURL
+	}
+
+	if returnURL.Scheme == "" && returnURL.Host == "" {
+		if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") {
+			return ErrInvalidReturnURL
+		}
+		return nil
+	}
+
+	if returnURL.Scheme != "http" && returnURL.Scheme != "https" {
+		return ErrInvalidReturnURL
+	}
+
+	origin := returnURL.Scheme + "://" + returnURL.Host
+	for _, allowed := range s.allowedOrigins {
+		if origin == strings.TrimSuffix(allowed, "/") {
+			return nil
+		}
+	}
+
+	return ErrInvalidReturnURL
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+)
+
+var (
+	ErrGameNotFound        = errors.New("game not found")
+	ErrPlaySessionNotFound = errors.New("play session not found")
+	ErrPlaySessionEnded    = errors.New("play session has ended, start a new one")
+)
+
+// How long an instance may hold the flush lock, in case it dies while flushing.
+const playCountFlushLockTTL = time.Minute
+
+// PlayService records the games played, by players that are logged in or not,
+// and for how long.
+type PlayService struct {
+	gameRepo        repositories.GameRepositoryInterface
+	playHistoryRepo repositories.PlayHistoryRepositoryInterface
+	playCountRepo   repositories.PlayCountRepositoryInterface
+	rankingSvc      RankingServiceInterface
+	// A session without a heartbeat for sessionTimeout has ended.
+	sessionTimeout time.Duration
+}
+
+type PlayServiceInterface interface {
+	StartSession(userID *uint, gameID uint) (*entities.PlayHistory, error)
+	Heartbeat(gameID uint, sessionID string) error
+	EndSession(gameID uint, sessionID string) error
+	HeartbeatInterval() time.Duration
+	GetRecentlyPlayed(userID uint, limit int) ([]repositories.RecentlyPlayedGame, error)
+	GetUserPlaytime(userID uint) (*UserPlaytime, error)
+	FlushPlayCounts() error
+	EndStaleSessions() error
+}
+
+// UserPlaytime is how long a user played in total and in each game.
+type UserPlaytime struct {
+	Sessions       int64                        `json:"sessions"`
+	TotalSeconds   int64                        `json:"total_seconds"`
+	AverageSeconds float64                      `json:"average_seconds"`
+	Games          []repositories.PlaytimeStats `json:"games"`
+}
+
+func NewPlayService(gameRepo repositories.GameRepositoryInterface, playHistoryRepo repositories.PlayHistoryRepositoryInterface, playCountRepo repositories.PlayCountRepositoryInterface, rankingSvc RankingServiceInterface) *PlayService {
+	return &PlayService{
+		gameRepo:        gameRepo,
+		playHistoryRepo: playHistoryRepo,
+		playCountRepo:   playCountRepo,
+		rankingSvc:      rankingSvc,
+		sessionTimeout:  config.AppConfig.PlaySessionTimeout,
+	}
+}
+
+// StartSession adds a play of the game to the history, without a user for
+// anonymous players, and counts it towards the game's play count and
+// rankings. The game page keeps the returned session going with heartbeats.
+func (s *PlayService) StartSession(userID *uint, gameID uint) (*entities.PlayHistory, error) {
+	exists, err := s.gameRepo.Exists(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrGameNotFound
+	}
+
+	sessionID, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	history := &entities.PlayHistory{UserID: userID, GameID: gameID, SessionID: sessionID}
+	if err := s.playHistoryRepo.Create(history); err != nil {
+		return nil, err
+	}
+	if err := s.playCountRepo.Increment(context.Background(), gameID); err != nil {
+		return nil, err
+	}
+	if err := s.rankingSvc.RecordPlay(gameID, *history.DatePlayed); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// HeartbeatInterval is how often the game page should send heartbeats, well
+// within the session timeout.
+func (s *PlayService) HeartbeatInterval() time.Duration {
+	return s.sessionTimeout / 3
+}
+
+// Heartbeat extends the session to now. A session that timed out cannot be
+// resumed, so that the time the page was asleep does not count.
+func (s *PlayService) Heartbeat(gameID uint, sessionID string) error {
+	history, err := s.getSession(gameID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if history.EndedAt != nil || now.Sub(lastSeen(history)) > s.sessionTimeout {
+		return ErrPlaySessionEnded
+	}
+
+	history.LastHeartbeatAt = &now
+	history.DurationSeconds = int64(now.Sub(*history.DatePlayed).Seconds())
+	return s.playHistoryRepo.Extend(history)
+}
+
+// EndSession ends the session now, or at its last heartbeat if it timed out.
+// Ending a session twice is fine, as the page may send the end more than once.
+func (s *PlayService) EndSession(gameID uint, sessionID string) error {
+	history, err := s.getSession(gameID, sessionID)
+	if err != nil {
+		return err
+	}
+	if history.EndedAt != nil {
+		return nil
+	}
+
+	endedAt := time.Now()
+	if endedAt.Sub(lastSeen(history)) > s.sessionTimeout {
+		endedAt = lastSeen(history)
+	}
+	history.EndedAt = &endedAt
+	history.DurationSeconds = int64(endedAt.Sub(*history.DatePlayed).Seconds())
+	return s.playHistoryRepo.End(history)
+}
+
+func (s *PlayService) getSession(gameID uint, sessionID string) (*entities.PlayHistory, error) {
+	history, err := s.playHistoryRepo.GetSession(gameID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		return nil, ErrPlaySessionNotFound
+	}
+	return history, nil
+}
+
+// lastSeen is the last time the game page showed the session was going.
+func lastSeen(history *entities.PlayHistory) time.Time {
+	if history.LastHeartbeatAt != nil {
+		return *history.LastHeartbeatAt
+	}
+	return *history.DatePlayed
+}
+
+// EndStaleSessions ends the sessions whose game page stopped sending
+// heartbeats, e.g. because it was closed without an end event.
+func (s *PlayService) EndStaleSessions() error {
+	ended, err := s.playHistoryRepo.EndStaleSessions(time.Now().Add(-s.sessionTimeout))
+	if err != nil {
+		return err
+	}
+	if ended > 0 {
+		log.Printf("Ended %d stale play sessions", ended)
+	}
+	return nil
+}
+
+func (s *PlayService) GetUserPlaytime(userID uint) (*UserPlaytime, error) {
+	games, err := s.playHistoryRepo.GetUserPlaytime(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	playtime := &UserPlaytime{Games: games}
+	for _, game := range games {
+		playtime.Sessions += game.Sessions
+		playtime.TotalSeconds += game.TotalSeconds
+	}
+	if playtime.Sessions > 0 {
+		playtime.AverageSeconds = float64(playtime.TotalSeconds) / float64(playtime.Sessions)
+	}
+	return playtime, nil
+}
+
+func (s *PlayService) GetRecentlyPlayed(userID uint, limit int) ([]repositories.RecentlyPlayedGame, error) {
+	return s.playHistoryRepo.GetRecentlyPlayed(userID, limit)
+}
+
+// FlushPlayCounts adds the plays counted in Redis to the games' play counts.
+// Only one instance flushes at a time; counts that fail to be stored, or to be
+// cleared once stored, are retried on the next flush and only added once.
+func (s *PlayService) FlushPlayCounts() error {
+	ctx := context.Background()
+	token, err := s.playCountRepo.LockFlush(ctx, playCountFlushLockTTL)
+	if err != nil || token == "" {
+		return err
+	}
+	defer s.playCountRepo.UnlockFlush(ctx, token)
+
+	flushID, counts, err := s.playCountRepo.TakePending(ctx)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	if err := s.gameRepo.IncrementPlayCounts(flushID, counts); err != nil {
+		return err
+	}
+	if err := s.playCountRepo.ClearFlushed(ctx); err != nil {
+		return err
+	}
+	log.Printf("Flushed the play counts of %d games", len(counts))
+	return nil
+}
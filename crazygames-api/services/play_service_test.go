@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGameRepo struct {
+	repositories.GameRepositoryInterface
+	playCounts map[uint]int64
+	flushed    map[string]bool
+	failFlush  bool
+}
+
+func (r *fakeGameRepo) Exists(id uint) (bool, error) {
+	_, ok := r.playCounts[id]
+	return ok, nil
+}
+
+func (r *fakeGameRepo) IncrementPlayCounts(flushID string, counts map[uint]int64) error {
+	if r.failFlush {
+		return assert.AnError
+	}
+	if r.flushed[flushID] {
+		return nil
+	}
+	if r.flushed == nil {
+		r.flushed = map[string]bool{}
+	}
+	r.flushed[flushID] = true
+	for gameID, count := range counts {
+		r.playCounts[gameID] += count
+	}
+	return nil
+}
+
+type fakePlayHistoryRepo struct {
+	repositories.PlayHistoryRepositoryInterface
+	plays []entities.PlayHistory
+}
+
+func (r *fakePlayHistoryRepo) Create(history *entities.PlayHistory) error {
+	now := time.Now()
+	history.ID = uint(len(r.plays) + 1)
+	history.DatePlayed = &now
+	r.plays = append(r.plays, *history)
+	return nil
+}
+
+func (r *fakePlayHistoryRepo) GetSession(gameID uint, sessionID string) (*entities.PlayHistory, error) {
+	for _, play := range r.plays {
+		if play.GameID == gameID && play.SessionID == sessionID {
+			return &play, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePlayHistoryRepo) Extend(history *entities.PlayHistory) error {
+	if r.plays[history.ID-1].EndedAt == nil {
+		r.plays[history.ID-1] = *history
+	}
+	return nil
+}
+
+func (r *fakePlayHistoryRepo) End(history *entities.PlayHistory) error {
+	return r.Extend(history)
+}
+
+func (r *fakePlayHistoryRepo) EndStaleSessions(before time.Time) (int64, error) {
+	var ended int64
+	for i, play := range r.plays {
+		if play.EndedAt == nil && lastSeen(&play).Before(before) {
+			endedAt := lastSeen(&play)
+			r.plays[i].EndedAt = &endedAt
+			ended++
+		}
+	}
+	return ended, nil
+}
+
+// fakePlayCountRepo keeps the pending and flushing counts like the Redis hashes.
+type fakePlayCountRepo struct {
+	pending    map[uint]int64
+	flushing   map[uint]int64
+	flushes    int
+	locked     bool
+	failClear  bool
+	unlockedBy []string
+}
+
+func (r *fakePlayCountRepo) Increment(ctx context.Context, gameID uint) error {
+	r.pending[gameID]++
+	return nil
+}
+
+func (r *fakePlayCountRepo) LockFlush(ctx context.Context, ttl time.Duration) (string, error) {
+	if r.locked {
+		return "", nil
+	}
+	r.locked = true
+	return "token", nil
+}
+
+func (r *fakePlayCountRepo) UnlockFlush(ctx context.Context, token string) error {
+	r.unlockedBy = append(r.unlockedBy, token)
+	r.locked = false
+	return nil
+}
+
+func (r *fakePlayCountRepo) TakePending(ctx context.Context) (string, map[uint]int64, error) {
+	if r.flushing == nil {
+		r.flushing, r.pending = r.pending, map[uint]int64{}
+		r.flushes++
+	}
+	return strconv.Itoa(r.flushes), r.flushing, nil
+}
+
+func (r *fakePlayCountRepo) ClearFlushed(ctx context.Context) error {
+	if r.failClear {
+		return assert.AnError
+	}
+	r.flushing = nil
+	return nil
+}
+
+type fakeRankingService struct {
+	RankingServiceInterface
+	plays []uint
+}
+
+func (s *fakeRankingService) RecordPlay(gameID uint, at time.Time) error {
+	s.plays = append(s.plays, gameID)
+	return nil
+}
+
+func Test_Play(t *testing.T) {
+	gameRepo := &fakeGameRepo{playCounts: map[uint]int64{1: 10, 2: 0}}
+	historyRepo := &fakePlayHistoryRepo{}
+	countRepo := &fakePlayCountRepo{pending: map[uint]int64{}}
+	rankingSvc := &fakeRankingService{}
+	svc := NewPlayService(gameRepo, historyRepo, countRepo, rankingSvc)
+	userID := uint(5)
+	recordPlay := func(userID *uint, gameID uint) error {
+		_, err := svc.StartSession(userID, gameID)
+		return err
+	}
+
+	t.Run("play of an unknown game should fail", func(t *testing.T) {
+		err := recordPlay(nil, 3)
+		assert.ErrorIs(t, err, ErrGameNotFound)
+		assert.Empty(t, historyRepo.plays)
+	})
+
+	t.Run("plays should be recorded with their player if any", func(t *testing.T) {
+		assert.NoError(t, recordPlay(nil, 1))
+		assert.NoError(t, recordPlay(&userID, 1))
+		assert.NoError(t, recordPlay(&userID, 2))
+
+		assert.Len(t, historyRepo.plays, 3)
+		assert.Nil(t, historyRepo.plays[0].UserID)
+		assert.Equal(t, userID, *historyRepo.plays[1].UserID)
+		assert.Equal(t, int64(10), gameRepo.playCounts[1], "play count should wait for the flush")
+		assert.Equal(t, []uint{1, 1, 2}, rankingSvc.plays)
+	})
+
+	t.Run("failed flush should be retried", func(t *testing.T) {
+		gameRepo.failFlush = true
+		assert.Error(t, svc.FlushPlayCounts())
+		assert.False(t, countRepo.locked, "flush should unlock")
+
+		gameRepo.failFlush = false
+		assert.NoError(t, recordPlay(nil, 1))
+		assert.NoError(t, svc.FlushPlayCounts())
+		assert.Equal(t, int64(12), gameRepo.playCounts[1])
+		assert.Equal(t, int64(1), gameRepo.playCounts[2])
+
+		assert.NoError(t, svc.FlushPlayCounts())
+		assert.Equal(t, int64(13), gameRepo.playCounts[1], "plays during the failed flush should be kept")
+	})
+
+	t.Run("flush that failed to clear should not count twice", func(t *testing.T) {
+		assert.NoError(t, recordPlay(nil, 1))
+		countRepo.failClear = true
+		assert.Error(t, svc.FlushPlayCounts())
+		assert.Equal(t, int64(14), gameRepo.playCounts[1])
+
+		countRepo.failClear = false
+		assert.NoError(t, svc.FlushPlayCounts())
+		assert.Equal(t, int64(14), gameRepo.playCounts[1])
+		assert.Nil(t, countRepo.flushing)
+		assert.Equal(t, "token", countRepo.unlockedBy[len(countRepo.unlockedBy)-1], "flush should unlock with its token")
+	})
+
+	t.Run("flush should be skipped while another instance flushes", func(t *testing.T) {
+		countRepo.locked = true
+		defer func() { countRepo.locked = false }()
+
+		assert.NoError(t, recordPlay(nil, 2))
+		assert.NoError(t, svc.FlushPlayCounts())
+		assert.Equal(t, int64(1), gameRepo.playCounts[2])
+	})
+}
+
+func Test_PlaySession(t *testing.T) {
+	gameRepo := &fakeGameRepo{playCounts: map[uint]int64{1: 0, 2: 0}}
+	historyRepo := &fakePlayHistoryRepo{}
+	svc := NewPlayService(gameRepo, historyRepo, &fakePlayCountRepo{pending: map[uint]int64{}}, &fakeRankingService{})
+	svc.sessionTimeout = time.Minute
+
+	// startedAgo starts a session of the game as if it started a while ago.
+	startedAgo := func(gameID uint, ago time.Duration) *entities.PlayHistory {
+		history, err := svc.StartSession(nil, gameID)
+		assert.NoError(t, err)
+		startedAt := time.Now().Add(-ago)
+		historyRepo.plays[history.ID-1].DatePlayed = &startedAt
+		return history
+	}
+
+	t.Run("session should get an ID on start", func(t *testing.T) {
+		first := startedAgo(1, 0)
+		second := startedAgo(1, 0)
+		assert.NotEmpty(t, first.SessionID)
+		assert.NotEqual(t, first.SessionID, second.SessionID)
+	})
+
+	t.Run("heartbeat should extend the session", func(t *testing.T) {
+		history := startedAgo(1, 30*time.Second)
+		assert.NoError(t, svc.Heartbeat(1, history.SessionID))
+
+		play := historyRepo.plays[history.ID-1]
+		assert.NotNil(t, play.LastHeartbeatAt)
+		assert.InDelta(t, 30, play.DurationSeconds, 1)
+		assert.Nil(t, play.EndedAt)
+	})
+
+	t.Run("heartbeat of an unknown session should fail", func(t *testing.T) {
+		history := startedAgo(1, 0)
+		assert.ErrorIs(t, svc.Heartbeat(2, history.SessionID), ErrPlaySessionNotFound)
+		assert.ErrorIs(t, svc.Heartbeat(1, "unknown"), ErrPlaySessionNotFound)
+	})
+
+	t.Run("timed out session should not be resumed", func(t *testing.T) {
+		history := startedAgo(1, 2*time.Minute)
+		assert.ErrorIs(t, svc.Heartbeat(1, history.SessionID), ErrPlaySessionEnded)
+	})
+
+	t.Run("end should be idempotent", func(t *testing.T) {
+		history := startedAgo(2, 45*time.Second)
+		assert.NoError(t, svc.EndSession(2, history.SessionID))
+		endedAt := historyRepo.plays[history.ID-1].EndedAt
+		assert.NotNil(t, endedAt)
+		assert.InDelta(t, 45, historyRepo.plays[history.ID-1].DurationSeconds, 1)
+
+		assert.NoError(t, svc.EndSession(2, history.SessionID))
+		assert.Equal(t, endedAt, historyRepo.plays[history.ID-1].EndedAt)
+		assert.ErrorIs(t, svc.Heartbeat(2, history.SessionID), ErrPlaySessionEnded)
+	})
+
+	t.Run("late end should not count the time without heartbeats", func(t *testing.T) {
+		history := startedAgo(2, 10*time.Minute)
+		assert.NoError(t, svc.EndSession(2, history.SessionID))
+
+		play := historyRepo.plays[history.ID-1]
+		assert.Equal(t, *play.DatePlayed, *play.EndedAt)
+		assert.Zero(t, play.DurationSeconds)
+	})
+
+	t.Run("stale sessions should be ended", func(t *testing.T) {
+		stale := startedAgo(1, 5*time.Minute)
+		live := startedAgo(1, 0)
+		assert.NoError(t, svc.EndStaleSessions())
+
+		assert.NotNil(t, historyRepo.plays[stale.ID-1].EndedAt)
+		assert.Nil(t, historyRepo.plays[live.ID-1].EndedAt)
+	})
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+)
+
+// trendingEpoch is when the trending scores start. A play's score is the
+// number of half-lives between the epoch and the play, so that newer plays
+// weigh more without having to decay the older ones.
+var trendingEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// RankingService ranks the games for the homepage, by trend, by popularity
+// over a window and by date added.
+type RankingService struct {
+	gameRepo        repositories.GameRepositoryInterface
+	playHistoryRepo repositories.PlayHistoryRepositoryInterface
+	rankingRepo     repositories.RankingRepositoryInterface
+	favoriteRepo    repositories.FavoriteRepositoryInterface
+	halfLife        time.Duration
+}
+
+// RankingServiceInterface lists the ranked games with the user that is logged
+// in, if any, to tell which are their favorites.
+type RankingServiceInterface interface {
+	RecordPlay(gameID uint, at time.Time) error
+	GetTrending(limit int, userID *uint) ([]entities.Game, error)
+	GetPopular(window string, limit int, userID *uint) ([]entities.Game, error)
+	GetNew(limit int, userID *uint) ([]entities.Game, error)
+	Rebuild(ctx context.Context) error
+}
+
+func NewRankingService(gameRepo repositories.GameRepositoryInterface, playHistoryRepo repositories.PlayHistoryRepositoryInterface, rankingRepo repositories.RankingRepositoryInterface, favoriteRepo repositories.FavoriteRepositoryInterface) *RankingService {
+	return &RankingService{
+		gameRepo:        gameRepo,
+		playHistoryRepo: playHistoryRepo,
+		rankingRepo:     rankingRepo,
+		favoriteRepo:    favoriteRepo,
+		halfLife:        config.AppConfig.TrendingHalfLife,
+	}
+}
+
+// trendingScore is the log2 of how much a play at the given time weighs.
+func (s *RankingService) trendingScore(at time.Time) float64 {
+	return float64(at.Sub(trendingEpoch)) / float64(s.halfLife)
+}
+
+func (s *RankingService) RecordPlay(gameID uint, at time.Time) error {
+	return s.rankingRepo.RecordPlay(context.Background(), gameID, at, s.trendingScore(at))
+}
+
+func (s *RankingService) GetTrending(limit int, userID *uint) ([]entities.Game, error) {
+	ids, err := s.rankingRepo.GetTrending(context.Background(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.getGames(ids, userID)
+}
+
+func (s *RankingService) GetPopular(window string, limit int, userID *uint) ([]entities.Game, error) {
+	ids, err := s.rankingRepo.GetPopular(context.Background(), window, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.getGames(ids, userID)
+}
+
+func (s *RankingService) GetNew(limit int, userID *uint) ([]entities.Game, error) {
+	games, err := s.gameRepo.GetNewest(limit)
+	if err != nil {
+		return nil, err
+	}
+	return games, s.withDetails(games, userID)
+}
+
+// getGames returns the games in the order of the ranking, without the ones
+// that were deleted since they were played.
+func (s *RankingService) getGames(ids []uint, userID *uint) ([]entities.Game, error) {
+	found, err := s.gameRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]entities.Game, len(found))
+	for _, game := range found {
+		byID[game.ID] = game
+	}
+	games := make([]entities.Game, 0, len(found))
+	for _, id := range ids {
+		if game, ok := byID[id]; ok {
+			games = append(games, game)
+		}
+	}
+	return games, s.withDetails(games, userID)
+}
+
+// withDetails flags the games that are favorites of the user, if any.
+func (s *RankingService) withDetails(games []entities.Game, userID *uint) error {
+	if userID == nil {
+		return nil
+	}
+	return withFavorites(s.favoriteRepo, *userID, games)
+}
+
+// Rebuild recomputes the rankings from MySQL, e.g. after Redis was flushed.
+// The trending scores only count the plays of the last RankingHistory, the
+// older ones weigh next to nothing.
+func (s *RankingService) Rebuild(ctx context.Context) error {
+	now := time.Now()
+	rankings := &repositories.Rankings{
+		Trending:    map[uint]float64{},
+		HourlyPlays: map[time.Time]map[uint]int64{},
+		Since:       now.Add(-repositories.RankingHistory).Truncate(time.Hour),
+	}
+
+	var plays int
+	err := s.playHistoryRepo.EachPlaySince(rankings.Since, func(history *entities.PlayHistory) error {
+		if history.DatePlayed == nil {
+			return nil
+		}
+		score := s.trendingScore(*history.DatePlayed)
+		if current, ok := rankings.Trending[history.GameID]; ok {
+			high, low := math.Max(current, score), math.Min(current, score)
+			score = high + math.Log2(1+math.Exp2(low-high))
+		}
+		rankings.Trending[history.GameID] = score
+
+		hour := history.DatePlayed.UTC().Truncate(time.Hour)
+		if rankings.HourlyPlays[hour] == nil {
+			rankings.HourlyPlays[hour] = map[uint]int64{}
+		}
+		rankings.HourlyPlays[hour][history.GameID]++
+		plays++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	rankings.AllTimePlays, err = s.gameRepo.GetPlayCounts()
+	if err != nil {
+		return err
+	}
+
+	if err := s.rankingRepo.Replace(ctx, rankings); err != nil {
+		return err
+	}
+	log.Printf("Rebuilt the rankings of %d games from %d plays", len(rankings.AllTimePlays), plays)
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRankingGameRepo struct {
+	repositories.GameRepositoryInterface
+	games map[uint]entities.Game
+}
+
+func (r *fakeRankingGameRepo) GetByIDs(ids []uint) ([]entities.Game, error) {
+	var games []entities.Game
+	for _, game := range r.games {
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+func (r *fakeRankingGameRepo) GetPlayCounts() (map[uint]int64, error) {
+	return map[uint]int64{1: 100, 2: 3}, nil
+}
+
+type fakeRankingPlayHistoryRepo struct {
+	repositories.PlayHistoryRepositoryInterface
+	plays []entities.PlayHistory
+}
+
+func (r *fakeRankingPlayHistoryRepo) EachPlaySince(since time.Time, fn func(history *entities.PlayHistory) error) error {
+	for _, play := range r.plays {
+		if err := fn(&play); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fakeRankingRepo struct {
+	repositories.RankingRepositoryInterface
+	trending []uint
+	replaced *repositories.Rankings
+}
+
+func (r *fakeRankingRepo) GetTrending(ctx context.Context, limit int) ([]uint, error) {
+	return r.trending, nil
+}
+
+func (r *fakeRankingRepo) Replace(ctx context.Context, rankings *repositories.Rankings) error {
+	r.replaced = rankings
+	return nil
+}
+
+func Test_Rankings(t *testing.T) {
+	gameRepo := &fakeRankingGameRepo{games: map[uint]entities.Game{
+		1: {ID: 1, GameTitle: "First"},
+		2: {ID: 2, GameTitle: "Second"},
+		3: {ID: 3, GameTitle: "Third"},
+	}}
+	historyRepo := &fakeRankingPlayHistoryRepo{}
+	rankingRepo := &fakeRankingRepo{}
+	favoriteRepo := &fakeFavoriteRepo{favorites: map[uint]bool{1: true}}
+	svc := NewRankingService(gameRepo, historyRepo, rankingRepo, favoriteRepo)
+	svc.halfLife = time.Hour
+
+	t.Run("games should keep the ranking order without the deleted ones", func(t *testing.T) {
+		rankingRepo.trending = []uint{3, 4, 1}
+		games, err := svc.GetTrending(10, nil)
+		assert.NoError(t, err)
+		assert.Len(t, games, 2)
+		assert.Equal(t, uint(3), games[0].ID)
+		assert.Equal(t, uint(1), games[1].ID)
+		assert.False(t, games[1].IsFavorite)
+	})
+
+	t.Run("ranked games should be flagged for the user", func(t *testing.T) {
+		userID := uint(1)
+		rankingRepo.trending = []uint{3, 1}
+		games, err := svc.GetTrending(10, &userID)
+		assert.NoError(t, err)
+		assert.False(t, games[0].IsFavorite)
+		assert.True(t, games[1].IsFavorite)
+	})
+
+	t.Run("plays should count half as much every half-life", func(t *testing.T) {
+		now := time.Now()
+		assert.InDelta(t, 1, svc.trendingScore(now)-svc.trendingScore(now.Add(-time.Hour)), 1e-9)
+	})
+
+	t.Run("rebuild should weigh recent plays more", func(t *testing.T) {
+		now := time.Now()
+		recent, old := now.Add(-time.Hour), now.Add(-5*time.Hour)
+		historyRepo.plays = []entities.PlayHistory{
+			{GameID: 1, DatePlayed: &old},
+			{GameID: 1, DatePlayed: &old},
+			{GameID: 1, DatePlayed: &old},
+			{GameID: 2, DatePlayed: &recent},
+			{GameID: 2, DatePlayed: &recent},
+		}
+		assert.NoError(t, svc.Rebuild(context.Background()))
+
+		rankings := rankingRepo.replaced
+		assert.InDelta(t, svc.trendingScore(recent)+1, rankings.Trending[2], 1e-9, "two plays should weigh twice one")
+		assert.Greater(t, rankings.Trending[2], rankings.Trending[1])
+		assert.Equal(t, int64(3), rankings.HourlyPlays[old.UTC().Truncate(time.Hour)][1])
+		assert.Equal(t, int64(100), rankings.AllTimePlays[1])
+	})
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"crazygames.io/config"
+	"crazygames.io/repositories"
+)
+
+// How long failed logins are remembered after the last one, so the lockout
+// keeps growing for an attacker that waits out each lock.
+const loginFailureTTL = 24 * time.Hour
+
+// RateLimitError is returned when a request is refused by a rate limit or an
+// account lockout. The message is the same in both cases so it does not tell
+// whether the account exists.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return "too many attempts, please try again later"
+}
+
+type RateLimitService struct {
+	repo repositories.RateLimitRepositoryInterface
+
+	window                        time.Duration
+	loginLimitPerAccount          int
+	forgotPasswordLimitPerAccount int
+	lockoutThreshold              int
+	lockoutCooldown               time.Duration
+	lockoutMaxCooldown            time.Duration
+}
+
+type RateLimitServiceInterface interface {
+	LimitLogin(email string) error
+	LimitForgotPassword(email string) error
+	LimitMFA(userID uint) error
+	LimitPasswordConfirmation(userID uint) error
+	CheckLockout(email string) error
+	RecordLoginFailure(email string) error
+	ResetLoginFailures(email string) error
+}
+
+func NewRateLimitService(repo repositories.RateLimitRepositoryInterface) *RateLimitService {
+	return &RateLimitService{
+		repo: repo,
+
+		window:                        config.AppConfig.RateLimitWindow,
+		loginLimitPerAccount:          config.AppConfig.LoginLimitPerAccount,
+		forgotPasswordLimitPerAccount: config.AppConfig.ForgotPasswordLimitPerAccount,
+		lockoutThreshold:              config.AppConfig.LockoutThreshold,
+		lockoutCooldown:               config.AppConfig.LockoutCooldown,
+		lockoutMaxCooldown:            config.AppConfig.LockoutMaxCooldown,
+	}
+}
+
+func (s *RateLimitService) LimitLogin(email string) error {
+	return s.limit("login:account:"+strings.ToLower(email), s.loginLimitPerAccount)
+}
+
+func (s *RateLimitService) LimitForgotPassword(email string) error {
+	return s.limit("forgot_password:account:"+strings.ToLower(email), s.forgotPasswordLimitPerAccount)
+}
+
+// LimitMFA allows as many second factor attempts per window as failed
+// passwords before a lockout, so the 6 digit codes cannot be guessed.
+func (s *RateLimitService) LimitMFA(userID uint) error {
+	return s.limit(fmt.Sprintf("mfa:user:%d", userID), s.lockoutThreshold)
+}
+
+// LimitPasswordConfirmation limits the password checks of a logged in user, so
+// a stolen access token cannot be used to guess the password.
+func (s *RateLimitService) LimitPasswordConfirmation(userID uint) error {
+	return s.limit(fmt.Sprintf("password:user:%d", userID), s.lockoutThreshold)
+}
+
+func (s *RateLimitService) limit(key string, limit int) error {
+	allowed, retryAfter, err := s.repo.Allow(context.Background(), key, limit, s.window)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (s *RateLimitService) CheckLockout(email string) error {
+	lockedFor, err := s.repo.LockedFor(context.Background(), email)
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return &RateLimitError{RetryAfter: lockedFor}
+	}
+	return nil
+}
+
+// RecordLoginFailure counts a wrong password for email. From the threshold on,
+// every further failure locks the account, for twice as long each time up to
+// the maximum cooldown.
+func (s *RateLimitService) RecordLoginFailure(email string) error {
+	failures, err := s.repo.RecordFailure(context.Background(), email, loginFailureTTL)
+	if err != nil {
+		return err
+	}
+	if failures < int64(s.lockoutThreshold) {
+		return nil
+	}
+
+	return s.repo.Lock(context.Background(), email, s.lockoutDuration(failures))
+}
+
+func (s *RateLimitService) lockoutDuration(failures int64) time.Duration {
+	duration := s.lockoutCooldown
+	for i := int64(s.lockoutThreshold); i < failures && duration < s.lockoutMaxCooldown; i++ {
+		duration *= 2
+	}
+	if duration > s.lockoutMaxCooldown {
+		return s.lockoutMaxCooldown
+	}
+	return duration
+}
+
+// ResetLoginFailures forgets the failed logins of email, after a successful
+// login or when an admin unlocks the account.
+func (s *RateLimitService) ResetLoginFailures(email string) error {
+	return s.repo.Unlock(context.Background(), email)
+}
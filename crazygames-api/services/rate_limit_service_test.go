@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"crazygames.io/handler/request"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRateLimitRepo counts requests per key without expiring them.
+type fakeRateLimitRepo struct {
+	requests map[string]int
+	failures map[string]int64
+	locks    map[string]time.Duration
+}
+
+func newFakeRateLimitRepo() *fakeRateLimitRepo {
+	return &fakeRateLimitRepo{requests: map[string]int{}, failures: map[string]int64{}, locks: map[string]time.Duration{}}
+}
+
+func (r *fakeRateLimitRepo) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	if r.requests[key] >= limit {
+		return false, window, nil
+	}
+	r.requests[key]++
+	return true, 0, nil
+}
+
+func (r *fakeRateLimitRepo) RecordFailure(ctx context.Context, account string, ttl time.Duration) (int64, error) {
+	r.failures[strings.ToLower(account)]++
+	return r.failures[strings.ToLower(account)], nil
+}
+
+func (r *fakeRateLimitRepo) Lock(ctx context.Context, account string, duration time.Duration) error {
+	r.locks[strings.ToLower(account)] = duration
+	return nil
+}
+
+func (r *fakeRateLimitRepo) LockedFor(ctx context.Context, account string) (time.Duration, error) {
+	return r.locks[strings.ToLower(account)], nil
+}
+
+func (r *fakeRateLimitRepo) Unlock(ctx context.Context, account string) error {
+	delete(r.locks, strings.ToLower(account))
+	delete(r.failures, strings.ToLower(account))
+	return nil
+}
+
+func newTestRateLimitService(repo *fakeRateLimitRepo) *RateLimitService {
+	svc := NewRateLimitService(repo)
+	svc.window = 15 * time.Minute
+	svc.loginLimitPerAccount = 100
+	svc.forgotPasswordLimitPerAccount = 3
+	svc.lockoutThreshold = 3
+	svc.lockoutCooldown = time.Minute
+	svc.lockoutMaxCooldown = 5 * time.Minute
+	return svc
+}
+
+func Test_LoginLockout(t *testing.T) {
+	repo := newFakeRateLimitRepo()
+	svc, _ := newTestAuthService()
+	svc.rateLimitSvc = newTestRateLimitService(repo)
+	svc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+
+	login := func(email, password string) error {
+		_, err := svc.Login(&request.LoginRequest{Email: email, Password: password})
+		return err
+	}
+
+	t.Run("unknown email and wrong password should fail the same way", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidCredentials, login("unknown@example.com", "password"))
+		assert.Equal(t, ErrInvalidCredentials, login("player@example.com", "wrong"))
+	})
+
+	t.Run("successful login should reset the failures", func(t *testing.T) {
+		assert.NoError(t, login("player@example.com", "password"))
+		assert.Zero(t, repo.failures["player@example.com"])
+	})
+
+	t.Run("failures from the threshold on should lock for longer each time", func(t *testing.T) {
+		login("player@example.com", "wrong")
+		login("player@example.com", "wrong")
+		assert.Zero(t, repo.locks["player@example.com"])
+
+		login("player@example.com", "wrong")
+		assert.Equal(t, time.Minute, repo.locks["player@example.com"])
+
+		for _, expected := range []time.Duration{2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute} {
+			delete(repo.locks, "player@example.com")
+			login("player@example.com", "wrong")
+			assert.Equal(t, expected, repo.locks["player@example.com"])
+		}
+	})
+
+	t.Run("locked account should refuse the right password", func(t *testing.T) {
+		var rateLimitErr *RateLimitError
+		assert.True(t, errors.As(login("player@example.com", "password"), &rateLimitErr))
+		assert.Equal(t, 5*time.Minute, rateLimitErr.RetryAfter)
+	})
+
+	t.Run("unlocked account should log in", func(t *testing.T) {
+		svc.rateLimitSvc.ResetLoginFailures("player@example.com")
+		assert.NoError(t, login("player@example.com", "password"))
+	})
+}
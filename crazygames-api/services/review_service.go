@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+)
+
+var (
+	ErrReviewExists    = errors.New("you already reviewed this game, edit your review instead")
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrNotReviewAuthor = errors.New("only the author can change a review")
+	ErrOwnReviewVote   = errors.New("you cannot vote on your own review")
+)
+
+// ratingPrior makes a game's rating start from 5 reviews of 3 stars, so that
+// a single 5-star review does not top the charts.
+var ratingPrior = repositories.RatingPrior{Mean: 3, Weight: 5}
+
+type ReviewService struct {
+	reviewRepo repositories.ReviewRepositoryInterface
+	gameRepo   repositories.GameRepositoryInterface
+}
+
+type ReviewServiceInterface interface {
+	GetByGame(gameID uint, query request.ReviewsRequestQuery) ([]repositories.GameReview, int64, error)
+	Create(ctx context.Context, userID uint, gameID uint, request *request.ReviewRequest) (*entities.Review, error)
+	Update(ctx context.Context, userID uint, id uint, request *request.ReviewRequest) (*entities.Review, error)
+	Delete(ctx context.Context, userID uint, id uint) error
+	Vote(userID uint, id uint) error
+	Unvote(userID uint, id uint) error
+}
+
+func NewReviewService(reviewRepo repositories.ReviewRepositoryInterface, gameRepo repositories.GameRepositoryInterface) *ReviewService {
+	return &ReviewService{reviewRepo: reviewRepo, gameRepo: gameRepo}
+}
+
+func (s *ReviewService) GetByGame(gameID uint, query request.ReviewsRequestQuery) ([]repositories.GameReview, int64, error) {
+	if err := s.checkGame(gameID); err != nil {
+		return nil, 0, err
+	}
+	return s.reviewRepo.GetByGame(gameID, query)
+}
+
+// Create adds the review of the game by the user, who can only review each
+// game once.
+func (s *ReviewService) Create(ctx context.Context, userID uint, gameID uint, request *request.ReviewRequest) (*entities.Review, error) {
+	if err := s.checkGame(gameID); err != nil {
+		return nil, err
+	}
+	existing, err := s.reviewRepo.GetByGameAndUser(gameID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrReviewExists
+	}
+
+	review := &entities.Review{GameID: gameID, UserID: &userID, Rating: request.Rating, Comment: request.Comment}
+	if err := s.reviewRepo.Create(ctx, review, ratingPrior); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (s *ReviewService) Update(ctx context.Context, userID uint, id uint, request *request.ReviewRequest) (*entities.Review, error) {
+	review, err := s.getOwnReview(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	review.Rating = request.Rating
+	review.Comment = request.Comment
+	if err := s.reviewRepo.Update(ctx, review, ratingPrior); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func (s *ReviewService) Delete(ctx context.Context, userID uint, id uint) error {
+	review, err := s.getOwnReview(userID, id)
+	if err != nil {
+		return err
+	}
+	return s.reviewRepo.Delete(ctx, review, ratingPrior)
+}
+
+// Vote marks the review as helpful to the user. Voting twice counts once.
+func (s *ReviewService) Vote(userID uint, id uint) error {
+	if err := s.checkVote(userID, id); err != nil {
+		return err
+	}
+	return s.reviewRepo.AddVote(id, userID)
+}
+
+func (s *ReviewService) Unvote(userID uint, id uint) error {
+	if err := s.checkVote(userID, id); err != nil {
+		return err
+	}
+	return s.reviewRepo.RemoveVote(id, userID)
+}
+
+func (s *ReviewService) checkGame(gameID uint) error {
+	exists, err := s.gameRepo.Exists(gameID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrGameNotFound
+	}
+	return nil
+}
+
+func (s *ReviewService) getReview(id uint) (*entities.Review, error) {
+	review, err := s.reviewRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+func (s *ReviewService) getOwnReview(userID uint, id uint) (*entities.Review, error) {
+	review, err := s.getReview(id)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserID == nil || *review.UserID != userID {
+		return nil, ErrNotReviewAuthor
+	}
+	return review, nil
+}
+
+func (s *ReviewService) checkVote(userID uint, id uint) error {
+	review, err := s.getReview(id)
+	if err != nil {
+		return err
+	}
+	if review.UserID != nil && *review.UserID == userID {
+		return ErrOwnReviewVote
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReviewRepo struct {
+	repositories.ReviewRepositoryInterface
+	reviews map[uint]*entities.Review
+	votes   map[uint]map[uint]bool
+	prior   repositories.RatingPrior
+}
+
+func (r *fakeReviewRepo) GetByID(id uint) (*entities.Review, error) {
+	if review, ok := r.reviews[id]; ok {
+		stored := *review
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeReviewRepo) GetByGameAndUser(gameID uint, userID uint) (*entities.Review, error) {
+	for _, review := range r.reviews {
+		if review.GameID == gameID && review.UserID != nil && *review.UserID == userID {
+			return review, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeReviewRepo) Create(ctx context.Context, review *entities.Review, prior repositories.RatingPrior) error {
+	review.ID = uint(len(r.reviews) + 1)
+	r.reviews[review.ID] = review
+	r.prior = prior
+	return nil
+}
+
+func (r *fakeReviewRepo) Update(ctx context.Context, review *entities.Review, prior repositories.RatingPrior) error {
+	r.reviews[review.ID] = review
+	return nil
+}
+
+func (r *fakeReviewRepo) Delete(ctx context.Context, review *entities.Review, prior repositories.RatingPrior) error {
+	delete(r.reviews, review.ID)
+	return nil
+}
+
+func (r *fakeReviewRepo) AddVote(reviewID uint, userID uint) error {
+	if r.votes[reviewID] == nil {
+		r.votes[reviewID] = map[uint]bool{}
+	}
+	r.votes[reviewID][userID] = true
+	return nil
+}
+
+func Test_Reviews(t *testing.T) {
+	reviewRepo := &fakeReviewRepo{reviews: map[uint]*entities.Review{}, votes: map[uint]map[uint]bool{}}
+	svc := NewReviewService(reviewRepo, &fakeGameRepo{playCounts: map[uint]int64{1: 0}})
+	ctx := context.Background()
+	authorID, otherID := uint(1), uint(2)
+
+	review, err := svc.Create(ctx, authorID, 1, &request.ReviewRequest{Rating: 5, Comment: "Great"})
+	assert.NoError(t, err)
+	assert.Equal(t, ratingPrior, reviewRepo.prior, "game rating should be a Bayesian average")
+
+	t.Run("review of an unknown game should fail", func(t *testing.T) {
+		_, err := svc.Create(ctx, authorID, 2, &request.ReviewRequest{Rating: 5})
+		assert.ErrorIs(t, err, ErrGameNotFound)
+	})
+
+	t.Run("second review of the same game should fail", func(t *testing.T) {
+		_, err := svc.Create(ctx, authorID, 1, &request.ReviewRequest{Rating: 1})
+		assert.ErrorIs(t, err, ErrReviewExists)
+	})
+
+	t.Run("only the author should change a review", func(t *testing.T) {
+		_, err := svc.Update(ctx, otherID, review.ID, &request.ReviewRequest{Rating: 1})
+		assert.ErrorIs(t, err, ErrNotReviewAuthor)
+		assert.ErrorIs(t, svc.Delete(ctx, otherID, review.ID), ErrNotReviewAuthor)
+		assert.Equal(t, 5, reviewRepo.reviews[review.ID].Rating)
+
+		updated, err := svc.Update(ctx, authorID, review.ID, &request.ReviewRequest{Rating: 4, Comment: "Good"})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, updated.Rating)
+		assert.Equal(t, "Good", reviewRepo.reviews[review.ID].Comment)
+	})
+
+	t.Run("authors should not vote on their own review", func(t *testing.T) {
+		assert.ErrorIs(t, svc.Vote(authorID, review.ID), ErrOwnReviewVote)
+		assert.NoError(t, svc.Vote(otherID, review.ID))
+		assert.True(t, reviewRepo.votes[review.ID][otherID])
+		assert.ErrorIs(t, svc.Vote(otherID, 99), ErrReviewNotFound)
+	})
+
+	t.Run("deleted review should be gone", func(t *testing.T) {
+		assert.NoError(t, svc.Delete(ctx, authorID, review.ID))
+		_, err := svc.Update(ctx, authorID, review.ID, &request.ReviewRequest{Rating: 4})
+		assert.ErrorIs(t, err, ErrReviewNotFound)
+	})
+}
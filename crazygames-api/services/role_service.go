@@ -0,0 +1,29 @@
+package services
+
+import (
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+)
+
+type RoleService struct {
+	roleRepo repositories.RoleRepositoryInterface
+}
+
+type RoleServiceInterface interface {
+	GetAll() ([]entities.Role, error)
+	HasPermission(role string, permission string) (bool, error)
+}
+
+func NewRoleService(roleRepo repositories.RoleRepositoryInterface) *RoleService {
+	return &RoleService{roleRepo: roleRepo}
+}
+
+func (s *RoleService) GetAll() ([]entities.Role, error) {
+	return s.roleRepo.GetAll()
+}
+
+// HasPermission is looked up on every request, so that changes to a role
+// apply to its users straight away.
+func (s *RoleService) HasPermission(role string, permission string) (bool, error) {
+	return s.roleRepo.HasPermission(role, permission)
+}
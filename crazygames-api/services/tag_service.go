@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"crazygames.io/utils"
+)
+
+var (
+	ErrTagNotFound    = errors.New("tag not found")
+	ErrTagExists      = errors.New("a tag with this name or slug already exists")
+	ErrInvalidTagSlug = errors.New("tag slug must contain letters or digits")
+)
+
+type TagService struct {
+	tagRepo  repositories.TagRepositoryInterface
+	gameRepo repositories.GameRepositoryInterface
+	gameSvc  GameServiceInterface
+}
+
+type TagServiceInterface interface {
+	GetAll(query request.TagsRequestQuery) ([]entities.Tag, error)
+	Create(ctx context.Context, request *request.TagRequest) (*entities.Tag, error)
+	Update(ctx context.Context, id uint, request *request.TagRequest) (*entities.Tag, error)
+	Delete(ctx context.Context, id uint) error
+	GetGames(slug string, query request.TagGamesRequestQuery, userID *uint) ([]entities.Game, int64, error)
+	SetGameTags(ctx context.Context, gameID uint, tagIDs []uint) ([]entities.Tag, error)
+}
+
+func NewTagService(tagRepo repositories.TagRepositoryInterface, gameRepo repositories.GameRepositoryInterface, gameSvc GameServiceInterface) *TagService {
+	return &TagService{tagRepo: tagRepo, gameRepo: gameRepo, gameSvc: gameSvc}
+}
+
+func (s *TagService) GetAll(query request.TagsRequestQuery) ([]entities.Tag, error) {
+	return s.tagRepo.GetAll(strings.TrimSpace(query.Search))
+}
+
+func (s *TagService) Create(ctx context.Context, request *request.TagRequest) (*entities.Tag, error) {
+	tag, err := s.newTag(0, request)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) Update(ctx context.Context, id uint, request *request.TagRequest) (*entities.Tag, error) {
+	existing, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrTagNotFound
+	}
+	tag, err := s.newTag(id, request)
+	if err != nil {
+		return nil, err
+	}
+	return s.tagRepo.Update(ctx, tag)
+}
+
+// Delete removes the tag from its games before deleting it.
+func (s *TagService) Delete(ctx context.Context, id uint) error {
+	existing, err := s.tagRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrTagNotFound
+	}
+	return s.tagRepo.Delete(ctx, id)
+}
+
+// GetGames returns a page of the games with the tag, the same way the game
+// list does when filtered by that tag.
+func (s *TagService) GetGames(slug string, query request.TagGamesRequestQuery, userID *uint) ([]entities.Game, int64, error) {
+	tag, err := s.tagRepo.GetBySlug(slug)
+	if err != nil {
+		return nil, 0, err
+	}
+	if tag == nil {
+		return nil, 0, ErrTagNotFound
+	}
+	return s.gameSvc.GetAll(request.GamesRequestQuery{
+		PageNumber: query.PageNumber,
+		PageSize:   query.PageSize,
+		Tags:       tag.Slug,
+	}, userID)
+}
+
+// SetGameTags replaces the tags of the game, failing if any of them does not
+// exist.
+func (s *TagService) SetGameTags(ctx context.Context, gameID uint, tagIDs []uint) ([]entities.Tag, error) {
+	exists, err := s.gameRepo.Exists(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrGameNotFound
+	}
+
+	seen := make(map[uint]bool, len(tagIDs))
+	ids := make([]uint, 0, len(tagIDs))
+	for _, id := range tagIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	tags, err := s.tagRepo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != len(ids) {
+		return nil, ErrTagNotFound
+	}
+
+	if err := s.tagRepo.SetGameTags(ctx, gameID, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// newTag builds the tag from the request, checking that no other tag than id
+// has its name or slug.
+func (s *TagService) newTag(id uint, request *request.TagRequest) (*entities.Tag, error) {
+	name := strings.TrimSpace(request.TagName)
+	slug := request.Slug
+	if slug == "" {
+		slug = name
+	}
+	slug = utils.Slugify(slug)
+	if slug == "" {
+		return nil, ErrInvalidTagSlug
+	}
+
+	exists, err := s.tagRepo.Exists(name, slug, id)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrTagExists
+	}
+	return &entities.Tag{ID: id, TagName: name, Slug: slug}, nil
+}
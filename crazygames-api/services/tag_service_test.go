@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTagRepo struct {
+	repositories.TagRepositoryInterface
+	tags     []entities.Tag
+	gameTags map[uint][]entities.Tag
+}
+
+func (r *fakeTagRepo) Create(ctx context.Context, tag *entities.Tag) error {
+	tag.ID = uint(len(r.tags) + 1)
+	r.tags = append(r.tags, *tag)
+	return nil
+}
+
+func (r *fakeTagRepo) GetByID(id uint) (*entities.Tag, error) {
+	for _, tag := range r.tags {
+		if tag.ID == id {
+			return &tag, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTagRepo) GetBySlug(slug string) (*entities.Tag, error) {
+	for _, tag := range r.tags {
+		if tag.Slug == slug {
+			return &tag, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTagRepo) GetByIDs(ids []uint) ([]entities.Tag, error) {
+	var tags []entities.Tag
+	for _, id := range ids {
+		if tag, _ := r.GetByID(id); tag != nil {
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+func (r *fakeTagRepo) Exists(name string, slug string, exceptID uint) (bool, error) {
+	for _, tag := range r.tags {
+		if tag.ID != exceptID && (tag.TagName == name || tag.Slug == slug) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeTagRepo) Update(ctx context.Context, tag *entities.Tag) (*entities.Tag, error) {
+	r.tags[tag.ID-1] = *tag
+	return tag, nil
+}
+
+func (r *fakeTagRepo) SetGameTags(ctx context.Context, gameID uint, tags []entities.Tag) error {
+	r.gameTags[gameID] = tags
+	return nil
+}
+
+type fakeGameService struct {
+	GameServiceInterface
+	query request.GamesRequestQuery
+}
+
+func (s *fakeGameService) GetAll(query request.GamesRequestQuery, userID *uint) ([]entities.Game, int64, error) {
+	s.query = query
+	return []entities.Game{{ID: 1}}, 1, nil
+}
+
+func Test_Tags(t *testing.T) {
+	ctx := context.Background()
+	tagRepo := &fakeTagRepo{gameTags: map[uint][]entities.Tag{}}
+	gameSvc := &fakeGameService{}
+	svc := NewTagService(tagRepo, &fakeGameRepo{playCounts: map[uint]int64{1: 0}}, gameSvc)
+
+	t.Run("slug should default to the tag name", func(t *testing.T) {
+		tag, err := svc.Create(ctx, &request.TagRequest{TagName: " Tower Defense "})
+		assert.NoError(t, err)
+		assert.Equal(t, "Tower Defense", tag.TagName)
+		assert.Equal(t, "tower-defense", tag.Slug)
+
+		tag, err = svc.Create(ctx, &request.TagRequest{TagName: "2 Player", Slug: "Two Player"})
+		assert.NoError(t, err)
+		assert.Equal(t, "two-player", tag.Slug)
+	})
+
+	t.Run("tags should be unique by name and slug", func(t *testing.T) {
+		_, err := svc.Create(ctx, &request.TagRequest{TagName: "Tower defense!"})
+		assert.ErrorIs(t, err, ErrTagExists)
+		_, err = svc.Create(ctx, &request.TagRequest{TagName: "2 Player"})
+		assert.ErrorIs(t, err, ErrTagExists)
+		_, err = svc.Create(ctx, &request.TagRequest{TagName: "!!"})
+		assert.ErrorIs(t, err, ErrInvalidTagSlug)
+
+		tag, err := svc.Update(ctx, 1, &request.TagRequest{TagName: "Tower Defense", Slug: "td"})
+		assert.NoError(t, err, "a tag should keep its own name")
+		assert.Equal(t, "td", tag.Slug)
+		_, err = svc.Update(ctx, 3, &request.TagRequest{TagName: "Racing"})
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+
+	t.Run("tag games should be the games filtered by the tag", func(t *testing.T) {
+		games, total, err := svc.GetGames("td", request.TagGamesRequestQuery{PageNumber: 2, PageSize: 10}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, games, 1)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, request.GamesRequestQuery{PageNumber: 2, PageSize: 10, Tags: "td"}, gameSvc.query)
+
+		_, _, err = svc.GetGames("tower-defense", request.TagGamesRequestQuery{PageNumber: 1, PageSize: 10}, nil)
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+
+	t.Run("game tags should all exist", func(t *testing.T) {
+		_, err := svc.SetGameTags(ctx, 1, []uint{1, 3})
+		assert.ErrorIs(t, err, ErrTagNotFound)
+		_, err = svc.SetGameTags(ctx, 2, []uint{1})
+		assert.ErrorIs(t, err, ErrGameNotFound)
+		assert.Empty(t, tagRepo.gameTags)
+
+		tags, err := svc.SetGameTags(ctx, 1, []uint{2, 1, 2})
+		assert.NoError(t, err)
+		assert.Len(t, tags, 2)
+		assert.Equal(t, tags, tagRepo.gameTags[1])
+
+		tags, err = svc.SetGameTags(ctx, 1, []uint{})
+		assert.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/handler/request"
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type fakeResetTokenRepo struct {
+	repositories.PasswordResetTokenRepositoryInterface
+	tokens map[string]*entities.PasswordResetToken // by email
+}
+
+func (r *fakeResetTokenRepo) Create(token *entities.PasswordResetToken) error {
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens[token.Email] = token
+	return nil
+}
+
+func (r *fakeResetTokenRepo) GetByEmail(email string) (*entities.PasswordResetToken, error) {
+	if token, ok := r.tokens[email]; ok {
+		return token, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeResetTokenRepo) GetByTokenHash(tokenHash string) (*entities.PasswordResetToken, error) {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeResetTokenRepo) SetResetToken(email, tokenHash string, expiresAt time.Time) error {
+	token := r.tokens[email]
+	token.TokenHash, token.ExpiredAt, token.IsUsed = tokenHash, expiresAt, false
+	return nil
+}
+
+func (r *fakeResetTokenRepo) MarkTokenAsUsed(tokenID uint) error {
+	for _, token := range r.tokens {
+		if token.ID == tokenID && !token.IsUsed {
+			token.IsUsed = true
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+type fakeRoleRepo struct {
+	repositories.RoleRepositoryInterface
+}
+
+func (fakeRoleRepo) GetByName(name string) (*entities.Role, error) {
+	switch name {
+	case entities.RoleAdmin, entities.RoleEditor, entities.RoleAdManager, entities.RolePlayer:
+		return &entities.Role{Name: name}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// revokingSessionRepo records the users whose sessions were revoked, and the
+// session kept when only the others were.
+type revokingSessionRepo struct {
+	repositories.SessionRepositoryInterface
+	revoked    []uint
+	kept       string
+	loggedInAt map[string]time.Time
+}
+
+func (r *revokingSessionRepo) GetCreatedAt(ctx context.Context, familyID string) (time.Time, error) {
+	return r.loggedInAt[familyID], nil
+}
+
+func (r *revokingSessionRepo) RevokeAllForUser(ctx context.Context, userID uint) error {
+	r.revoked = append(r.revoked, userID)
+	return nil
+}
+
+func (r *revokingSessionRepo) RevokeOthersForUser(ctx context.Context, userID uint, keepFamilyID string) error {
+	r.revoked = append(r.revoked, userID)
+	r.kept = keepFamilyID
+	return nil
+}
+
+func Test_PasswordReset(t *testing.T) {
+	userRepo := &fakeUserRepo{}
+	resetTokenRepo := &fakeResetTokenRepo{tokens: map[string]*entities.PasswordResetToken{}}
+	sessionRepo := &revokingSessionRepo{}
+	svc := NewUserService(userRepo, resetTokenRepo, sessionRepo, nil, newTestRateLimitService(newFakeRateLimitRepo()))
+	svc.resetTokenTTL = time.Hour
+	userRepo.Create(context.Background(), &entities.User{Username: "player", Email: "player@example.com"})
+
+	t.Run("unknown email should not get a token", func(t *testing.T) {
+		_, err := svc.GenerateResetToken("unknown@example.com")
+		assert.ErrorIs(t, err, ErrEmailNotRegistered)
+	})
+
+	oldToken, err := svc.GenerateResetToken("player@example.com")
+	assert.NoError(t, err, "failed to generate reset token")
+
+	t.Run("only the hash should be stored", func(t *testing.T) {
+		stored := resetTokenRepo.tokens["player@example.com"]
+		assert.NotEqual(t, oldToken, stored.TokenHash)
+		assert.Len(t, stored.TokenHash, 64)
+	})
+
+	newToken, err := svc.GenerateResetToken("player@example.com")
+	assert.NoError(t, err, "resend should not be refused")
+
+	t.Run("resend should supersede the old token", func(t *testing.T) {
+		err := svc.ResetPassword(oldToken, "new-password")
+		assert.ErrorIs(t, err, ErrInvalidResetToken)
+	})
+
+	t.Run("expired token should fail", func(t *testing.T) {
+		resetTokenRepo.tokens["player@example.com"].ExpiredAt = time.Now().Add(-time.Minute)
+		defer func() { resetTokenRepo.tokens["player@example.com"].ExpiredAt = time.Now().Add(time.Hour) }()
+
+		err := svc.ResetPassword(newToken, "new-password")
+		assert.ErrorIs(t, err, ErrResetTokenExpired)
+	})
+
+	t.Run("reset should set the password and revoke the sessions", func(t *testing.T) {
+		err := svc.ResetPassword(newToken, "new-password")
+		assert.NoError(t, err)
+
+		user, _ := userRepo.GetByEmail("player@example.com")
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("new-password")))
+		assert.Equal(t, []uint{user.ID}, sessionRepo.revoked)
+	})
+
+	t.Run("token should only work once", func(t *testing.T) {
+		err := svc.ResetPassword(newToken, "other-password")
+		assert.ErrorIs(t, err, ErrResetTokenUsed)
+	})
+}
+
+func Test_AssignRole(t *testing.T) {
+	userRepo := &fakeUserRepo{}
+	sessionRepo := &revokingSessionRepo{}
+	svc := NewUserService(userRepo, nil, sessionRepo, fakeRoleRepo{}, nil)
+	userRepo.Create(context.Background(), &entities.User{Username: "admin", Email: "admin@example.com", Role: entities.RoleAdmin})
+	userRepo.Create(context.Background(), &entities.User{Username: "player", Email: "player@example.com", Role: entities.RolePlayer})
+
+	t.Run("unknown role should fail", func(t *testing.T) {
+		_, err := svc.AssignRole(context.Background(), 1, 2, "superuser")
+		assert.ErrorIs(t, err, ErrUnknownRole)
+	})
+
+	t.Run("admin should not change their own role", func(t *testing.T) {
+		_, err := svc.AssignRole(context.Background(), 1, 1, entities.RolePlayer)
+		assert.ErrorIs(t, err, ErrChangeOwnRole)
+	})
+
+	t.Run("assign should change the role and revoke the sessions", func(t *testing.T) {
+		user, err := svc.AssignRole(context.Background(), 1, 2, entities.RoleEditor)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.RoleEditor, user.Role)
+		assert.Equal(t, []uint{2}, sessionRepo.revoked)
+	})
+}
+
+func Test_SetStatus(t *testing.T) {
+	authSvc, _ := newTestAuthService()
+	sessionRepo := &revokingSessionRepo{}
+	svc := NewUserService(authSvc.userRepo, nil, sessionRepo, fakeRoleRepo{}, nil)
+	authSvc.Register(&request.RegisterRequest{Username: "admin", Email: "admin@example.com", Password: "password"})
+	authSvc.Register(&request.RegisterRequest{Username: "player", Email: "player@example.com", Password: "password"})
+	login := &request.LoginRequest{Email: "player@example.com", Password: "password"}
+
+	t.Run("moderator should not change their own status", func(t *testing.T) {
+		_, err := svc.SetStatus(context.Background(), 1, 1, &request.UserStatusRequest{Status: entities.UserBanned})
+		assert.ErrorIs(t, err, ErrChangeOwnStatus)
+	})
+
+	t.Run("suspension ending in the past should fail", func(t *testing.T) {
+		until := time.Now().Add(-time.Hour)
+		_, err := svc.SetStatus(context.Background(), 1, 2, &request.UserStatusRequest{Status: entities.UserSuspended, Until: &until})
+		assert.ErrorIs(t, err, ErrSuspensionEnded)
+	})
+
+	t.Run("suspended user should be logged out and refused at login", func(t *testing.T) {
+		until := time.Now().Add(time.Hour)
+		user, err := svc.SetStatus(context.Background(), 1, 2, &request.UserStatusRequest{Status: entities.UserSuspended, Until: &until, Reason: "spam"})
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), *user.StatusChangedBy)
+		assert.Equal(t, []uint{2}, sessionRepo.revoked)
+
+		_, err = authSvc.Login(login)
+		var restrictedErr *AccountRestrictedError
+		assert.ErrorAs(t, err, &restrictedErr)
+		assert.Contains(t, err.Error(), "suspended until")
+		assert.Contains(t, err.Error(), "spam")
+	})
+
+	t.Run("ended suspension should not refuse the login", func(t *testing.T) {
+		user, _ := authSvc.userRepo.GetByID(2)
+		ended := time.Now().Add(-time.Minute)
+		user.SuspendedUntil = &ended
+
+		_, err := authSvc.Login(login)
+		assert.NoError(t, err)
+	})
+
+	t.Run("banned user should be refused at login", func(t *testing.T) {
+		_, err := svc.SetStatus(context.Background(), 1, 2, &request.UserStatusRequest{Status: entities.UserBanned})
+		assert.NoError(t, err)
+
+		_, err = authSvc.Login(login)
+		var restrictedErr *AccountRestrictedError
+		assert.ErrorAs(t, err, &restrictedErr)
+		assert.Equal(t, "account is banned", err.Error())
+	})
+
+	t.Run("lifted user should log in again", func(t *testing.T) {
+		user, err := svc.SetStatus(context.Background(), 1, 2, &request.UserStatusRequest{Status: entities.UserActive})
+		assert.NoError(t, err)
+		assert.Nil(t, user.SuspendedUntil)
+
+		_, err = authSvc.Login(login)
+		assert.NoError(t, err)
+	})
+}
+
+func Test_ExportUsersCSV(t *testing.T) {
+	userRepo := &fakeUserRepo{}
+	svc := NewUserService(userRepo, nil, nil, fakeRoleRepo{}, nil)
+	userRepo.Create(context.Background(), &entities.User{Username: "player", Email: "player@example.com", Role: entities.RolePlayer, Status: entities.UserActive})
+	userRepo.Create(context.Background(), &entities.User{Username: "=HYPERLINK(\"http://evil\")", Email: "evil@example.com", Role: entities.RolePlayer, Status: entities.UserBanned, StatusReason: "spam, twice"})
+
+	var out strings.Builder
+	assert.NoError(t, svc.ExportCSV(request.UsersRequestQuery{}, &out))
+
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, userCSVHeader, rows[0])
+	assert.Equal(t, "player@example.com", rows[1][2])
+	assert.Equal(t, "'=HYPERLINK(\"http://evil\")", rows[2][1], "formulas should be escaped")
+	assert.Equal(t, "spam, twice", rows[2][7])
+}
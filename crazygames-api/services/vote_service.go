@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"crazygames.io/entities"
+	"crazygames.io/repositories"
+)
+
+// Votes of a VoteRequest.
+const (
+	VoteUp    = "up"
+	VoteDown  = "down"
+	VoteClear = "clear"
+)
+
+// DeviceCookieTTL is how long a device keeps its ID, and its votes with it.
+const DeviceCookieTTL = 365 * 24 * time.Hour
+
+var voteValues = map[string]int{VoteUp: entities.VoteUp, VoteDown: entities.VoteDown, VoteClear: 0}
+
+// VoteResult is the vote of the player on a game, empty when cleared, with
+// the game's counters after it.
+type VoteResult struct {
+	Vote      string  `json:"vote"`
+	UpVotes   int     `json:"up_votes"`
+	DownVotes int     `json:"down_votes"`
+	LikeRatio float64 `json:"like_ratio"`
+}
+
+// VoteService records the thumbs up and down of the players on the games,
+// once per logged in user or per device of anonymous players.
+type VoteService struct {
+	gameRepo       repositories.GameRepositoryInterface
+	gameVoteRepo   repositories.GameVoteRepositoryInterface
+	deviceVoteRepo repositories.DeviceVoteRepositoryInterface
+}
+
+type VoteServiceInterface interface {
+	Vote(userID *uint, deviceID string, gameID uint, vote string) (*VoteResult, error)
+}
+
+func NewVoteService(gameRepo repositories.GameRepositoryInterface, gameVoteRepo repositories.GameVoteRepositoryInterface, deviceVoteRepo repositories.DeviceVoteRepositoryInterface) *VoteService {
+	return &VoteService{gameRepo: gameRepo, gameVoteRepo: gameVoteRepo, deviceVoteRepo: deviceVoteRepo}
+}
+
+// Vote replaces the vote of the user on the game, or of the device when
+// there is no user. Voting the same twice counts once.
+func (s *VoteService) Vote(userID *uint, deviceID string, gameID uint, vote string) (*VoteResult, error) {
+	exists, err := s.gameRepo.Exists(gameID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrGameNotFound
+	}
+
+	value := voteValues[vote]
+	var counts *repositories.VoteCounts
+	if userID != nil {
+		counts, err = s.gameVoteRepo.SetUserVote(gameID, *userID, value)
+	} else {
+		var previous int
+		previous, err = s.deviceVoteRepo.Swap(context.Background(), gameID, deviceID, value, DeviceCookieTTL)
+		if err != nil {
+			return nil, err
+		}
+		counts, err = s.gameVoteRepo.UpdateCounts(gameID, previous, value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VoteResult{
+		UpVotes:   counts.UpVotes,
+		DownVotes: counts.DownVotes,
+		LikeRatio: entities.LikeRatio(counts.UpVotes, counts.DownVotes),
+	}
+	if value != 0 {
+		result.Vote = vote
+	}
+	return result, nil
+}
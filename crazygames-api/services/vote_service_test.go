@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"crazygames.io/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGameVoteRepo struct {
+	counts repositories.VoteCounts
+	votes  map[uint]int
+}
+
+func (r *fakeGameVoteRepo) SetUserVote(gameID uint, userID uint, value int) (*repositories.VoteCounts, error) {
+	previous := r.votes[userID]
+	r.votes[userID] = value
+	return r.UpdateCounts(gameID, previous, value)
+}
+
+func (r *fakeGameVoteRepo) UpdateCounts(gameID uint, previous int, value int) (*repositories.VoteCounts, error) {
+	for vote, counter := range map[int]*int{1: &r.counts.UpVotes, -1: &r.counts.DownVotes} {
+		if previous == vote {
+			*counter--
+		}
+		if value == vote {
+			*counter++
+		}
+	}
+	counts := r.counts
+	return &counts, nil
+}
+
+type fakeDeviceVoteRepo struct {
+	votes map[string]int
+}
+
+func (r *fakeDeviceVoteRepo) Swap(ctx context.Context, gameID uint, deviceID string, value int, ttl time.Duration) (int, error) {
+	previous := r.votes[deviceID]
+	r.votes[deviceID] = value
+	return previous, nil
+}
+
+func Test_Vote(t *testing.T) {
+	voteRepo := &fakeGameVoteRepo{votes: map[uint]int{}}
+	svc := NewVoteService(&fakeGameRepo{playCounts: map[uint]int64{1: 0}}, voteRepo, &fakeDeviceVoteRepo{votes: map[string]int{}})
+	userID := uint(1)
+
+	t.Run("vote on an unknown game should fail", func(t *testing.T) {
+		_, err := svc.Vote(&userID, "", 2, VoteUp)
+		assert.ErrorIs(t, err, ErrGameNotFound)
+	})
+
+	t.Run("voting twice should count once", func(t *testing.T) {
+		svc.Vote(&userID, "", 1, VoteUp)
+		result, err := svc.Vote(&userID, "", 1, VoteUp)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteResult{Vote: VoteUp, UpVotes: 1, LikeRatio: 1}, result)
+	})
+
+	t.Run("anonymous votes should count once per device", func(t *testing.T) {
+		svc.Vote(nil, "device", 1, VoteDown)
+		result, err := svc.Vote(nil, "device", 1, VoteDown)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteResult{Vote: VoteDown, UpVotes: 1, DownVotes: 1, LikeRatio: 0.5}, result)
+	})
+
+	t.Run("changing and clearing a vote should move the counters", func(t *testing.T) {
+		result, err := svc.Vote(nil, "device", 1, VoteUp)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteResult{Vote: VoteUp, UpVotes: 2, LikeRatio: 1}, result)
+
+		result, err = svc.Vote(&userID, "", 1, VoteClear)
+		assert.NoError(t, err)
+		assert.Equal(t, &VoteResult{UpVotes: 1, LikeRatio: 1}, result)
+	})
+}
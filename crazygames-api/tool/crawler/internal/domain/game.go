@@ -1,23 +1,23 @@
-package domain
-
-type GameData struct {
-	Name           string
-	URL            string
-	Rating         string
-	RatingVotes    string
-	Developer      string
-	ReleaseDate    string
-	LastUpdated    string
-	Technology     string
-	Platforms      string
-	Classification string
-	WikiPages      string
-	Iframe         string
-	Description    string
-	Features       string
-	Controls       string
-	FAQ            string
-	GameplayVideo  string
-	HoverVideo     string
-	ThumbnailURL   string
-}
+package domain
+
+type GameData struct {
+	Name           string
+	URL            string
+	Rating         string
+	RatingVotes    string
+	Developer      string
+	ReleaseDate    string
+	LastUpdated    string
+	Technology     string
+	Platforms      string
+	Classification string
+	WikiPages      string
+	Iframe         string
+	Description    string
+	Features       string
+	Controls       string
+	FAQ            string
+	GameplayVideo  string
+	HoverVideo     string
+	ThumbnailURL   string
+}
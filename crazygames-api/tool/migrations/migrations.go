@@ -1,100 +1,462 @@
-package main
-
-import (
-	"log"
-
-	"crazygames.io/config"
-	"crazygames.io/entities"
-	"github.com/go-gormigrate/gormigrate/v2"
-	"gorm.io/driver/mysql"
-	"gorm.io/gorm"
-)
-
-func InitMigrations() []*gormigrate.Migration {
-	return []*gormigrate.Migration{
-		{
-			ID: "20250112_create_users_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.User{})
-			},
-		},
-		{
-			ID: "20250112_create_categories_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.Category{})
-			},
-		},
-		{
-			ID: "20250112_create_games_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.Game{})
-			},
-		},
-		{
-			ID: "20250112_create_reviews_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.Review{})
-			},
-		},
-		{
-			ID: "20250112_create_favorites_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.Favorite{})
-			},
-		},
-		{
-			ID: "20250112_create_tags_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.Tag{})
-			},
-		},
-		{
-			ID: "20250112_create_game_tags_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.GameTag{})
-			},
-		},
-		{
-			ID: "20250112_create_play_history_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.PlayHistory{})
-			},
-		},
-		{
-			ID: "20250115_create_ads_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.Ads{})
-			},
-		},
-		{
-			ID: "20250115_game_categories_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.GameCategory{})
-			},
-		},
-		{
-			ID: "20250112_password_reset_token_table",
-			Migrate: func(tx *gorm.DB) error {
-				return tx.AutoMigrate(&entities.PasswordResetToken{})
-			},
-		},
-	}
-}
-
-func main() {
-	config.LoadConfig()
-	db := config.ConnectDatabase()
-	gormDB, err := gorm.Open(mysql.New(mysql.Config{
-		Conn: db.ConnPool,
-	}), &gorm.Config{})
-	if err != nil {
-		log.Fatalf("failed to connect to MySQL database: %v", err)
-	}
-	m := gormigrate.New(gormDB, gormigrate.DefaultOptions, InitMigrations())
-
-	if err = m.Migrate(); err != nil {
-		log.Fatalf("could not migrate: %v", err)
-	}
-
-	log.Println("Migrations ran successfully")
-}
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"crazygames.io/config"
+	"crazygames.io/entities"
+	"crazygames.io/utils"
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+func InitMigrations() []*gormigrate.Migration {
+	return []*gormigrate.Migration{
+		{
+			ID: "20250112_create_users_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.User{})
+			},
+		},
+		{
+			ID: "20250112_create_categories_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Category{})
+			},
+		},
+		{
+			ID: "20250112_create_games_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Game{})
+			},
+		},
+		{
+			ID: "20250112_create_reviews_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Review{})
+			},
+		},
+		{
+			ID: "20250112_create_favorites_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Favorite{})
+			},
+		},
+		{
+			ID: "20250112_create_tags_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Tag{})
+			},
+		},
+		{
+			ID: "20250112_create_game_tags_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.GameTag{})
+			},
+		},
+		{
+			ID: "20250112_create_play_history_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.PlayHistory{})
+			},
+		},
+		{
+			ID: "20250115_create_ads_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Ads{})
+			},
+		},
+		{
+			ID: "20250115_game_categories_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.GameCategory{})
+			},
+		},
+		{
+			ID: "20250112_password_reset_token_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.PasswordResetToken{})
+			},
+		},
+		{
+			ID: "20261018_allow_users_without_password",
+			Migrate: func(tx *gorm.DB) error {
+				if tx.Migrator().HasConstraint(&entities.User{}, "chk_users_password") {
+					return tx.Migrator().DropConstraint(&entities.User{}, "chk_users_password")
+				}
+				return nil
+			},
+		},
+		{
+			ID: "20261018_create_user_identities_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.UserIdentity{})
+			},
+		},
+		{
+			ID: "20261018_add_users_email_verified",
+			Migrate: func(tx *gorm.DB) error {
+				if !tx.Migrator().HasColumn(&entities.User{}, "EmailVerified") {
+					if err := tx.Migrator().AddColumn(&entities.User{}, "EmailVerified"); err != nil {
+						return err
+					}
+				}
+				// Accounts from before verification existed are trusted as they
+				// are, or REQUIRE_EMAIL_VERIFICATION would lock them all out.
+				return tx.Exec("UPDATE users SET email_verified = true").Error
+			},
+		},
+		{
+			ID: "20261018_add_users_totp",
+			Migrate: func(tx *gorm.DB) error {
+				for _, field := range []string{"TOTPSecret", "TOTPEnabled", "TOTPLastStep"} {
+					if tx.Migrator().HasColumn(&entities.User{}, field) {
+						continue
+					}
+					if err := tx.Migrator().AddColumn(&entities.User{}, field); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			ID: "20261018_create_recovery_codes_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.RecoveryCode{})
+			},
+		},
+		{
+			ID: "20261018_hash_password_reset_tokens",
+			Migrate: func(tx *gorm.DB) error {
+				// The stored tokens cannot be hashed after the fact, so outstanding resets have to be requested again
+				if err := tx.Exec("DELETE FROM password_reset_tokens").Error; err != nil {
+					return err
+				}
+				if tx.Migrator().HasColumn("password_reset_tokens", "token") {
+					if err := tx.Migrator().DropColumn("password_reset_tokens", "token"); err != nil {
+						return err
+					}
+				}
+				return tx.AutoMigrate(&entities.PasswordResetToken{})
+			},
+		},
+		{
+			ID: "20261018_create_roles_and_permissions_tables",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.Permission{}, &entities.Role{})
+			},
+		},
+		{
+			ID: "20261018_seed_builtin_roles",
+			Migrate: func(tx *gorm.DB) error {
+				return seedRoles(tx, builtinPermissions, builtinRoles)
+			},
+		},
+		{
+			ID: "20261018_create_api_keys_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.APIKey{})
+			},
+		},
+		{
+			ID: "20261018_create_audit_events_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.AuditEvent{})
+			},
+		},
+		{
+			ID: "20261018_grant_audit_read",
+			Migrate: func(tx *gorm.DB) error {
+				permission := entities.Permission{Name: entities.PermissionAuditRead, Description: "Read the audit log"}
+				return grantPermission(tx, permission, entities.RoleAdmin)
+			},
+		},
+		{
+			ID: "20261018_add_users_profile",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.User{})
+			},
+		},
+		{
+			ID: "20261018_create_data_requests_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.DataRequest{})
+			},
+		},
+		{
+			ID: "20261018_add_users_status",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.User{})
+			},
+		},
+		{
+			ID: "20261018_grant_user_read",
+			Migrate: func(tx *gorm.DB) error {
+				permission := entities.Permission{Name: entities.PermissionUserRead, Description: "Search and export users"}
+				return grantPermission(tx, permission, entities.RoleAdmin)
+			},
+		},
+		{
+			ID: "20261018_index_play_histories",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.PlayHistory{})
+			},
+		},
+		{
+			ID: "20261018_add_play_sessions",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.PlayHistory{})
+			},
+		},
+		{
+			// Keeps the last review of each user per game, so that they can
+			// only have one, and rates the reviewed games from their reviews
+			// as if each also had 5 reviews of 3 stars.
+			ID: "20261018_add_review_votes_and_game_ratings",
+			Migrate: func(tx *gorm.DB) error {
+				err := tx.Exec("DELETE older FROM reviews older JOIN reviews newer " +
+					"ON newer.game_id = older.game_id AND newer.user_id = older.user_id AND newer.id > older.id").Error
+				if err != nil {
+					return err
+				}
+				if err := tx.AutoMigrate(&entities.Review{}, &entities.ReviewVote{}, &entities.Game{}); err != nil {
+					return err
+				}
+				return tx.Exec("UPDATE games JOIN (SELECT game_id, COUNT(*) AS reviews, SUM(rating) AS stars FROM reviews GROUP BY game_id) rated " +
+					"ON rated.game_id = games.id SET games.rating = (15 + rated.stars) / (5 + rated.reviews), games.rating_count = rated.reviews").Error
+			},
+		},
+		{
+			ID: "20261018_create_game_votes_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.GameVote{}, &entities.Game{})
+			},
+		},
+		{
+			// Keeps one favorite per user and game, and none of deleted games.
+			ID: "20261018_unique_favorites",
+			Migrate: func(tx *gorm.DB) error {
+				err := tx.Exec("DELETE newer FROM favorites newer JOIN favorites older " +
+					"ON older.user_id = newer.user_id AND older.game_id = newer.game_id AND older.id < newer.id").Error
+				if err != nil {
+					return err
+				}
+				if err := tx.Exec("DELETE FROM favorites WHERE game_id NOT IN (SELECT id FROM games)").Error; err != nil {
+					return err
+				}
+				return tx.AutoMigrate(&entities.Favorite{})
+			},
+		},
+		{
+			ID: "20261018_add_tags_slug",
+			Migrate: func(tx *gorm.DB) error {
+				if !tx.Migrator().HasColumn(&entities.Tag{}, "Slug") {
+					if err := tx.Migrator().AddColumn(&entities.Tag{}, "Slug"); err != nil {
+						return err
+					}
+				}
+				var tags []entities.Tag
+				if err := tx.Where("slug = ''").Find(&tags).Error; err != nil {
+					return err
+				}
+				// Names that only differ by case or punctuation get the tag ID
+				// appended to keep the slugs unique.
+				used := map[string]bool{}
+				for _, tag := range tags {
+					slug := utils.Slugify(tag.TagName)
+					if slug == "" || used[slug] {
+						slug = utils.Slugify(fmt.Sprintf("%s %d", slug, tag.ID))
+					}
+					used[slug] = true
+					if err := tx.Model(&tag).UpdateColumn("slug", slug).Error; err != nil {
+						return err
+					}
+				}
+				return tx.AutoMigrate(&entities.Tag{})
+			},
+		},
+		{
+			ID: "20261018_key_game_tags",
+			Migrate: func(tx *gorm.DB) error {
+				// game_tags had no key, so it is rebuilt without its duplicate
+				// and dangling rows.
+				if err := tx.Migrator().RenameTable("game_tags", "game_tags_old"); err != nil {
+					return err
+				}
+				if err := tx.AutoMigrate(&entities.GameTag{}); err != nil {
+					return err
+				}
+				err := tx.Exec("INSERT INTO game_tags (game_id, tag_id) SELECT DISTINCT game_id, tag_id FROM game_tags_old " +
+					"WHERE game_id IN (SELECT id FROM games) AND tag_id IN (SELECT id FROM tags)").Error
+				if err != nil {
+					return err
+				}
+				return tx.Migrator().DropTable("game_tags_old")
+			},
+		},
+		{
+			ID: "20261018_grant_tag_write",
+			Migrate: func(tx *gorm.DB) error {
+				permission := entities.Permission{Name: entities.PermissionTagWrite, Description: "Create, update and delete tags"}
+				return grantPermission(tx, permission, entities.RoleAdmin, entities.RoleEditor)
+			},
+		},
+		{
+			ID: "20261018_add_games_primary_category",
+			Migrate: func(tx *gorm.DB) error {
+				if !tx.Migrator().HasColumn(&entities.Game{}, "PrimaryCategoryID") {
+					if err := tx.Migrator().AddColumn(&entities.Game{}, "PrimaryCategoryID"); err != nil {
+						return err
+					}
+				}
+				// Games in several categories start with the oldest one.
+				err := tx.Exec("UPDATE games SET primary_category_id = " +
+					"(SELECT MIN(category_id) FROM game_categories WHERE game_categories.game_id = games.id) " +
+					"WHERE primary_category_id IS NULL").Error
+				if err != nil {
+					return err
+				}
+				if tx.Migrator().HasIndex(&entities.Game{}, "PrimaryCategoryID") {
+					return nil
+				}
+				return tx.Migrator().CreateIndex(&entities.Game{}, "PrimaryCategoryID")
+			},
+		},
+		{
+			ID: "20261018_add_games_playtime",
+			Migrate: func(tx *gorm.DB) error {
+				for _, field := range []string{"SessionCount", "TotalPlaySeconds"} {
+					if tx.Migrator().HasColumn(&entities.Game{}, field) {
+						continue
+					}
+					if err := tx.Migrator().AddColumn(&entities.Game{}, field); err != nil {
+						return err
+					}
+				}
+				// Sessions ended before the counters were kept on the games.
+				return tx.Exec("UPDATE games SET " +
+					"session_count = (SELECT COUNT(*) FROM play_histories " +
+					"WHERE play_histories.game_id = games.id AND ended_at IS NOT NULL), " +
+					"total_play_seconds = (SELECT COALESCE(SUM(duration_seconds), 0) FROM play_histories " +
+					"WHERE play_histories.game_id = games.id AND ended_at IS NOT NULL)").Error
+			},
+		},
+		{
+			ID: "20261018_create_play_count_flushes_table",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&entities.PlayCountFlush{})
+			},
+		},
+	}
+}
+
+var builtinPermissions = []entities.Permission{
+	{Name: entities.PermissionGameWrite, Description: "Create, update and delete games"},
+	{Name: entities.PermissionCategoryWrite, Description: "Create, update and delete categories"},
+	{Name: entities.PermissionAdsWrite, Description: "Create, update and delete ads"},
+	{Name: entities.PermissionUserWrite, Description: "Create, update, delete and unlock users"},
+	{Name: entities.PermissionRoleAssign, Description: "Assign roles to users"},
+}
+
+type builtinRole struct {
+	entities.Role
+	permissions []string
+}
+
+var builtinRoles = []builtinRole{
+	{
+		Role: entities.Role{Name: entities.RoleAdmin, Description: "Full access"},
+		permissions: []string{
+			entities.PermissionGameWrite,
+			entities.PermissionCategoryWrite,
+			entities.PermissionAdsWrite,
+			entities.PermissionUserWrite,
+			entities.PermissionRoleAssign,
+		},
+	},
+	{
+		Role:        entities.Role{Name: entities.RoleEditor, Description: "Manages games and categories"},
+		permissions: []string{entities.PermissionGameWrite, entities.PermissionCategoryWrite},
+	},
+	{
+		Role:        entities.Role{Name: entities.RoleAdManager, Description: "Manages ads"},
+		permissions: []string{entities.PermissionAdsWrite},
+	},
+	{
+		Role: entities.Role{Name: entities.RolePlayer, Description: "Plays games"},
+	},
+}
+
+// seedRoles creates the missing permissions and roles, leaving the permissions
+// of roles that already exist as they are.
+func seedRoles(tx *gorm.DB, permissions []entities.Permission, roles []builtinRole) error {
+	byName := map[string]entities.Permission{}
+	for _, permission := range permissions {
+		if err := tx.Where(entities.Permission{Name: permission.Name}).Attrs(permission).FirstOrCreate(&permission).Error; err != nil {
+			return err
+		}
+		byName[permission.Name] = permission
+	}
+
+	for _, builtin := range roles {
+		role := builtin.Role
+		result := tx.Where(entities.Role{Name: role.Name}).Attrs(role).FirstOrCreate(&role)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		rolePermissions := make([]entities.Permission, 0, len(builtin.permissions))
+		for _, name := range builtin.permissions {
+			rolePermissions = append(rolePermissions, byName[name])
+		}
+		if len(rolePermissions) > 0 {
+			if err := tx.Model(&role).Association("Permissions").Append(rolePermissions); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// grantPermission creates the permission if it is missing and gives it to the
+// roles that exist.
+func grantPermission(tx *gorm.DB, permission entities.Permission, roleNames ...string) error {
+	if err := tx.Where(entities.Permission{Name: permission.Name}).Attrs(permission).FirstOrCreate(&permission).Error; err != nil {
+		return err
+	}
+
+	var roles []entities.Role
+	if err := tx.Where("name IN ?", roleNames).Find(&roles).Error; err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if err := tx.Model(&role).Association("Permissions").Append(&permission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	config.LoadConfig()
+	db := config.ConnectDatabase()
+	gormDB, err := gorm.Open(mysql.New(mysql.Config{
+		Conn: db.ConnPool,
+	}), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("failed to connect to MySQL database: %v", err)
+	}
+	m := gormigrate.New(gormDB, gormigrate.DefaultOptions, InitMigrations())
+
+	if err = m.Migrate(); err != nil {
+		log.Fatalf("could not migrate: %v", err)
+	}
+
+	log.Println("Migrations ran successfully")
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"crazygames.io/config"
+	"crazygames.io/repositories"
+	"crazygames.io/services"
+)
+
+// Rebuilds the game rankings in Redis from MySQL, e.g. after Redis was flushed.
+func main() {
+	config.LoadConfig()
+	db := config.ConnectDatabase()
+	redisClient := config.ConnectRedis()
+
+	rankingService := services.NewRankingService(
+		repositories.NewGameRepository(db),
+		repositories.NewPlayHistoryRepository(db),
+		repositories.NewRankingRepository(redisClient),
+		repositories.NewFavoriteRepository(db),
+	)
+	if err := rankingService.Rebuild(context.Background()); err != nil {
+		log.Fatalf("Failed to rebuild the rankings: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "context"
+
+// AuditActor is who makes an administrative request, carried in the request
+// context down to the repositories that record the audit events.
+type AuditActor struct {
+	UserID    uint
+	IP        string
+	RequestID string
+}
+
+type auditActorKey struct{}
+
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActorFrom returns the actor of ctx, if the change it belongs to is audited.
+func AuditActorFrom(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
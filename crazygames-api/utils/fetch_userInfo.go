@@ -1,37 +1,34 @@
-package utils
-
-import (
-	"encoding/json"
-	"fmt"
-	"io/ioutil"
-	"net/http"
-)
-
-func FetchUserInfo(accessToken string) (map[string]interface{}, error) { // Exported function
-	url := "https://www.googleapis.com/oauth2/v2/userinfo"
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+accessToken)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user info: %v", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
-	}
-
-	var userInfo map[string]interface{}
-	if err := json.Unmarshal(body, &userInfo); err != nil {
-		return nil, fmt.Errorf("failed to parse user info: %v", err)
-	}
-
-	return userInfo, nil
-}
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// FetchUserInfo calls a provider API endpoint and decodes its JSON response
+// into userInfo. client must already authenticate its requests, e.g. one
+// returned by oauth2.Config.Client.
+func FetchUserInfo(client *http.Client, url string, userInfo interface{}) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user info: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch user info: %s", resp.Status)
+	}
+
+	if err := json.Unmarshal(body, userInfo); err != nil {
+		return fmt.Errorf("failed to parse user info: %v", err)
+	}
+
+	return nil
+}
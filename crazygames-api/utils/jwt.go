@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Claims is the payload of the access tokens issued to logged in users.
+type Claims struct {
+	UserID    uint   `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+func GenerateToken(secret string, userID uint, role string, sessionID string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:    userID,
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+
+	return token.SignedString([]byte(secret))
+}
+
+// ParseToken verifies the HS256 signature and expiry of tokenString and returns its claims.
+func ParseToken(secret string, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.UserID == 0 {
+		return nil, errors.New("token has no user")
+	}
+
+	return claims, nil
+}
+
+// emailVerificationAudience keeps verification tokens and access tokens, which
+// share the secret, from being accepted in place of each other.
+const emailVerificationAudience = "email-verification"
+
+// EmailVerificationClaims is the payload of the link we email to prove a user owns Email.
+type EmailVerificationClaims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+func GenerateEmailVerificationToken(secret string, userID uint, email string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, EmailVerificationClaims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+
+	return token.SignedString([]byte(secret))
+}
+
+// ParseEmailVerificationToken verifies tokenString and returns the user and email it was issued for.
+func ParseEmailVerificationToken(secret string, tokenString string) (uint, string, error) {
+	claims := &EmailVerificationClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithAudience(emailVerificationAudience))
+	if err != nil {
+		return 0, "", err
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID == 0 || claims.Email == "" {
+		return 0, "", errors.New("token has no user")
+	}
+
+	return uint(userID), claims.Email, nil
+}
+
+// mfaAudience marks the token a user gets after the password step of a login
+// that still needs their second factor.
+const mfaAudience = "mfa"
+
+func GenerateMFAToken(secret string, userID uint, ttl time.Duration) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(userID), 10),
+		Audience:  jwt.ClaimStrings{mfaAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	})
+
+	return token.SignedString([]byte(secret))
+}
+
+// ParseMFAToken verifies tokenString and returns the user that passed the password step.
+func ParseMFAToken(secret string, tokenString string) (uint, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithAudience(mfaAudience))
+	if err != nil {
+		return 0, err
+	}
+
+	userID, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, errors.New("token has no user")
+	}
+
+	return uint(userID), nil
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecret = "test-secret"
+
+func Test_EmailVerificationToken(t *testing.T) {
+	t.Run("valid token should return its user and email", func(t *testing.T) {
+		token, err := GenerateEmailVerificationToken(testSecret, 7, "player@example.com", time.Hour)
+		assert.NoError(t, err, "failed to generate token")
+
+		userID, email, err := ParseEmailVerificationToken(testSecret, token)
+		assert.NoError(t, err, "failed to parse token")
+		assert.Equal(t, uint(7), userID)
+		assert.Equal(t, "player@example.com", email)
+	})
+
+	t.Run("expired token should fail", func(t *testing.T) {
+		token, _ := GenerateEmailVerificationToken(testSecret, 7, "player@example.com", -time.Minute)
+
+		_, _, err := ParseEmailVerificationToken(testSecret, token)
+		assert.Error(t, err)
+	})
+
+	t.Run("access token should not verify an email", func(t *testing.T) {
+		token, _ := GenerateToken(testSecret, 7, "player", "session", time.Hour)
+
+		_, _, err := ParseEmailVerificationToken(testSecret, token)
+		assert.Error(t, err)
+	})
+
+	t.Run("verification token should not be an access token", func(t *testing.T) {
+		token, _ := GenerateEmailVerificationToken(testSecret, 7, "player@example.com", time.Hour)
+
+		_, err := ParseToken(testSecret, token)
+		assert.Error(t, err)
+	})
+}
+
+func Test_MFAToken(t *testing.T) {
+	t.Run("valid token should return its user", func(t *testing.T) {
+		token, err := GenerateMFAToken(testSecret, 7, time.Minute)
+		assert.NoError(t, err, "failed to generate token")
+
+		userID, err := ParseMFAToken(testSecret, token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), userID)
+	})
+
+	t.Run("mfa token should not be an access token", func(t *testing.T) {
+		token, _ := GenerateMFAToken(testSecret, 7, time.Minute)
+
+		_, err := ParseToken(testSecret, token)
+		assert.Error(t, err)
+	})
+
+	t.Run("email verification token should not pass the mfa step", func(t *testing.T) {
+		token, _ := GenerateEmailVerificationToken(testSecret, 7, "player@example.com", time.Hour)
+
+		_, err := ParseMFAToken(testSecret, token)
+		assert.Error(t, err)
+	})
+}
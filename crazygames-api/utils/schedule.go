@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery calls job every interval until ctx is done, logging its errors
+// under name.
+func RunEvery(ctx context.Context, name string, interval time.Duration, job func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := job(); err != nil {
+				log.Printf("%s failed: %v", name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "strings"
+
+// Slugify lowercases s and joins its runs of letters and digits with hyphens,
+// so that "Tower Defense" becomes "tower-defense".
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
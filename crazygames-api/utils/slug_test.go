@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Slugify(t *testing.T) {
+	assert.Equal(t, "tower-defense", Slugify("Tower Defense"))
+	assert.Equal(t, "2-player", Slugify("  2 Player! "))
+	assert.Equal(t, "first-person-shooter", Slugify("First-Person  Shooter"))
+	assert.Equal(t, "", Slugify("!!"))
+}
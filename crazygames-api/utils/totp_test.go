@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The secret of the RFC 6238 test vectors, "12345678901234567890", in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func Test_TOTPCode(t *testing.T) {
+	// The last 6 digits of the SHA-1 test vectors in RFC 6238 appendix B
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, expected := range vectors {
+		code, err := TOTPCode(rfcSecret, time.Unix(unix, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+}
+
+func Test_ValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := TOTPCode(rfcSecret, now)
+
+	t.Run("current code should be accepted", func(t *testing.T) {
+		step, ok := ValidateTOTP(rfcSecret, code, now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, now.Unix()/30, step)
+	})
+
+	t.Run("code of the previous period should be accepted", func(t *testing.T) {
+		_, ok := ValidateTOTP(rfcSecret, code, now.Add(30*time.Second), 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("old code should be refused", func(t *testing.T) {
+		_, ok := ValidateTOTP(rfcSecret, code, now.Add(90*time.Second), 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("used code should be refused", func(t *testing.T) {
+		step, _ := ValidateTOTP(rfcSecret, code, now, 0)
+		_, ok := ValidateTOTP(rfcSecret, code, now, step)
+		assert.False(t, ok)
+	})
+
+	t.Run("wrong code should be refused", func(t *testing.T) {
+		_, ok := ValidateTOTP(rfcSecret, "000000", now, 0)
+		assert.False(t, ok)
+	})
+}
+
+func Test_TOTPProvisioningURI(t *testing.T) {
+	uri := TOTPProvisioningURI("CrazyGames", "admin@example.com", rfcSecret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/CrazyGames:admin@example.com?"))
+	assert.Contains(t, uri, "secret="+rfcSecret)
+	assert.Contains(t, uri, "issuer=CrazyGames")
+}